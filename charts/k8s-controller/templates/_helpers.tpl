@@ -11,4 +11,41 @@
 {{- $name := default .Chart.Name .Values.nameOverride -}}
 {{- printf "%s-%s" .Release.Name $name | trunc 63 | trimSuffix "-" -}}
 {{- end -}}
+{{- end -}}
+
+{{/* ServiceAccount name */}}
+{{- define "app.serviceAccountName" -}}
+{{- default (include "app.name" .) .Values.serviceAccount.name -}}
+{{- end -}}
+
+{{/* True when watchNamespaces needs cluster-wide watches: "*" or a namespace label selector */}}
+{{- define "app.clusterWide" -}}
+{{- if or (eq (trim .Values.watchNamespaces) "*") (regexMatch "[=!()]" .Values.watchNamespaces) -}}
+true
+{{- end -}}
+{{- end -}}
+
+{{/* Rules the controller needs in every watched namespace */}}
+{{- define "app.watchRules" -}}
+- apiGroups: ["frontendpage.silhouetteua.io"]
+  resources: ["frontendpages", "frontendpagebackups"]
+  verbs: ["get", "list", "watch", "create", "update", "patch", "delete"]
+- apiGroups: ["frontendpage.silhouetteua.io"]
+  resources: ["frontendpages/status", "frontendpagebackups/status"]
+  verbs: ["get", "update", "patch"]
+- apiGroups: ["apps"]
+  resources: ["deployments"]
+  verbs: ["get", "list", "watch", "create", "update", "patch", "delete"]
+- apiGroups: ["batch"]
+  resources: ["cronjobs"]
+  verbs: ["get", "list", "watch", "create", "update", "patch", "delete"]
+- apiGroups: [""]
+  resources: ["configmaps"]
+  verbs: ["get", "list", "watch", "create", "update", "patch", "delete"]
+- apiGroups: [""]
+  resources: ["secrets"]
+  verbs: ["get", "list", "watch"]
+- apiGroups: [""]
+  resources: ["events"]
+  verbs: ["create", "patch"]
 {{- end -}}
\ No newline at end of file
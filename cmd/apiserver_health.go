@@ -1,50 +1,245 @@
 package cmd
 
 import (
-	"crypto/tls"
+	"context"
 	"fmt"
-	"github.com/rs/zerolog/log"
-	"github.com/spf13/cobra"
+	"io"
 	"net/http"
+	"os"
+	"strings"
 	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	frontendv1alpha1 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/v1alpha1"
+	"github.com/silhouetteUA/k8s-controller/pkg/health"
+	"github.com/silhouetteUA/k8s-controller/pkg/printers"
 )
 
-var apiServerURL string
+var (
+	apiServerURL  string
+	controllerURL string
+	healthCRDs    bool
+	healthTimeout time.Duration
+)
+
+// healthCRDNames are the CustomResourceDefinitions checked by kctl health --crds.
+var healthCRDNames = []string{
+	"frontendpages." + frontendv1alpha1.SchemeGroupVersion.Group,
+	"frontendpagebackups." + frontendv1alpha1.SchemeGroupVersion.Group,
+}
 
 var healthCmd = &cobra.Command{
 	Use:   "health",
-	Short: "Perform a health check of the Kubernetes API",
-	Long:  "Use flag `api-server=value` to specify the Kubernetes API Server URL and execute health check against it",
+	Short: "Check the health of the Kubernetes API server and, optionally, the controller",
+	Long: `Queries /livez and /readyz?verbose of the API server with the credentials of the selected
+kubeconfig context and reports each check. --api-server overrides the server URL of the context.
+
+With --controller-url the controller's own /readyz is checked too, and with --crds the
+FrontendPage and FrontendPageBackup CustomResourceDefinitions must be installed and established.
+Exits with status 2 when a component is unhealthy.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		//client := http.Client{Timeout: 3 * time.Second}
-		client := &http.Client{
-			Timeout: 3 * time.Second,
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-			},
-		}
-		resp, err := client.Get(apiServerURL + "/healthz")
+		cfg, err := getRestConfig(kubeconfig)
 		if err != nil {
-			fmt.Println("Status: Unhealthy")
-			fmt.Printf("K8s API reachable: no (%v)\n", err)
-			return
+			log.Error().Err(err).Msg("Failed to load kubeconfig")
+			os.Exit(1)
 		}
-		defer func() {
-			if err := resp.Body.Close(); err != nil {
-				log.Error().Err(err).Msg("failed to close response body")
-			}
-		}()
-		if resp.StatusCode == 200 {
-			fmt.Println("Status: Healthy")
-			fmt.Println("K8s API reachable: yes")
-		} else {
-			fmt.Println("Status: Unhealthy")
-			fmt.Printf("K8s API reachable: no (status code %d)\n", resp.StatusCode)
+		if apiServerURL != "" {
+			cfg.Host = apiServerURL
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), healthTimeout)
+		defer cancel()
+		result, err := checkHealth(ctx, cfg, controllerURL, healthCRDs)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to create Kubernetes client")
+			os.Exit(1)
+		}
+		printOutput(cmd, healthOutput(result))
+		if !result.Healthy {
+			os.Exit(2)
 		}
 	},
 }
 
+// healthResult is the output of kctl health.
+type healthResult struct {
+	Server     string            `json:"server"`
+	Healthy    bool              `json:"healthy"`
+	Components []componentHealth `json:"components"`
+}
+
+// componentHealth is the health of one endpoint: livez, readyz, controller or crds.
+type componentHealth struct {
+	Component string               `json:"component"`
+	Endpoint  string               `json:"endpoint"`
+	Healthy   bool                 `json:"healthy"`
+	Checks    []health.CheckResult `json:"checks,omitempty"`
+	// Message is set when the component could not be checked or failed without details.
+	Message string `json:"message,omitempty"`
+}
+
+// checkHealth checks the API server behind cfg, then the controller at controllerURL when it
+// is set and the CRDs when crds is set.
+func checkHealth(ctx context.Context, cfg *rest.Config, controllerURL string, crds bool) (healthResult, error) {
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return healthResult{}, err
+	}
+	rc := clientset.Discovery().RESTClient()
+	result := healthResult{Server: cfg.Host}
+	result.Components = append(result.Components,
+		apiServerProbe(ctx, rc, "livez"),
+		apiServerProbe(ctx, rc, "readyz"))
+	if controllerURL != "" {
+		result.Components = append(result.Components, controllerProbe(ctx, controllerURL))
+	}
+	if crds {
+		extClient, err := apiextensionsclient.NewForConfig(cfg)
+		if err != nil {
+			return healthResult{}, err
+		}
+		result.Components = append(result.Components, crdHealth(ctx, extClient))
+	}
+	result.Healthy = true
+	for _, c := range result.Components {
+		result.Healthy = result.Healthy && c.Healthy
+	}
+	return result, nil
+}
+
+// apiServerProbe queries an API server probe endpoint with ?verbose.
+func apiServerProbe(ctx context.Context, rc rest.Interface, endpoint string) componentHealth {
+	component := componentHealth{Component: endpoint, Endpoint: "/" + endpoint}
+	var code int
+	body, err := rc.Get().AbsPath(component.Endpoint).Param("verbose", "").Do(ctx).StatusCode(&code).Raw()
+	return probeHealth(component, code, string(body), err)
+}
+
+// controllerProbe queries the controller's /readyz?verbose. The probe server is not
+// authenticated, so a plain HTTP client is enough.
+func controllerProbe(ctx context.Context, baseURL string) componentHealth {
+	component := componentHealth{Component: "controller", Endpoint: strings.TrimSuffix(baseURL, "/") + "/readyz"}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, component.Endpoint+"?verbose", nil)
+	if err != nil {
+		return probeHealth(component, 0, "", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return probeHealth(component, 0, "", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.Error().Err(err).Msg("failed to close response body")
+		}
+	}()
+	body, err := io.ReadAll(resp.Body)
+	return probeHealth(component, resp.StatusCode, string(body), err)
+}
+
+// probeHealth fills component from a probe response. A failed probe whose body lists its
+// checks is reported through them rather than as a transport error.
+func probeHealth(component componentHealth, code int, body string, err error) componentHealth {
+	component.Checks = health.ParseVerbose(body)
+	component.Healthy = err == nil && code == http.StatusOK
+	for _, check := range component.Checks {
+		component.Healthy = component.Healthy && check.Healthy
+	}
+	if !component.Healthy && len(component.Checks) == 0 {
+		if err != nil {
+			component.Message = err.Error()
+		} else {
+			component.Message = fmt.Sprintf("status code %d", code)
+		}
+	}
+	return component
+}
+
+// crdHealth checks that each of healthCRDNames exists and is Established.
+func crdHealth(ctx context.Context, c apiextensionsclient.Interface) componentHealth {
+	component := componentHealth{Component: "crds", Endpoint: "/apis/apiextensions.k8s.io/v1/customresourcedefinitions", Healthy: true}
+	for _, name := range healthCRDNames {
+		check := health.CheckResult{Name: name}
+		crd, err := c.ApiextensionsV1().CustomResourceDefinitions().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			check.Message = err.Error()
+		} else {
+			check.Healthy, check.Message = crdEstablished(crd)
+		}
+		component.Healthy = component.Healthy && check.Healthy
+		component.Checks = append(component.Checks, check)
+	}
+	return component
+}
+
+func crdEstablished(crd *apiextensionsv1.CustomResourceDefinition) (bool, string) {
+	for _, cond := range crd.Status.Conditions {
+		if cond.Type != apiextensionsv1.Established {
+			continue
+		}
+		if cond.Status == apiextensionsv1.ConditionTrue {
+			return true, ""
+		}
+		if cond.Message != "" {
+			return false, "not established: " + cond.Message
+		}
+		break
+	}
+	return false, "not established"
+}
+
+// healthOutput describes a health result for the printers: one row per component, with the
+// failed checks named in MESSAGE. -o json|yaml has every check.
+func healthOutput(result healthResult) printers.Output {
+	out := printers.Output{
+		Object: result,
+		Columns: []printers.Column{
+			{Header: "COMPONENT"},
+			{Header: "STATUS"},
+			{Header: "CHECKS"},
+			{Header: "MESSAGE"},
+			{Header: "ENDPOINT", Wide: true},
+		},
+	}
+	for _, c := range result.Components {
+		status := "Unhealthy"
+		if c.Healthy {
+			status = "Healthy"
+		}
+		checks, passed := 0, 0
+		var failed []string
+		for _, check := range c.Checks {
+			checks++
+			if check.Healthy {
+				passed++
+				continue
+			}
+			if check.Message != "" {
+				failed = append(failed, check.Name+": "+check.Message)
+			} else {
+				failed = append(failed, check.Name)
+			}
+		}
+		message := c.Message
+		if len(failed) > 0 {
+			message = strings.Join(failed, "; ")
+		}
+		out.Rows = append(out.Rows, []string{c.Component, status, fmt.Sprintf("%d/%d passed", passed, checks), orNone(message), c.Endpoint})
+		out.Names = append(out.Names, c.Component)
+	}
+	return out
+}
+
 func init() {
 	rootCmd.AddCommand(healthCmd)
-	healthCmd.Flags().StringVar(&apiServerURL, "api-server", "https://kubernetes.default.svc", "Health check external Kubernetes API server")
+	addOutputFlags(healthCmd)
+	healthCmd.Flags().StringVar(&apiServerURL, "api-server", "", "API server URL, overriding the server of the kubeconfig context")
+	healthCmd.Flags().StringVar(&controllerURL, "controller-url", "", "Also check the controller's /readyz at this base URL, e.g. http://localhost:8080")
+	healthCmd.Flags().BoolVar(&healthCRDs, "crds", false, "Also check that the FrontendPage CRDs are installed and established")
+	healthCmd.Flags().DurationVar(&healthTimeout, "timeout", 10*time.Second, "Time allowed for all checks")
 }
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"context"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+)
+
+func TestCheckHealth(t *testing.T) {
+	var authorized []string
+	established := "True"
+	apiServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		authorized = append(authorized, r.URL.RequestURI())
+		switch r.URL.Path {
+		case "/livez":
+			fmt.Fprint(w, "[+]ping ok\n[+]etcd ok\nlivez check passed\n")
+		case "/readyz":
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, "[+]ping ok\n[-]informer-sync failed: reason withheld\nreadyz check failed\n")
+		case "/apis/apiextensions.k8s.io/v1/customresourcedefinitions/frontendpages.frontendpage.silhouetteua.io":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"apiVersion":"apiextensions.k8s.io/v1","kind":"CustomResourceDefinition",
+				"metadata":{"name":"frontendpages.frontendpage.silhouetteua.io"},
+				"status":{"conditions":[{"type":"Established","status":%q}]}}`, established)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"apiVersion":"v1","kind":"Status","status":"Failure","reason":"NotFound","code":404}`)
+		}
+	}))
+	defer apiServer.Close()
+	controller := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/readyz" || !r.URL.Query().Has("verbose") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, "[+]ping ok\n[+]leader-election ok\nreadyz check passed\n")
+	}))
+	defer controller.Close()
+
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: apiServer.Certificate().Raw})
+	cfg := &rest.Config{Host: apiServer.URL, BearerToken: "test-token", TLSClientConfig: rest.TLSClientConfig{CAData: ca}}
+	ctx := context.Background()
+
+	result, err := checkHealth(ctx, cfg, controller.URL, true)
+	require.NoError(t, err)
+	require.False(t, result.Healthy)
+	require.Contains(t, authorized, "/livez?verbose=", "the request carries the kubeconfig token and trusts its CA")
+	require.Len(t, result.Components, 4)
+	livez, readyz, ctrl, crds := result.Components[0], result.Components[1], result.Components[2], result.Components[3]
+	require.True(t, livez.Healthy)
+	require.Len(t, livez.Checks, 2)
+	require.False(t, readyz.Healthy)
+	require.Equal(t, "reason withheld", readyz.Checks[1].Message)
+	require.True(t, ctrl.Healthy)
+	require.False(t, crds.Healthy)
+	require.True(t, crds.Checks[0].Healthy)
+	require.False(t, crds.Checks[1].Healthy, "frontendpagebackups is not installed")
+
+	out := healthOutput(result)
+	require.Equal(t, []string{"livez", "readyz", "controller", "crds"}, out.Names)
+	require.Equal(t, []string{"readyz", "Unhealthy", "1/2 passed", "informer-sync: reason withheld", "/readyz"}, out.Rows[1])
+	require.Equal(t, []string{"livez", "Healthy", "2/2 passed", "<none>", "/livez"}, out.Rows[0])
+
+	established = "False"
+	result, err = checkHealth(ctx, cfg, "", true)
+	require.NoError(t, err)
+	require.Len(t, result.Components, 3)
+	require.Equal(t, "not established", result.Components[2].Checks[0].Message)
+
+	// Without the CA the server is not trusted; the failure is reported, not fatal.
+	result, err = checkHealth(ctx, &rest.Config{Host: apiServer.URL, BearerToken: "test-token"}, "", false)
+	require.NoError(t, err)
+	require.False(t, result.Healthy)
+	require.Empty(t, result.Components[0].Checks)
+	require.Contains(t, result.Components[0].Message, "certificate")
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	frontendv1alpha2 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/frontendBackup"
+	frontendv1alpha1 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/v1alpha1"
+	"github.com/silhouetteUA/k8s-controller/pkg/manifest"
+)
+
+var (
+	manifestFiles     []string
+	manifestRecursive bool
+	applyPrune        bool
+	applySelector     string
+	applyDryRun       bool
+	applyForce        bool
+	applyFieldManager string
+)
+
+// pruneKinds are pruned even when no manifest of that kind is left, so removing the last
+// page from git still deletes it.
+var pruneKinds = []schema.GroupVersionKind{
+	frontendv1alpha1.SchemeGroupVersion.WithKind("FrontendPage"),
+	frontendv1alpha2.SchemeGroupVersion.WithKind("FrontendPageBackup"),
+}
+
+var applyCmd = &cobra.Command{
+	Use:   "apply -f PATH...",
+	Short: "Apply manifests with server-side apply",
+	Long: `Applies the FrontendPage, FrontendPageBackup, Deployment and other manifests in the given files
+and directories with server-side apply. Files may hold several YAML documents or JSON objects.
+
+With --prune, objects matching --selector that are no longer in the manifests are deleted. The
+selector is required so that only objects managed from these manifests are considered.`,
+	Example: `  kctl apply -f pages/
+  kctl apply -f pages/ -R --prune -l app.kubernetes.io/part-of=site`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runWithClient("apply manifests", func(ctx context.Context, c client.Client) error {
+			return applyManifests(ctx, c, cmd)
+		})
+	},
+}
+
+func applyManifests(ctx context.Context, c client.Client, cmd *cobra.Command) error {
+	applier, objs, selector, err := loadManifests(c, cmd)
+	if err != nil {
+		return err
+	}
+	applier.DryRun = applyDryRun
+	var prune []*unstructured.Unstructured
+	if applyPrune {
+		// Candidates are found before applying so a failed apply never prunes.
+		if prune, err = applier.PruneCandidates(ctx, objs, selector, pruneKinds); err != nil {
+			return err
+		}
+	}
+	results, err := applier.Apply(ctx, objs)
+	printResults(cmd.OutOrStdout(), results)
+	if err != nil {
+		return err
+	}
+	results, err = applier.Prune(ctx, prune)
+	printResults(cmd.OutOrStdout(), results)
+	return err
+}
+
+// loadManifests reads -f, builds the applier for the command's flags and prepares the
+// objects. The selector is nil unless --prune is set.
+func loadManifests(c client.Client, cmd *cobra.Command) (*manifest.Applier, []*unstructured.Unstructured, labels.Selector, error) {
+	if len(manifestFiles) == 0 {
+		return nil, nil, nil, fmt.Errorf("no manifests given, use -f")
+	}
+	var selector labels.Selector
+	if applyPrune {
+		if applySelector == "" {
+			return nil, nil, nil, fmt.Errorf("--prune requires --selector")
+		}
+		var err error
+		if selector, err = labels.Parse(applySelector); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	objs, err := manifest.Load(manifestFiles, manifestRecursive, cmd.InOrStdin())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	applier := &manifest.Applier{
+		Client:           c,
+		Scheme:           c.Scheme(),
+		FieldManager:     applyFieldManager,
+		Namespace:        namespace,
+		EnforceNamespace: cmd.Flags().Changed("namespace"),
+		ForceConflicts:   applyForce,
+	}
+	if err := applier.Prepare(objs); err != nil {
+		return nil, nil, nil, err
+	}
+	return applier, objs, selector, nil
+}
+
+func printResults(out io.Writer, results []manifest.Result) {
+	for _, r := range results {
+		if applyDryRun {
+			fmt.Fprintf(out, "%s (dry run)\n", r)
+			continue
+		}
+		fmt.Fprintln(out, r)
+	}
+}
+
+// addManifestFlags registers the flags shared by apply and diff.
+func addManifestFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.StringSliceVarP(&manifestFiles, "filename", "f", nil, "Manifest file or directory, - for stdin; may be repeated")
+	flags.BoolVarP(&manifestRecursive, "recursive", "R", false, "Read directories recursively")
+	flags.BoolVar(&applyPrune, "prune", false, "Delete objects matching --selector that are not in the manifests")
+	flags.StringVarP(&applySelector, "selector", "l", "", "Label selector of the objects considered for pruning")
+	flags.BoolVar(&applyForce, "force-conflicts", false, "Take over fields owned by other field managers")
+	flags.StringVar(&applyFieldManager, "field-manager", manifest.DefaultFieldManager, "Name of the field manager")
+}
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+	addManifestFlags(applyCmd)
+	applyCmd.Flags().BoolVar(&applyDryRun, "dry-run", false, "Send the changes with server-side dry run; nothing is persisted")
+}
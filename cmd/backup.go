@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	frontendv1alpha2 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/frontendBackup"
+	"github.com/silhouetteUA/k8s-controller/pkg/printers"
+)
+
+var fpbSelector string
+var fpbAllNamespaces bool
+
+var fpbCmd = &cobra.Command{
+	Use:     "frontendpagebackup",
+	Aliases: []string{"fpb", "frontendpagebackups"},
+	Short:   "Inspect FrontendPageBackups",
+}
+
+var fpbGetCmd = &cobra.Command{
+	Use:   "get NAME",
+	Short: "Show a FrontendPageBackup",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runWithClient("get FrontendPageBackup", func(ctx context.Context, c client.Client) error {
+			var backup frontendv1alpha2.FrontendPageBackup
+			if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: args[0]}, &backup); err != nil {
+				return err
+			}
+			out := backupsOutput([]frontendv1alpha2.FrontendPageBackup{backup}, false)
+			out.Object = out.Object.(*frontendv1alpha2.FrontendPageBackupList).Items[0]
+			printOutput(cmd, out)
+			return nil
+		})
+	},
+}
+
+var fpbListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List FrontendPageBackups",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ns := namespace
+		if fpbAllNamespaces {
+			ns = ""
+		}
+		sel, err := labels.Parse(fpbSelector)
+		if err != nil {
+			log.Error().Err(err).Msg("Invalid --selector")
+			os.Exit(1)
+		}
+		runWithClient("list FrontendPageBackups", func(ctx context.Context, c client.Client) error {
+			var backups frontendv1alpha2.FrontendPageBackupList
+			if err := c.List(ctx, &backups, client.InNamespace(ns), client.MatchingLabelsSelector{Selector: sel}); err != nil {
+				return err
+			}
+			if len(backups.Items) == 0 && printOptions.IsTable() {
+				printNoResources(cmd, "FrontendPageBackups", ns)
+				return nil
+			}
+			printOutput(cmd, backupsOutput(backups.Items, fpbAllNamespaces))
+			return nil
+		})
+	},
+}
+
+// backupsOutput describes backups for the printers.
+func backupsOutput(backups []frontendv1alpha2.FrontendPageBackup, withNamespace bool) printers.Output {
+	gv := frontendv1alpha2.SchemeGroupVersion
+	list := &frontendv1alpha2.FrontendPageBackupList{TypeMeta: listTypeMeta(gv.String(), "FrontendPageBackup")}
+	out := printers.Output{Object: list}
+	if withNamespace {
+		out.Columns = append(out.Columns, printers.Column{Header: "NAMESPACE"})
+	}
+	out.Columns = append(out.Columns,
+		printers.Column{Header: "NAME"},
+		printers.Column{Header: "PAGE"},
+		printers.Column{Header: "SCHEDULE"},
+		printers.Column{Header: "STATUS"},
+		printers.Column{Header: "LAST BACKUP"},
+		printers.Column{Header: "AGE"},
+		printers.Column{Header: "PATH", Wide: true},
+	)
+	for _, b := range backups {
+		b.APIVersion, b.Kind = gv.String(), "FrontendPageBackup"
+		b.ManagedFields = nil
+		list.Items = append(list.Items, b)
+		lastBackup := "<never>"
+		if b.Status.LastBackupTime != nil {
+			lastBackup = age(*b.Status.LastBackupTime) + " ago"
+		}
+		var row []string
+		if withNamespace {
+			row = append(row, b.Namespace)
+		}
+		row = append(row, b.Name, b.Spec.FrontendPageRef, b.Spec.Schedule, orNone(b.Status.Status), lastBackup,
+			age(b.CreationTimestamp), orNone(b.Status.LastBackupPath))
+		out.Rows = append(out.Rows, row)
+		out.Names = append(out.Names, resourceName("FrontendPageBackup", gv.Group, b.Name))
+	}
+	return out
+}
+
+func init() {
+	rootCmd.AddCommand(fpbCmd)
+	fpbCmd.AddCommand(fpbGetCmd, fpbListCmd)
+	addOutputFlags(fpbGetCmd)
+	addOutputFlags(fpbListCmd)
+	fpbListCmd.Flags().StringVarP(&fpbSelector, "selector", "l", "", "Label selector, e.g. team=web")
+	fpbListCmd.Flags().BoolVarP(&fpbAllNamespaces, "all-namespaces", "A", false, "List FrontendPageBackups in every namespace")
+}
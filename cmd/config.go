@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+var configOutput string
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the kctl configuration",
+}
+
+var configViewCmd = &cobra.Command{
+	Use:   "view",
+	Short: "Print the effective configuration",
+	Long: `Prints the configuration after layering the defaults, the config file, KCTL_* environment
+variables and the global flags. Every key can be set in the environment by upper-casing its
+path: server.events.queueSize is KCTL_SERVER_EVENTS_QUEUE_SIZE.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var (
+			out []byte
+			err error
+		)
+		switch configOutput {
+		case "", "yaml":
+			out, err = yaml.Marshal(effectiveConfig)
+		case "json":
+			out, err = json.MarshalIndent(effectiveConfig, "", "  ")
+			out = append(out, '\n')
+		default:
+			log.Error().Msgf("Unsupported output format %q, want yaml or json", configOutput)
+			os.Exit(1)
+		}
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to encode the configuration")
+			os.Exit(1)
+		}
+		if path, _ := configLoader.File(); path != "" {
+			if _, statErr := os.Stat(path); statErr == nil && configOutput != "json" {
+				fmt.Fprintf(cmd.OutOrStdout(), "# loaded from %s\n", path)
+			}
+		}
+		_, _ = cmd.OutOrStdout().Write(out)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configViewCmd)
+	configViewCmd.Flags().StringVarP(&configOutput, "output", "o", "yaml", "Output format: yaml or json")
+}
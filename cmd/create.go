@@ -66,8 +66,6 @@ var createCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(createCmd)
-	createCmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Path to the kubeconfig file")
-	createCmd.Flags().StringVar(&namespace, "namespace", "default", "Kubernetes namespace")
 	createCmd.Flags().StringVar(&deploymentName, "name", "", "Name of the deployment to create")
 	createCmd.Flags().StringVar(&deploymentImage, "image", "", "Container image for the deployment")
 }
@@ -43,7 +43,5 @@ var deleteCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(deleteCmd)
-	deleteCmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Path to the kubeconfig file")
-	deleteCmd.Flags().StringVar(&namespace, "namespace", "default", "Kubernetes namespace")
 	deleteCmd.Flags().StringVar(&deploymentName, "name", "", "Name of the deployment to delete")
 }
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/silhouetteUA/k8s-controller/pkg/manifest"
+)
+
+var diffColor string
+
+var diffCmd = &cobra.Command{
+	Use:   "diff -f PATH...",
+	Short: "Show what apply would change",
+	Long: `Shows a unified diff between the live objects and the result of applying the manifests, computed
+with a server-side dry run so defaults and other field managers are taken into account. With
+--prune, objects that apply would delete are shown as removed.
+
+Exits with 0 when there are no differences, 2 when there are and 1 on error.`,
+	Example: `  kctl diff -f pages/
+  kctl diff -f pages/ --prune -l app.kubernetes.io/part-of=site --color=never`,
+	Args: cobra.NoArgs,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		if diffColor != "auto" && diffColor != "always" && diffColor != "never" {
+			log.Error().Str("color", diffColor).Msg("Invalid --color, use auto, always or never")
+			os.Exit(1)
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		var diff string
+		runWithClient("diff manifests", func(ctx context.Context, c client.Client) error {
+			var err error
+			diff, err = diffManifests(ctx, c, cmd)
+			return err
+		})
+		if diff == "" {
+			return
+		}
+		if diffColor == "always" || diffColor == "auto" && term.IsTerminal(int(os.Stdout.Fd())) {
+			diff = manifest.Colorize(diff)
+		}
+		fmt.Fprint(cmd.OutOrStdout(), diff)
+		os.Exit(2)
+	},
+}
+
+func diffManifests(ctx context.Context, c client.Client, cmd *cobra.Command) (string, error) {
+	applier, objs, selector, err := loadManifests(c, cmd)
+	if err != nil {
+		return "", err
+	}
+	var prune []*unstructured.Unstructured
+	if applyPrune {
+		if prune, err = applier.PruneCandidates(ctx, objs, selector, pruneKinds); err != nil {
+			return "", err
+		}
+	}
+	return applier.Diff(ctx, objs, prune)
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+	addManifestFlags(diffCmd)
+	diffCmd.Flags().StringVar(&diffColor, "color", "auto", "Color the diff: auto, always or never")
+}
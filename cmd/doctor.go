@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/silhouetteUA/k8s-controller/pkg/controller"
+	"github.com/silhouetteUA/k8s-controller/pkg/printers"
+)
+
+var doctorDelete bool
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose problems with the objects kctl manages",
+}
+
+var doctorOrphansCmd = &cobra.Command{
+	Use:   "orphans",
+	Short: "Find ConfigMaps, Deployments and CronJobs left behind by, or drifted from, their FrontendPages",
+	Long: `Lists the children built for FrontendPages and FrontendPageBackups and reports:
+
+  Orphaned  the owner no longer exists, was recreated, or no longer produces the object
+  Drifted   the owner exists but the object differs from what the controller builds
+
+With --delete orphaned objects are deleted; drifted ones are only reported.
+Exits with status 2 when findings remain.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := getRuntimeClient(kubeconfig)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to create Kubernetes client")
+			os.Exit(1)
+		}
+		ctx := context.Background()
+		findings, err := controller.ScanOrphans(ctx, c, namespace)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to scan for orphans")
+			os.Exit(1)
+		}
+		if doctorDelete {
+			deleted, err := controller.DeleteOrphans(ctx, c, findings)
+			if err != nil {
+				log.Error().Err(err).Msg("Failed to delete orphans")
+				os.Exit(1)
+			}
+			fmt.Fprintf(os.Stderr, "Deleted %d orphaned object(s)\n", deleted)
+			remaining := findings[:0]
+			for _, f := range findings {
+				if f.Problem != controller.ProblemOrphaned {
+					remaining = append(remaining, f)
+				}
+			}
+			findings = remaining
+		}
+
+		if len(findings) == 0 && printOptions.IsTable() {
+			fmt.Fprintf(cmd.ErrOrStderr(), "No orphaned or drifted objects in namespace %q\n", namespace)
+			return
+		}
+		printOutput(cmd, findingsOutput(findings))
+		if len(findings) > 0 {
+			os.Exit(2)
+		}
+	},
+}
+
+// findingsOutput describes scan findings for the printers.
+func findingsOutput(findings []controller.Finding) printers.Output {
+	if findings == nil {
+		findings = []controller.Finding{}
+	}
+	out := printers.Output{
+		Object: findings,
+		Columns: []printers.Column{
+			{Header: "KIND"},
+			{Header: "NAME"},
+			{Header: "PROBLEM"},
+			{Header: "OWNER"},
+			{Header: "REASON"},
+			{Header: "NAMESPACE", Wide: true},
+			{Header: "UID", Wide: true},
+		},
+	}
+	for _, f := range findings {
+		reason := f.Reason
+		if len(f.Drift) > 0 {
+			reason = strings.Join(f.Drift, "; ")
+		}
+		out.Rows = append(out.Rows, []string{f.Kind, f.Name, string(f.Problem), orNone(f.Owner), reason, f.Namespace, string(f.UID)})
+		out.Names = append(out.Names, strings.ToLower(f.Kind)+"/"+f.Name)
+	}
+	return out
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.AddCommand(doctorOrphansCmd)
+	doctorOrphansCmd.Flags().BoolVar(&doctorDelete, "delete", false, "Delete orphaned objects (drifted objects are never deleted)")
+	addOutputFlags(doctorOrphansCmd)
+}
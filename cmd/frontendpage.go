@@ -0,0 +1,379 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	frontendv1alpha1 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/v1alpha1"
+	"github.com/silhouetteUA/k8s-controller/pkg/printers"
+	"github.com/silhouetteUA/k8s-controller/pkg/render"
+)
+
+var fpImage string
+var fpReplicas int
+var fpScaleReplicas int
+var fpContents string
+var fpFromFile string
+var fpLabels map[string]string
+var fpSelector string
+var fpAllNamespaces bool
+
+var fpCmd = &cobra.Command{
+	Use:     "frontendpage",
+	Aliases: []string{"fp", "frontendpages"},
+	Short:   "Manage FrontendPages",
+}
+
+var fpCreateCmd = &cobra.Command{
+	Use:   "create NAME --image IMAGE (--contents TEXT | --from-file FILE)",
+	Short: "Create a FrontendPage",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		contents, err := fpContentsFromFlags(cmd)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to read page contents")
+			os.Exit(1)
+		}
+		page := newFrontendPage(args[0], contents)
+		runWithClient("create FrontendPage", func(ctx context.Context, c client.Client) error {
+			return createFrontendPage(ctx, c, cmd.OutOrStdout(), page)
+		})
+	},
+}
+
+// newFrontendPage builds the page fp create sends from its flags.
+func newFrontendPage(name, contents string) *frontendv1alpha1.FrontendPage {
+	return &frontendv1alpha1.FrontendPage{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: fpLabels},
+		Spec:       frontendv1alpha1.FrontendPageSpec{Contents: contents, Image: fpImage, Replicas: fpReplicas},
+	}
+}
+
+var fpGetCmd = &cobra.Command{
+	Use:   "get NAME",
+	Short: "Show a FrontendPage",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runWithClient("get FrontendPage", func(ctx context.Context, c client.Client) error {
+			var page frontendv1alpha1.FrontendPage
+			if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: args[0]}, &page); err != nil {
+				return err
+			}
+			out, err := frontendPagesOutput(ctx, c, []frontendv1alpha1.FrontendPage{page}, false)
+			if err != nil {
+				return err
+			}
+			out.Object = out.Object.(*frontendv1alpha1.FrontendPageList).Items[0]
+			printOutput(cmd, out)
+			return nil
+		})
+	},
+}
+
+var fpListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List FrontendPages",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ns := namespace
+		if fpAllNamespaces {
+			ns = ""
+		}
+		runWithClient("list FrontendPages", func(ctx context.Context, c client.Client) error {
+			pages, err := listFrontendPages(ctx, c, ns, fpSelector)
+			if err != nil {
+				return err
+			}
+			if len(pages) == 0 && printOptions.IsTable() {
+				printNoResources(cmd, "FrontendPages", ns)
+				return nil
+			}
+			out, err := frontendPagesOutput(ctx, c, pages, fpAllNamespaces)
+			if err != nil {
+				return err
+			}
+			printOutput(cmd, out)
+			return nil
+		})
+	},
+}
+
+var fpUpdateCmd = &cobra.Command{
+	Use:   "update NAME [--image IMAGE] [--replicas N] [--contents TEXT | --from-file FILE] [--labels K=V,...]",
+	Short: "Change the spec or labels of a FrontendPage",
+	Long:  "Only the flags given are changed. Labels are merged into the existing ones; an empty value removes a label.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		flags := cmd.Flags()
+		if !flags.Changed("image") && !flags.Changed("replicas") && !flags.Changed("contents") &&
+			!flags.Changed("from-file") && !flags.Changed("labels") {
+			log.Error().Msg("Nothing to update, set at least one of --image, --replicas, --contents, --from-file or --labels")
+			os.Exit(1)
+		}
+		contents, err := fpContentsFromFlags(cmd)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to read page contents")
+			os.Exit(1)
+		}
+		mutate := func(page *frontendv1alpha1.FrontendPage) {
+			if flags.Changed("image") {
+				page.Spec.Image = fpImage
+			}
+			if flags.Changed("replicas") {
+				page.Spec.Replicas = fpReplicas
+			}
+			if flags.Changed("contents") || flags.Changed("from-file") {
+				page.Spec.Contents = contents
+			}
+			mergeLabels(&page.ObjectMeta, fpLabels)
+		}
+		runWithClient("update FrontendPage", func(ctx context.Context, c client.Client) error {
+			key := types.NamespacedName{Namespace: namespace, Name: args[0]}
+			if err := updateFrontendPage(ctx, c, key, mutate); err != nil {
+				return err
+			}
+			_, err := fmt.Fprintf(cmd.OutOrStdout(), "frontendpage %q updated\n", args[0])
+			return err
+		})
+	},
+}
+
+var fpDeleteCmd = &cobra.Command{
+	Use:   "delete NAME...",
+	Short: "Delete FrontendPages",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runWithClient("delete FrontendPages", func(ctx context.Context, c client.Client) error {
+			return deleteFrontendPages(ctx, c, cmd.OutOrStdout(), namespace, args)
+		})
+	},
+}
+
+var fpScaleCmd = &cobra.Command{
+	Use:   "scale NAME --replicas N",
+	Short: "Set the replica count of a FrontendPage",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if fpScaleReplicas < 0 {
+			log.Error().Msg("--replicas must not be negative")
+			os.Exit(1)
+		}
+		runWithClient("scale FrontendPage", func(ctx context.Context, c client.Client) error {
+			key := types.NamespacedName{Namespace: namespace, Name: args[0]}
+			if err := scaleFrontendPage(ctx, c, key, fpScaleReplicas); err != nil {
+				return err
+			}
+			_, err := fmt.Fprintf(cmd.OutOrStdout(), "frontendpage %q scaled to %d\n", args[0], fpScaleReplicas)
+			return err
+		})
+	},
+}
+
+// runWithClient connects to the cluster and runs fn, exiting on failure.
+func runWithClient(action string, fn func(ctx context.Context, c client.Client) error) {
+	runWithWatchClient(action, func(ctx context.Context, c client.WithWatch) error {
+		return fn(ctx, c)
+	})
+}
+
+// runWithWatchClient is runWithClient for commands that also watch. An *exitError from fn
+// sets the exit code.
+func runWithWatchClient(action string, fn func(ctx context.Context, c client.WithWatch) error) {
+	c, err := getRuntimeClient(kubeconfig)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to create Kubernetes client")
+		os.Exit(1)
+	}
+	if err := fn(context.Background(), c); err != nil {
+		log.Error().Err(err).Msg("Failed to " + action)
+		code := 1
+		var exit *exitError
+		if errors.As(err, &exit) {
+			code = exit.code
+		}
+		os.Exit(code)
+	}
+}
+
+// fpContentsFromFlags returns --contents, or the contents of --from-file ("-" reads stdin).
+func fpContentsFromFlags(cmd *cobra.Command) (string, error) {
+	if fpFromFile == "" {
+		return fpContents, nil
+	}
+	if fpFromFile == "-" {
+		data, err := io.ReadAll(cmd.InOrStdin())
+		return string(data), err
+	}
+	data, err := os.ReadFile(fpFromFile)
+	return string(data), err
+}
+
+func createFrontendPage(ctx context.Context, c client.Client, out io.Writer, page *frontendv1alpha1.FrontendPage) error {
+	if page.Spec.Image == "" {
+		return fmt.Errorf("--image is required")
+	}
+	if page.Spec.Replicas < 0 {
+		return fmt.Errorf("--replicas must not be negative")
+	}
+	if err := c.Create(ctx, page); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(out, "frontendpage %q created in namespace %q\n", page.Name, page.Namespace)
+	return err
+}
+
+// listFrontendPages lists the pages of namespace ("" for all) matching a label selector.
+func listFrontendPages(ctx context.Context, c client.Client, namespace, selector string) ([]frontendv1alpha1.FrontendPage, error) {
+	sel, err := labels.Parse(selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector %q: %w", selector, err)
+	}
+	var pages frontendv1alpha1.FrontendPageList
+	if err := c.List(ctx, &pages, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: sel}); err != nil {
+		return nil, err
+	}
+	return pages.Items, nil
+}
+
+// updateFrontendPage applies mutate to the latest version of the page, retrying on conflicts.
+func updateFrontendPage(ctx context.Context, c client.Client, key types.NamespacedName, mutate func(*frontendv1alpha1.FrontendPage)) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var page frontendv1alpha1.FrontendPage
+		if err := c.Get(ctx, key, &page); err != nil {
+			return err
+		}
+		mutate(&page)
+		if page.Spec.Replicas < 0 {
+			return fmt.Errorf("replicas must not be negative")
+		}
+		return c.Update(ctx, &page)
+	})
+}
+
+func scaleFrontendPage(ctx context.Context, c client.Client, key types.NamespacedName, replicas int) error {
+	page := &frontendv1alpha1.FrontendPage{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}}
+	patch := []byte(fmt.Sprintf(`{"spec":{"replicas":%d}}`, replicas))
+	return c.Patch(ctx, page, client.RawPatch(types.MergePatchType, patch))
+}
+
+// deleteFrontendPages deletes every named page, reporting the ones that do not exist.
+func deleteFrontendPages(ctx context.Context, c client.Client, out io.Writer, namespace string, names []string) error {
+	var missing []string
+	for _, name := range names {
+		page := &frontendv1alpha1.FrontendPage{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+		err := c.Delete(ctx, page)
+		switch {
+		case apierrors.IsNotFound(err):
+			missing = append(missing, name)
+			continue
+		case err != nil:
+			return err
+		}
+		if _, err := fmt.Fprintf(out, "frontendpage %q deleted\n", name); err != nil {
+			return err
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("frontendpages not found in namespace %q: %v", namespace, missing)
+	}
+	return nil
+}
+
+// mergeLabels sets labels on meta; an empty value removes the label.
+func mergeLabels(meta *metav1.ObjectMeta, set map[string]string) {
+	for k, v := range set {
+		if v == "" {
+			delete(meta.Labels, k)
+			continue
+		}
+		if meta.Labels == nil {
+			meta.Labels = map[string]string{}
+		}
+		meta.Labels[k] = v
+	}
+}
+
+// frontendPagesOutput describes pages for the printers. READY counts the ready replicas of
+// the Deployment built for each page against the desired replicas.
+func frontendPagesOutput(ctx context.Context, c client.Client, pages []frontendv1alpha1.FrontendPage, withNamespace bool) (printers.Output, error) {
+	listNamespace := ""
+	if len(pages) > 0 && !withNamespace {
+		listNamespace = pages[0].Namespace
+	}
+	var deployments appsv1.DeploymentList
+	if err := c.List(ctx, &deployments, client.InNamespace(listNamespace), client.HasLabels{render.LabelFrontendPage}); err != nil {
+		return printers.Output{}, err
+	}
+	ready := map[types.NamespacedName]int32{}
+	for _, d := range deployments.Items {
+		ready[types.NamespacedName{Namespace: d.Namespace, Name: d.Labels[render.LabelFrontendPage]}] = d.Status.ReadyReplicas
+	}
+
+	list := &frontendv1alpha1.FrontendPageList{TypeMeta: listTypeMeta(frontendv1alpha1.SchemeGroupVersion.String(), "FrontendPage")}
+	out := printers.Output{Object: list}
+	if withNamespace {
+		out.Columns = append(out.Columns, printers.Column{Header: "NAMESPACE"})
+	}
+	out.Columns = append(out.Columns,
+		printers.Column{Header: "NAME"},
+		printers.Column{Header: "READY"},
+		printers.Column{Header: "IMAGE"},
+		printers.Column{Header: "AGE"},
+		printers.Column{Header: "CONTENTS", Wide: true},
+		printers.Column{Header: "LABELS", Wide: true},
+	)
+	for _, p := range pages {
+		p.APIVersion, p.Kind = frontendv1alpha1.SchemeGroupVersion.String(), "FrontendPage"
+		p.ManagedFields = nil
+		list.Items = append(list.Items, p)
+		var row []string
+		if withNamespace {
+			row = append(row, p.Namespace)
+		}
+		row = append(row,
+			p.Name,
+			fmt.Sprintf("%d/%d", ready[types.NamespacedName{Namespace: p.Namespace, Name: p.Name}], p.Spec.Replicas),
+			p.Spec.Image,
+			age(p.CreationTimestamp),
+			fmt.Sprintf("%d bytes", len(p.Spec.Contents)),
+			orNone(labels.FormatLabels(p.Labels)),
+		)
+		out.Rows = append(out.Rows, row)
+		out.Names = append(out.Names, resourceName("FrontendPage", frontendv1alpha1.SchemeGroupVersion.Group, p.Name))
+	}
+	return out, nil
+}
+
+func init() {
+	rootCmd.AddCommand(fpCmd)
+	fpCmd.AddCommand(fpCreateCmd, fpGetCmd, fpListCmd, fpUpdateCmd, fpDeleteCmd, fpScaleCmd)
+
+	for _, c := range []*cobra.Command{fpCreateCmd, fpUpdateCmd} {
+		c.Flags().StringVar(&fpImage, "image", "", "Container image serving the page")
+		c.Flags().IntVar(&fpReplicas, "replicas", 1, "Number of replicas")
+		c.Flags().StringVar(&fpContents, "contents", "", "Page contents")
+		c.Flags().StringVarP(&fpFromFile, "from-file", "f", "", "Read the page contents from a file, - for stdin")
+		c.Flags().StringToStringVar(&fpLabels, "labels", nil, "Labels to set, e.g. team=web,tier=front")
+		c.MarkFlagsMutuallyExclusive("contents", "from-file")
+	}
+	_ = fpCreateCmd.MarkFlagRequired("image")
+	fpScaleCmd.Flags().IntVar(&fpScaleReplicas, "replicas", 0, "Desired number of replicas")
+	_ = fpScaleCmd.MarkFlagRequired("replicas")
+	fpListCmd.Flags().StringVarP(&fpSelector, "selector", "l", "", "Label selector, e.g. team=web,tier!=cache")
+	addOutputFlags(fpGetCmd)
+	addOutputFlags(fpListCmd)
+	fpListCmd.Flags().BoolVarP(&fpAllNamespaces, "all-namespaces", "A", false, "List FrontendPages in every namespace")
+}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	frontendv1alpha1 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/v1alpha1"
+)
+
+const editHeader = `# Edit the FrontendPage below and save to apply the change. These comment lines are
+# ignored; an empty file or an unchanged one cancels the edit.
+#
+`
+
+var fpEditCmd = &cobra.Command{
+	Use:   "edit NAME",
+	Short: "Edit a FrontendPage in $EDITOR",
+	Long: `Opens the FrontendPage as YAML in $KCTL_EDITOR, $EDITOR or vi and updates it with the saved
+result. If the page changed in the cluster while it was being edited, nothing is written and the
+edited copy is kept so it can be re-applied.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runWithClient("edit FrontendPage", func(ctx context.Context, c client.Client) error {
+			key := types.NamespacedName{Namespace: namespace, Name: args[0]}
+			return editFrontendPage(ctx, c, cmd.OutOrStdout(), key, runEditor)
+		})
+	},
+}
+
+// editFrontendPage writes the page to a temporary file, lets edit change it and updates
+// the page with the result. The update is conditional on the resourceVersion read before
+// editing, so concurrent changes are reported as a conflict rather than overwritten.
+func editFrontendPage(ctx context.Context, c client.Client, out io.Writer, key types.NamespacedName, edit func(path string) error) error {
+	var page frontendv1alpha1.FrontendPage
+	if err := c.Get(ctx, key, &page); err != nil {
+		return err
+	}
+	page.APIVersion, page.Kind = frontendv1alpha1.SchemeGroupVersion.String(), "FrontendPage"
+	page.ManagedFields = nil
+	original, err := yaml.Marshal(&page)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp("", "kctl-edit-"+key.Name+"-*.yaml")
+	if err != nil {
+		return err
+	}
+	path := f.Name()
+	_, err = f.Write(append([]byte(editHeader), original...))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return err
+	}
+	keep := false
+	defer func() {
+		if !keep {
+			_ = os.Remove(path)
+		}
+	}()
+
+	if err := edit(path); err != nil {
+		return fmt.Errorf("editor: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	edited := stripHeader(data)
+	if len(bytes.TrimSpace(edited)) == 0 || bytes.Equal(edited, original) {
+		_, err := fmt.Fprintln(out, "Edit cancelled, no changes made")
+		return err
+	}
+
+	var updated frontendv1alpha1.FrontendPage
+	if err := yaml.UnmarshalStrict(edited, &updated); err != nil {
+		keep = true
+		return fmt.Errorf("invalid FrontendPage, your changes are saved in %s: %w", path, err)
+	}
+	if updated.Name != page.Name || updated.Namespace != page.Namespace {
+		keep = true
+		return fmt.Errorf("the name and namespace cannot be changed, your changes are saved in %s", path)
+	}
+	updated.ResourceVersion = page.ResourceVersion
+	if err := c.Update(ctx, &updated); err != nil {
+		keep = true
+		if apierrors.IsConflict(err) {
+			return fmt.Errorf("frontendpage %q was modified while it was being edited, your changes are saved in %s", key.Name, path)
+		}
+		return fmt.Errorf("%w (your changes are saved in %s)", err, path)
+	}
+	_, err = fmt.Fprintf(out, "frontendpage %q edited\n", key.Name)
+	return err
+}
+
+// stripHeader drops the comment lines at the top of the file. Later lines are kept, as
+// they may belong to multi-line page contents.
+func stripHeader(data []byte) []byte {
+	for len(data) > 0 && data[0] == '#' {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			return nil
+		}
+		data = data[i+1:]
+	}
+	return data
+}
+
+// runEditor opens path in $KCTL_EDITOR, $EDITOR or vi. The variable may carry arguments,
+// e.g. "code --wait".
+func runEditor(path string) error {
+	editor := os.Getenv("KCTL_EDITOR")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	args := append(strings.Fields(editor), path)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return cmd.Run()
+}
+
+func init() {
+	fpCmd.AddCommand(fpEditCmd)
+}
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	frontendv1alpha1 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/v1alpha1"
+	"github.com/silhouetteUA/k8s-controller/pkg/printers"
+	"github.com/silhouetteUA/k8s-controller/pkg/render"
+)
+
+var fpEventsCmd = &cobra.Command{
+	Use:   "events NAME",
+	Short: "List the events of a FrontendPage and the objects built for it",
+	Long: `Lists the events of the FrontendPage, its Deployment with its ReplicaSets and Pods, its
+ConfigMap and its backup CronJob with its Jobs and their Pods, oldest first.`,
+	Example: `  kctl fp events home
+  kctl fp events home -o yaml`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runWithClient("list FrontendPage events", func(ctx context.Context, c client.Client) error {
+			key := types.NamespacedName{Namespace: namespace, Name: args[0]}
+			var page frontendv1alpha1.FrontendPage
+			if err := c.Get(ctx, key, &page); err != nil {
+				return err
+			}
+			events, err := pageEvents(ctx, c, key)
+			if err != nil {
+				return err
+			}
+			printOutput(cmd, eventsOutput(events))
+			return nil
+		})
+	},
+}
+
+// objectRef identifies an object within the namespace of a page.
+type objectRef struct {
+	Kind, Name string
+}
+
+// pageObjects returns the objects built for a page and the ones those own in turn: its
+// Deployment with its ReplicaSets and Pods, its ConfigMap, and its backup CronJob with its
+// Jobs and their Pods. Ownership is followed through controller references, so the children
+// of another page whose name merely starts with this one are left out.
+func pageObjects(ctx context.Context, c client.Client, page types.NamespacedName) (map[objectRef]bool, error) {
+	cron := render.BackupCronJobName(page.Name)
+	objs := map[objectRef]bool{
+		{"FrontendPage", page.Name}: true,
+		{"Deployment", page.Name}:   true,
+		{"ConfigMap", page.Name}:    true,
+		{"CronJob", cron}:           true,
+	}
+	var replicaSets appsv1.ReplicaSetList
+	if err := c.List(ctx, &replicaSets, client.InNamespace(page.Namespace)); err != nil {
+		return nil, err
+	}
+	for _, rs := range replicaSets.Items {
+		addOwned(objs, "ReplicaSet", &rs)
+	}
+	var jobs batchv1.JobList
+	if err := c.List(ctx, &jobs, client.InNamespace(page.Namespace)); err != nil {
+		return nil, err
+	}
+	for _, job := range jobs.Items {
+		addOwned(objs, "Job", &job)
+	}
+	var pods corev1.PodList
+	if err := c.List(ctx, &pods, client.InNamespace(page.Namespace)); err != nil {
+		return nil, err
+	}
+	for _, pod := range pods.Items {
+		addOwned(objs, "Pod", &pod)
+	}
+	return objs, nil
+}
+
+// addOwned adds obj to objs when its controller is already in objs.
+func addOwned(objs map[objectRef]bool, kind string, obj metav1.Object) {
+	if owner := metav1.GetControllerOf(obj); owner != nil && objs[objectRef{owner.Kind, owner.Name}] {
+		objs[objectRef{kind, obj.GetName()}] = true
+	}
+}
+
+// pageEvents returns the events of a page and of the objects built for it, oldest first.
+// Events of children that are gone, such as the Pods of an old ReplicaSet, are kept when
+// their name shows they belonged to the page.
+func pageEvents(ctx context.Context, c client.Client, page types.NamespacedName) ([]corev1.Event, error) {
+	objs, err := pageObjects(ctx, c, page)
+	if err != nil {
+		return nil, err
+	}
+	var events corev1.EventList
+	if err := c.List(ctx, &events, client.InNamespace(page.Namespace)); err != nil {
+		return nil, err
+	}
+	var matched []corev1.Event
+	for _, e := range events.Items {
+		ref := objectRef{e.InvolvedObject.Kind, e.InvolvedObject.Name}
+		if objs[ref] || goneChild(objs, ref) {
+			matched = append(matched, e)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return eventTime(matched[i]).Before(eventTime(matched[j]))
+	})
+	return matched, nil
+}
+
+// goneChild reports whether ref names a deleted ReplicaSet, Job or Pod whose generated name
+// starts with the name of one of objs: <deployment>-<hash>, <cronjob>-<time> or <owner>-<suffix>.
+func goneChild(objs map[objectRef]bool, ref objectRef) bool {
+	var owners []string
+	switch ref.Kind {
+	case "ReplicaSet":
+		owners = []string{"Deployment"}
+	case "Job":
+		owners = []string{"CronJob"}
+	case "Pod":
+		owners = []string{"ReplicaSet", "Job"}
+	default:
+		return false
+	}
+	i := strings.LastIndex(ref.Name, "-")
+	if i < 0 {
+		return false
+	}
+	owner := ref.Name[:i]
+	for _, kind := range owners {
+		if objs[objectRef{kind, owner}] || goneChild(objs, objectRef{kind, owner}) {
+			return true
+		}
+	}
+	return false
+}
+
+// eventTime is when an event last happened.
+func eventTime(e corev1.Event) time.Time {
+	switch {
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	}
+	return e.CreationTimestamp.Time
+}
+
+// eventsOutput describes events for the printers.
+func eventsOutput(events []corev1.Event) printers.Output {
+	list := &corev1.EventList{TypeMeta: listTypeMeta(corev1.SchemeGroupVersion.String(), "Event")}
+	out := printers.Output{
+		Object: list,
+		Columns: []printers.Column{
+			{Header: "LAST SEEN"},
+			{Header: "TYPE"},
+			{Header: "REASON"},
+			{Header: "OBJECT"},
+			{Header: "MESSAGE"},
+		},
+	}
+	for _, e := range events {
+		e.APIVersion, e.Kind = corev1.SchemeGroupVersion.String(), "Event"
+		e.ManagedFields = nil
+		list.Items = append(list.Items, e)
+		object := strings.ToLower(e.InvolvedObject.Kind) + "/" + e.InvolvedObject.Name
+		out.Rows = append(out.Rows, []string{age(metav1.NewTime(eventTime(e))), e.Type, e.Reason, object, e.Message})
+		out.Names = append(out.Names, "event/"+e.Name)
+	}
+	return out
+}
+
+func init() {
+	fpCmd.AddCommand(fpEventsCmd)
+	addOutputFlags(fpEventsCmd)
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	frontendv1alpha1 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/v1alpha1"
+)
+
+var (
+	logsFollow     bool
+	logsSince      time.Duration
+	logsTail       int64
+	logsTimestamps bool
+)
+
+var fpLogsCmd = &cobra.Command{
+	Use:   "logs NAME",
+	Short: "Print the logs of all pods of a FrontendPage",
+	Long: `Prints the logs of every pod of the FrontendPage's Deployment, each line prefixed with
+[pod/NAME]. With --follow the logs are streamed until all pods stop or kctl is interrupted;
+lines of different pods are interleaved as they arrive.`,
+	Example: `  kctl fp logs home
+  kctl fp logs home -f --since 10m`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runWithClient("get FrontendPage logs", func(ctx context.Context, c client.Client) error {
+			clientset, err := getKubeClient(kubeconfig)
+			if err != nil {
+				return err
+			}
+			opts := corev1.PodLogOptions{Follow: logsFollow, Timestamps: logsTimestamps}
+			if logsSince > 0 {
+				seconds := int64(logsSince.Round(time.Second).Seconds())
+				opts.SinceSeconds = &seconds
+			}
+			if logsTail >= 0 {
+				opts.TailLines = &logsTail
+			}
+			key := types.NamespacedName{Namespace: namespace, Name: args[0]}
+			return frontendPageLogs(ctx, c, clientset, cmd.OutOrStdout(), key, opts)
+		})
+	},
+}
+
+// pagePods returns the pods of the page's Deployment, sorted by name.
+func pagePods(ctx context.Context, c client.Client, page types.NamespacedName) ([]corev1.Pod, error) {
+	objs, err := pageObjects(ctx, c, page)
+	if err != nil {
+		return nil, err
+	}
+	var pods corev1.PodList
+	if err := c.List(ctx, &pods, client.InNamespace(page.Namespace), client.MatchingLabels{"app": page.Name}); err != nil {
+		return nil, err
+	}
+	var owned []corev1.Pod
+	for _, pod := range pods.Items {
+		if objs[objectRef{"Pod", pod.Name}] {
+			owned = append(owned, pod)
+		}
+	}
+	sort.Slice(owned, func(i, j int) bool { return owned[i].Name < owned[j].Name })
+	return owned, nil
+}
+
+// frontendPageLogs streams the logs of every pod of the page to out, prefixing each line
+// with the pod. A pod whose logs cannot be read does not stop the others; the failures are
+// returned together once all streams end.
+func frontendPageLogs(ctx context.Context, c client.Client, clientset kubernetes.Interface, out io.Writer, key types.NamespacedName, opts corev1.PodLogOptions) error {
+	var page frontendv1alpha1.FrontendPage
+	if err := c.Get(ctx, key, &page); err != nil {
+		return err
+	}
+	pods, err := pagePods(ctx, c, key)
+	if err != nil {
+		return err
+	}
+	if len(pods) == 0 {
+		return fmt.Errorf("frontendpage %q has no pods", key.Name)
+	}
+
+	var (
+		mu   sync.Mutex
+		errs []error
+		wg   sync.WaitGroup
+	)
+	for _, pod := range pods {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := streamPodLogs(ctx, clientset, &mu, out, pod.Namespace, pod.Name, opts)
+			if err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("pod %q: %w", pod.Name, err))
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// streamPodLogs copies the logs of one pod to out line by line, holding mu for each write
+// so lines of concurrent pods are never mixed.
+func streamPodLogs(ctx context.Context, clientset kubernetes.Interface, mu *sync.Mutex, out io.Writer, namespace, name string, opts corev1.PodLogOptions) error {
+	stream, err := clientset.CoreV1().Pods(namespace).GetLogs(name, &opts).Stream(ctx)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+	prefix := "[pod/" + name + "] "
+	r := bufio.NewReader(stream)
+	for {
+		line, err := r.ReadString('\n')
+		if line != "" {
+			if line[len(line)-1] != '\n' {
+				line += "\n"
+			}
+			mu.Lock()
+			_, werr := io.WriteString(out, prefix+line)
+			mu.Unlock()
+			if werr != nil {
+				return werr
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func init() {
+	fpCmd.AddCommand(fpLogsCmd)
+	fpLogsCmd.Flags().BoolVarP(&logsFollow, "follow", "f", false, "Stream the logs until the pods stop")
+	fpLogsCmd.Flags().DurationVar(&logsSince, "since", 0, "Only print logs newer than this, e.g. 10m; 0 prints all")
+	fpLogsCmd.Flags().Int64Var(&logsTail, "tail", -1, "Lines of recent logs to print per pod, -1 prints all")
+	fpLogsCmd.Flags().BoolVar(&logsTimestamps, "timestamps", false, "Include the timestamp of each line")
+}
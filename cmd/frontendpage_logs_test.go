@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	frontendv1alpha1 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/v1alpha1"
+)
+
+// pageChildrenClient returns a fake client with the pages home and home-v2 in namespace web,
+// their Deployments, ReplicaSets and Pods, a backup of home with a Job and its Pod, and events
+// for them.
+func pageChildrenClient(t *testing.T) client.Client {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, frontendv1alpha1.AddToScheme(scheme))
+	isController := true
+	owned := func(kind, name string) []metav1.OwnerReference {
+		return []metav1.OwnerReference{{Kind: kind, Name: name, Controller: &isController}}
+	}
+	pod := func(name, app, rs string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name: name, Namespace: "web", Labels: map[string]string{"app": app}, OwnerReferences: owned("ReplicaSet", rs),
+		}}
+	}
+	event := func(kind, name string, ago time.Duration) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: strings.ToLower(kind) + "." + name, Namespace: "web"},
+			InvolvedObject: corev1.ObjectReference{Kind: kind, Name: name},
+			Reason:         "Test", Type: "Normal",
+			LastTimestamp: metav1.NewTime(time.Now().Add(-ago)),
+		}
+	}
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&frontendv1alpha1.FrontendPage{ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "web"}},
+		&frontendv1alpha1.FrontendPage{ObjectMeta: metav1.ObjectMeta{Name: "home-v2", Namespace: "web"}},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "home-7d9f", Namespace: "web", OwnerReferences: owned("Deployment", "home")}},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "home-v2-5c8b", Namespace: "web", OwnerReferences: owned("Deployment", "home-v2")}},
+		pod("home-7d9f-b2x4k", "home", "home-7d9f"),
+		pod("home-7d9f-a1z9q", "home", "home-7d9f"),
+		pod("home-v2-5c8b-k3j2m", "home-v2", "home-v2-5c8b"),
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "backup-home-29000000", Namespace: "web", OwnerReferences: owned("CronJob", "backup-home")}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "backup-home-29000000-p8w2r", Namespace: "web", OwnerReferences: owned("Job", "backup-home-29000000")}},
+		event("Pod", "home-7d9f-b2x4k", 3*time.Minute),
+		event("FrontendPage", "home", 10*time.Minute),
+		event("Pod", "home-6b4c-z7y2x", 20*time.Minute),
+		event("Pod", "backup-home-29000000-p8w2r", time.Minute),
+		event("Pod", "home-v2-5c8b-k3j2m", 2*time.Minute),
+		event("Deployment", "home-v2", 2*time.Minute),
+		event("Service", "home", 2*time.Minute),
+	).Build()
+}
+
+func TestPageEvents(t *testing.T) {
+	c := pageChildrenClient(t)
+	events, err := pageEvents(context.Background(), c, types.NamespacedName{Namespace: "web", Name: "home"})
+	require.NoError(t, err)
+	out := eventsOutput(events)
+	var objects []string
+	for _, row := range out.Rows {
+		objects = append(objects, row[3])
+	}
+	require.Equal(t, []string{
+		"pod/home-6b4c-z7y2x", // of a ReplicaSet that is gone
+		"frontendpage/home",
+		"pod/home-7d9f-b2x4k",
+		"pod/backup-home-29000000-p8w2r",
+	}, objects, "events of home-v2 and of objects not built for the page are left out")
+}
+
+func TestFrontendPageLogs(t *testing.T) {
+	c := pageChildrenClient(t)
+	ctx := context.Background()
+	var out bytes.Buffer
+	err := frontendPageLogs(ctx, c, kubefake.NewClientset(), &out, types.NamespacedName{Namespace: "web", Name: "home"}, corev1.PodLogOptions{})
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	require.ElementsMatch(t, []string{"[pod/home-7d9f-a1z9q] fake logs", "[pod/home-7d9f-b2x4k] fake logs"}, lines)
+
+	err = frontendPageLogs(ctx, c, kubefake.NewClientset(), &out, types.NamespacedName{Namespace: "web", Name: "about"}, corev1.PodLogOptions{})
+	require.Error(t, err)
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/silhouetteUA/k8s-controller/pkg/manifest"
+	"github.com/silhouetteUA/k8s-controller/pkg/render"
+)
+
+var renderFiles []string
+
+var fpRenderCmd = &cobra.Command{
+	Use:   "render -f PATH...",
+	Short: "Print the objects the controller would create for FrontendPages",
+	Long: `Renders the ConfigMap and Deployment of each FrontendPage in the given files, and the backup
+CronJob of each FrontendPageBackup of those pages, exactly as the controller builds them. Nothing
+is read from or sent to the cluster, so it works in CI to show what a change to a page does.
+
+The objects are printed as a YAML stream unless -o is given. Objects without a namespace are
+rendered in the namespace of -n or the current context.`,
+	Example: `  kctl fp render -f pages/home.yaml
+  git show HEAD~1:pages/home.yaml | kctl fp render -f - > before.yaml`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		objs, err := renderManifests(renderFiles, cmd.InOrStdin(), namespace)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to render FrontendPages")
+			os.Exit(1)
+		}
+		if cmd.Flags().Changed("output") {
+			printOutput(cmd, manifestsOutput(objs))
+			return
+		}
+		if err := writeYAMLStream(cmd.OutOrStdout(), objs); err != nil {
+			log.Error().Err(err).Msg("Failed to print output")
+			os.Exit(1)
+		}
+	},
+}
+
+// renderManifests reads the pages and backups in paths and renders their objects.
+func renderManifests(paths []string, stdin io.Reader, namespace string) ([]*unstructured.Unstructured, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no manifests given, use -f")
+	}
+	objs, err := manifest.Load(paths, false, stdin)
+	if err != nil {
+		return nil, err
+	}
+	inputs, err := render.Inputs(objs, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("no FrontendPages found in %v", paths)
+	}
+	var rendered []*unstructured.Unstructured
+	for _, in := range inputs {
+		manifests, err := render.Manifests(in.Page, in.Backups)
+		if err != nil {
+			return nil, err
+		}
+		rendered = append(rendered, manifests...)
+	}
+	return rendered, nil
+}
+
+// writeYAMLStream writes objs as YAML documents separated by ---.
+func writeYAMLStream(w io.Writer, objs []*unstructured.Unstructured) error {
+	for i, obj := range objs {
+		data, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			if _, err := io.WriteString(w, "---\n"); err != nil {
+				return err
+			}
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func init() {
+	fpCmd.AddCommand(fpRenderCmd)
+	addOutputFlags(fpRenderCmd)
+	fpRenderCmd.Flags().StringSliceVarP(&renderFiles, "filename", "f", nil, "Manifest file or directory with FrontendPages and FrontendPageBackups, - for stdin; may be repeated")
+}
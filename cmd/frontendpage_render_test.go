@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderManifests(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "home.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+apiVersion: frontendpage.silhouetteua.io/v1alpha1
+kind: FrontendPage
+metadata:
+  name: home
+spec:
+  contents: <h1>home</h1>
+  image: nginx:1.27
+  replicas: 2
+`), 0o600))
+	backup := strings.NewReader(`{"apiVersion":"frontendpage.silhouetteua.io/v1alpha1","kind":"FrontendPageBackup",
+		"metadata":{"name":"nightly"},"spec":{"frontendPageRef":"home","schedule":"0 2 * * *"}}`)
+
+	objs, err := renderManifests([]string{path, "-"}, backup, "web")
+	require.NoError(t, err)
+	var out bytes.Buffer
+	require.NoError(t, writeYAMLStream(&out, objs))
+	docs := strings.Split(out.String(), "---\n")
+	require.Len(t, docs, 3)
+	require.Contains(t, docs[0], "kind: ConfigMap")
+	require.Contains(t, docs[0], "contents: <h1>home</h1>")
+	require.Contains(t, docs[1], "kind: Deployment")
+	require.Contains(t, docs[1], "namespace: web")
+	require.Contains(t, docs[2], "name: backup-home")
+	require.NotContains(t, out.String(), "creationTimestamp")
+
+	_, err = renderManifests(nil, nil, "web")
+	require.Error(t, err)
+}
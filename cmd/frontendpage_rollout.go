@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	frontendv1alpha1 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/v1alpha1"
+	"github.com/silhouetteUA/k8s-controller/pkg/rollout"
+)
+
+var rolloutTimeout time.Duration
+
+var fpRolloutCmd = &cobra.Command{
+	Use:   "rollout",
+	Short: "Follow FrontendPage rollouts",
+}
+
+var fpRolloutStatusCmd = &cobra.Command{
+	Use:   "status NAME",
+	Short: "Wait until the latest spec of a FrontendPage is live",
+	Long: `Follows the rollout of the FrontendPage's Deployment and the page's Ready condition, printing
+progress until the controller reports the current spec as rolled out.
+
+Exits with 0 when the rollout is complete, 2 when --timeout expires, 3 when the Deployment
+exceeded its progress deadline and 1 on any other error.`,
+	Example: `  kctl fp update home --image nginx:1.28 && kctl fp rollout status home --timeout 2m`,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runWithWatchClient("follow rollout", func(ctx context.Context, c client.WithWatch) error {
+			if rolloutTimeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, rolloutTimeout)
+				defer cancel()
+			}
+			key := types.NamespacedName{Namespace: namespace, Name: args[0]}
+			return rolloutStatus(ctx, c, cmd.OutOrStdout(), key)
+		})
+	},
+}
+
+// rolloutStatus watches the page and its Deployment, printing each new status message,
+// until the rollout completes or fails.
+func rolloutStatus(ctx context.Context, c client.WithWatch, out io.Writer, key types.NamespacedName) error {
+	last := ""
+	err := waitUntil(ctx, c, []watchTarget{
+		{&frontendv1alpha1.FrontendPageList{}, key},
+		{&appsv1.DeploymentList{}, key},
+	}, func(ctx context.Context) (bool, error) {
+		var page frontendv1alpha1.FrontendPage
+		if err := c.Get(ctx, key, &page); err != nil {
+			return false, err
+		}
+		dep := &appsv1.Deployment{}
+		if err := c.Get(ctx, key, dep); apierrors.IsNotFound(err) {
+			dep = nil
+		} else if err != nil {
+			return false, err
+		}
+		status := rollout.FrontendPageStatus(&page, dep)
+		if status.Phase == rollout.PhaseFailed {
+			return false, &exitError{code: exitRolloutFailed, err: errors.New(status.Message)}
+		}
+		if status.Message != last {
+			last = status.Message
+			if _, err := fmt.Fprintln(out, status.Message); err != nil {
+				return false, err
+			}
+		}
+		return status.Phase == rollout.PhaseComplete, nil
+	})
+	return timeoutError(err, "waiting for the rollout of frontendpage %q", key.Name)
+}
+
+func init() {
+	fpCmd.AddCommand(fpRolloutCmd)
+	fpRolloutCmd.AddCommand(fpRolloutStatusCmd)
+	fpRolloutStatusCmd.Flags().DurationVar(&rolloutTimeout, "timeout", 5*time.Minute, "How long to wait before giving up, 0 waits forever")
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	frontendv1alpha1 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/v1alpha1"
+	"github.com/silhouetteUA/k8s-controller/pkg/testutil"
+)
+
+func TestFrontendPageCommands(t *testing.T) {
+	mgr, _, restCfg, cleanup := testutil.StartTestManager(t)
+	defer cleanup()
+	// Read through the API server rather than the manager cache, as the CLI does.
+	c, err := client.New(restCfg, client.Options{Scheme: mgr.GetScheme()})
+	require.NoError(t, err)
+	ctx := context.Background()
+	var out bytes.Buffer
+
+	for _, p := range []struct{ name, team string }{{"home", "web"}, {"about", "web"}, {"admin", "ops"}} {
+		require.NoError(t, createFrontendPage(ctx, c, &out, &frontendv1alpha1.FrontendPage{
+			ObjectMeta: metav1.ObjectMeta{Name: p.name, Namespace: "default", Labels: map[string]string{"team": p.team}},
+			Spec:       frontendv1alpha1.FrontendPageSpec{Contents: "<h1>" + p.name + "</h1>", Image: "nginx:1.25", Replicas: 1},
+		}))
+	}
+	require.Contains(t, out.String(), `frontendpage "home" created`)
+	require.Error(t, createFrontendPage(ctx, c, &out, &frontendv1alpha1.FrontendPage{
+		ObjectMeta: metav1.ObjectMeta{Name: "noimage", Namespace: "default"},
+	}), "an image is required")
+
+	pages, err := listFrontendPages(ctx, c, "default", "team=web")
+	require.NoError(t, err)
+	require.Len(t, pages, 2)
+	table, err := frontendPagesOutput(ctx, c, pages, false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"frontendpage.frontendpage.silhouetteua.io/about", "frontendpage.frontendpage.silhouetteua.io/home"}, table.Names)
+	require.Equal(t, "0/1", table.Rows[0][1], "no Deployment is ready without the controller")
+	_, err = listFrontendPages(ctx, c, "default", "team in (web")
+	require.Error(t, err)
+
+	key := types.NamespacedName{Namespace: "default", Name: "home"}
+	require.NoError(t, updateFrontendPage(ctx, c, key, func(p *frontendv1alpha1.FrontendPage) {
+		p.Spec.Image = "nginx:1.27"
+		mergeLabels(&p.ObjectMeta, map[string]string{"team": "", "tier": "front"})
+	}))
+	require.NoError(t, scaleFrontendPage(ctx, c, key, 3))
+	var page frontendv1alpha1.FrontendPage
+	require.NoError(t, c.Get(ctx, key, &page))
+	require.Equal(t, "nginx:1.27", page.Spec.Image)
+	require.Equal(t, 3, page.Spec.Replicas)
+	require.Equal(t, map[string]string{"tier": "front"}, page.Labels)
+
+	out.Reset()
+	require.NoError(t, editFrontendPage(ctx, c, &out, key, func(path string) error {
+		return replaceInFile(path, "replicas: 3", "replicas: 5")
+	}))
+	require.Contains(t, out.String(), "edited")
+	require.NoError(t, c.Get(ctx, key, &page))
+	require.Equal(t, 5, page.Spec.Replicas)
+
+	out.Reset()
+	require.NoError(t, editFrontendPage(ctx, c, &out, key, func(string) error { return nil }))
+	require.Contains(t, out.String(), "Edit cancelled")
+
+	// A change made while the editor is open is a conflict, not an overwrite.
+	err = editFrontendPage(ctx, c, &out, key, func(path string) error {
+		if err := scaleFrontendPage(ctx, c, key, 1); err != nil {
+			return err
+		}
+		return replaceInFile(path, "nginx:1.27", "nginx:1.28")
+	})
+	require.ErrorContains(t, err, "modified while it was being edited")
+	saved := strings.TrimSpace(err.Error()[strings.LastIndex(err.Error(), " ")+1:])
+	defer os.Remove(saved)
+	data, readErr := os.ReadFile(saved)
+	require.NoError(t, readErr, "the edited copy is kept")
+	require.Contains(t, string(data), "nginx:1.28")
+	require.NoError(t, c.Get(ctx, key, &page))
+	require.Equal(t, "nginx:1.27", page.Spec.Image)
+	require.Equal(t, 1, page.Spec.Replicas)
+
+	out.Reset()
+	require.NoError(t, deleteFrontendPages(ctx, c, &out, "default", []string{"home", "about"}))
+	require.Contains(t, out.String(), `frontendpage "about" deleted`)
+	require.ErrorContains(t, deleteFrontendPages(ctx, c, &out, "default", []string{"admin", "home"}), "home")
+	pages, err = listFrontendPages(ctx, c, "", "")
+	require.NoError(t, err)
+	require.Empty(t, pages)
+}
+
+func replaceInFile(path, old, new string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(strings.Replace(string(data), old, new, 1)), 0o600)
+}
+
+func TestNewFrontendPage_DefaultReplicas(t *testing.T) {
+	require.NoError(t, fpCreateCmd.ParseFlags([]string{"--image", "nginx:alpine"}))
+	require.Equal(t, 1, newFrontendPage("home", "hello").Spec.Replicas, "the scale flag must not change the create default")
+
+	require.NoError(t, fpScaleCmd.ParseFlags([]string{"--replicas", "3"}))
+	require.Equal(t, 3, fpScaleReplicas)
+	require.Equal(t, 1, newFrontendPage("home", "hello").Spec.Replicas)
+}
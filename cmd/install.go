@@ -0,0 +1,325 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	frontendv1alpha2 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/frontendBackup"
+	frontendv1alpha1 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/v1alpha1"
+	"github.com/silhouetteUA/k8s-controller/pkg/config"
+	"github.com/silhouetteUA/k8s-controller/pkg/install"
+	"github.com/silhouetteUA/k8s-controller/pkg/manifest"
+	"github.com/silhouetteUA/k8s-controller/pkg/printers"
+)
+
+var (
+	installOpts     install.Options
+	installDryRun   bool
+	installWait     bool
+	installTimeout  time.Duration
+	uninstallKeep   bool
+	uninstallForce  bool
+	uninstallDryRun bool
+)
+
+var installCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the controller, its CRDs and RBAC in the cluster",
+	Long: `Renders the FrontendPage CRDs, the namespace, ServiceAccount, RBAC, Deployment and Services of the
+controller from manifests built into kctl, applies them with server-side apply and waits until the
+CRDs are established and the Deployment is available. Running it again upgrades the installation.
+
+The controller runs in --namespace (default custom-controller) and gets a Role in each namespace
+of --watch-namespaces, or a ClusterRole for * and selectors. --dry-run prints the manifests
+without contacting the cluster.
+
+Exits with 2 when --timeout expires before the installation is ready.`,
+	Example: `  kctl install --watch-namespaces 'web,api'
+  kctl install --dry-run -o yaml > kctl.yaml
+  kctl install --image ghcr.io/silhouetteua/k8s-controller:v1.0.0-abc1234 --replicas 2`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		installOpts.Namespace = installNamespace(cmd)
+		if installOpts.Image == "" {
+			installOpts.Image = defaultInstallImage()
+		}
+		if installDryRun {
+			objs, err := install.Render(installOpts)
+			if err != nil {
+				log.Error().Err(err).Msg("Failed to render manifests")
+				os.Exit(1)
+			}
+			printOutput(cmd, manifestsOutput(objs))
+			return
+		}
+		runWithWatchClient("install", func(ctx context.Context, c client.WithWatch) error {
+			return runInstall(ctx, c, cmd.OutOrStdout(), installOpts)
+		})
+	},
+}
+
+var uninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the controller, its RBAC and CRDs from the cluster",
+	Long: `Deletes what kctl install created, found by the labels of the installation named --name: first
+the Deployment, so the controller stops, then its Services, RBAC and ServiceAccount, then the
+CRDs and last the namespace, when kctl install created it.
+
+Deleting the CRDs deletes every FrontendPage and FrontendPageBackup with them, and the children
+they own. Uninstall refuses while any exist unless --force is given; --keep-crds leaves the CRDs
+and the custom resources in place.`,
+	Example: `  kctl uninstall --keep-crds
+  kctl uninstall --dry-run`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runWithWatchClient("uninstall", func(ctx context.Context, c client.WithWatch) error {
+			return runUninstall(ctx, c, cmd.OutOrStdout(), installOpts.Name)
+		})
+	},
+}
+
+// installNamespace is -n when given; the context's namespace is not used, so that kctl
+// install does not land in default by accident.
+func installNamespace(cmd *cobra.Command) string {
+	if cmd.Flags().Changed("namespace") {
+		return namespace
+	}
+	return install.DefaultNamespace
+}
+
+// defaultInstallImage is the image CI publishes for this build, tagged VERSION-COMMIT.
+func defaultInstallImage() string {
+	return install.DefaultImageRepository + ":" + Version + "-" + Commit
+}
+
+func runInstall(ctx context.Context, c client.WithWatch, out io.Writer, opts install.Options) error {
+	if Commit == "placeholder" && opts.Image == defaultInstallImage() {
+		return fmt.Errorf("this kctl is a development build with no published image, use --image")
+	}
+	objs, err := install.Render(opts)
+	if err != nil {
+		return err
+	}
+	if objs, err = skipForeignNamespace(ctx, c, objs, opts.Name); err != nil {
+		return err
+	}
+	applier := &manifest.Applier{Client: c, Scheme: c.Scheme(), Namespace: opts.Namespace}
+	if err := applier.Prepare(objs); err != nil {
+		return err
+	}
+	results, err := applier.Apply(ctx, objs)
+	for _, r := range results {
+		fmt.Fprintln(out, r)
+	}
+	if err != nil || !installWait {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, installTimeout)
+	defer cancel()
+	for _, obj := range objs {
+		var cond waitCondition
+		switch {
+		case obj.GetKind() == "CustomResourceDefinition":
+			cond = waitCondition{condType: "Established", status: "True"}
+		case obj.GetKind() == "Deployment" && opts.Replicas > 0:
+			cond = waitCondition{condType: "Available", status: "True"}
+		default:
+			continue
+		}
+		if err := waitForObject(ctx, c, out, obj, cond); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skipForeignNamespace drops the Namespace from objs when it exists but was not created by
+// this installation, so uninstall never deletes a namespace it found in place.
+func skipForeignNamespace(ctx context.Context, c client.Client, objs []*unstructured.Unstructured, name string) ([]*unstructured.Unstructured, error) {
+	kept := objs[:0:0]
+	for _, obj := range objs {
+		if obj.GetKind() != "Namespace" {
+			kept = append(kept, obj)
+			continue
+		}
+		live := &unstructured.Unstructured{}
+		live.SetGroupVersionKind(obj.GroupVersionKind())
+		err := c.Get(ctx, client.ObjectKeyFromObject(obj), live)
+		switch {
+		case apierrors.IsNotFound(err):
+			kept = append(kept, obj)
+		case err != nil:
+			return nil, err
+		case labels.SelectorFromSet(install.Labels(name)).Matches(labels.Set(live.GetLabels())):
+			kept = append(kept, obj)
+		}
+	}
+	return kept, nil
+}
+
+// uninstallKinds are the kinds kctl install creates besides the CRDs, found by label.
+var uninstallKinds = []schema.GroupVersionKind{
+	{Group: "apps", Version: "v1", Kind: "Deployment"},
+	{Version: "v1", Kind: "Service"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "RoleBinding"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRoleBinding"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "Role"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"},
+	{Version: "v1", Kind: "ServiceAccount"},
+	{Version: "v1", Kind: "Namespace"},
+}
+
+func runUninstall(ctx context.Context, c client.WithWatch, out io.Writer, name string) error {
+	selector := labels.SelectorFromSet(install.Labels(name))
+	var objs []*unstructured.Unstructured
+	for _, gvk := range uninstallKinds {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := c.List(ctx, list, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return err
+		}
+		for i := range list.Items {
+			objs = append(objs, &list.Items[i])
+		}
+	}
+	if !uninstallKeep {
+		crds, err := installedCRDs(ctx, c)
+		if err != nil {
+			return err
+		}
+		objs = append(objs, crds...)
+	}
+	if len(objs) == 0 {
+		fmt.Fprintf(out, "No installation named %q found\n", name)
+		return nil
+	}
+	objs = install.DeleteOrder(objs)
+
+	ctx, cancel := context.WithTimeout(ctx, installTimeout)
+	defer cancel()
+	for _, obj := range objs {
+		gvk := obj.GroupVersionKind()
+		ref := resourceName(gvk.Kind, gvk.Group, obj.GetName())
+		if uninstallDryRun {
+			fmt.Fprintf(out, "%s deleted (dry run)\n", ref)
+			continue
+		}
+		// Foreground deletion removes a Deployment's pods before the Deployment itself, so
+		// waiting for it means the controller has stopped.
+		err := c.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationForeground))
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("deleting %s: %w", ref, err)
+		}
+		if !installWait {
+			fmt.Fprintf(out, "%s deleted\n", ref)
+			continue
+		}
+		if err := waitForObject(ctx, c, out, obj, waitCondition{delete: true}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// installedCRDs returns the FrontendPage CRDs present in the cluster. Without --force it
+// fails while FrontendPages or FrontendPageBackups exist, as deleting the CRDs deletes them.
+func installedCRDs(ctx context.Context, c client.Client) ([]*unstructured.Unstructured, error) {
+	crds, err := install.CRDs()
+	if err != nil {
+		return nil, err
+	}
+	var present []*unstructured.Unstructured
+	for _, crd := range crds {
+		err := c.Get(ctx, client.ObjectKeyFromObject(crd), crd.DeepCopy())
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		present = append(present, crd)
+	}
+	if len(present) == 0 || uninstallForce {
+		return present, nil
+	}
+	var inUse []string
+	for _, r := range []struct {
+		list client.ObjectList
+		kind string
+	}{
+		{&frontendv1alpha1.FrontendPageList{}, "FrontendPage(s)"},
+		{&frontendv1alpha2.FrontendPageBackupList{}, "FrontendPageBackup(s)"},
+	} {
+		err := c.List(ctx, r.list)
+		if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if n := meta.LenList(r.list); n > 0 {
+			inUse = append(inUse, fmt.Sprintf("%d %s", n, r.kind))
+		}
+	}
+	if len(inUse) > 0 {
+		return nil, fmt.Errorf("%s still exist and would be deleted with the CRDs; delete them first, pass --keep-crds or --force", strings.Join(inUse, " and "))
+	}
+	return present, nil
+}
+
+// manifestsOutput describes rendered manifests for the printers; -o yaml and json print
+// them as a List that kubectl apply and kctl apply accept.
+func manifestsOutput(objs []*unstructured.Unstructured) printers.Output {
+	items := make([]any, 0, len(objs))
+	out := printers.Output{
+		Columns: []printers.Column{{Header: "KIND"}, {Header: "NAME"}, {Header: "NAMESPACE"}},
+	}
+	for _, obj := range objs {
+		items = append(items, obj.Object)
+		out.Rows = append(out.Rows, []string{obj.GetKind(), obj.GetName(), orNone(obj.GetNamespace())})
+		out.Names = append(out.Names, manifest.Ref(obj))
+	}
+	out.Object = map[string]any{"apiVersion": "v1", "kind": "List", "items": items}
+	return out
+}
+
+func init() {
+	defaults := config.Default()
+	rootCmd.AddCommand(installCmd, uninstallCmd)
+	addOutputFlags(installCmd)
+	flags := installCmd.Flags()
+	flags.StringVar(&installOpts.Image, "image", "", "Controller image (default: the image released with this kctl)")
+	flags.StringVar(&installOpts.ImagePullPolicy, "image-pull-policy", "IfNotPresent", "Image pull policy: Always, IfNotPresent or Never")
+	flags.IntVar(&installOpts.Replicas, "replicas", 1, "Number of controller replicas; they elect a leader")
+	flags.StringVar(&installOpts.WatchNamespaces, "watch-namespaces", defaults.Server.WatchNamespaces, "Namespaces the controller watches: a comma-separated list, a label selector on namespaces, or * for all")
+	flags.IntVar(&installOpts.Port, "port", defaults.Server.Port, "Port of the controller's REST API and probes")
+	flags.IntVar(&installOpts.MetricsPort, "metrics-port", defaults.Server.MetricsPort, "Port of the controller's metrics")
+	flags.StringVar(&installOpts.EventWebhookURL, "event-webhook-url", "", "Also send change events to this URL with the webhook event sink")
+	flags.BoolVar(&installDryRun, "dry-run", false, "Print the manifests instead of applying them")
+	for _, cmd := range []*cobra.Command{installCmd, uninstallCmd} {
+		cmd.Flags().StringVar(&installOpts.Name, "name", install.DefaultName, "Name of the installation's Deployment, Services, ServiceAccount and RBAC objects")
+		cmd.Flags().BoolVar(&installWait, "wait", true, "Wait until the installation is ready, or each object is gone on uninstall")
+		cmd.Flags().DurationVar(&installTimeout, "timeout", 5*time.Minute, "How long to wait")
+	}
+	uninstallCmd.Flags().BoolVar(&uninstallKeep, "keep-crds", false, "Leave the CRDs, and with them every FrontendPage and FrontendPageBackup")
+	uninstallCmd.Flags().BoolVar(&uninstallForce, "force", false, "Delete the CRDs even while FrontendPages or FrontendPageBackups exist")
+	uninstallCmd.Flags().BoolVar(&uninstallDryRun, "dry-run", false, "Print what would be deleted without deleting it")
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	frontendv1alpha1 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/v1alpha1"
+	"github.com/silhouetteUA/k8s-controller/pkg/install"
+)
+
+func TestUninstall(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, apiextensionsv1.AddToScheme(scheme))
+	require.NoError(t, frontendv1alpha1.AddToScheme(scheme))
+	objs, err := install.Render(install.Options{
+		Name: "kctl", Namespace: "kctl-system", Image: "kctl:dev", ImagePullPolicy: "Never",
+		Replicas: 1, WatchNamespaces: "web", Port: 8080, MetricsPort: 8081,
+	})
+	require.NoError(t, err)
+	page := &frontendv1alpha1.FrontendPage{ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "web"}}
+	builder := fake.NewClientBuilder().WithScheme(scheme).WithObjects(page)
+	for _, obj := range objs {
+		builder.WithObjects(obj)
+	}
+	// Another installation's objects are left alone.
+	other := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "kctl-system", Labels: install.Labels("other")}}
+	builder.WithObjects(other)
+	c := builder.Build()
+	ctx := context.Background()
+	var out bytes.Buffer
+	installWait = false
+
+	err = runUninstall(ctx, c, &out, "kctl")
+	require.ErrorContains(t, err, "1 FrontendPage(s) still exist")
+
+	uninstallDryRun, uninstallKeep = true, true
+	require.NoError(t, runUninstall(ctx, c, &out, "kctl"))
+	uninstallDryRun, uninstallKeep = false, false
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, len(objs)-2)
+	require.Equal(t, "deployment.apps/kctl deleted (dry run)", lines[0])
+	require.Equal(t, "namespace/kctl-system deleted (dry run)", lines[len(lines)-1])
+
+	// Each object is gone before the next is deleted.
+	uninstallForce, installWait = true, true
+	defer func() { uninstallForce = false }()
+	out.Reset()
+	require.NoError(t, runUninstall(ctx, c, &out, "kctl"))
+	lines = strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, len(objs))
+	require.Equal(t, "customresourcedefinition.apiextensions.k8s.io/frontendpagebackups.frontendpage.silhouetteua.io deleted", lines[len(lines)-3])
+	for _, obj := range objs {
+		live := &unstructured.Unstructured{}
+		live.SetGroupVersionKind(obj.GroupVersionKind())
+		require.Error(t, c.Get(ctx, client.ObjectKeyFromObject(obj), live), obj.GetKind())
+	}
+
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(other), other))
+
+	out.Reset()
+	require.NoError(t, runUninstall(ctx, c, &out, "kctl"))
+	require.Equal(t, "No installation named \"kctl\" found\n", out.String())
+
+	// Install never takes over a namespace it did not create, so uninstall cannot delete it.
+	require.NoError(t, c.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kctl-system"}}))
+	kept, err := skipForeignNamespace(ctx, c, objs, "kctl")
+	require.NoError(t, err)
+	require.Len(t, kept, len(objs)-1)
+	require.NotContains(t, kept, objs[2])
+}
@@ -2,49 +2,192 @@ package cmd
 
 import (
 	"fmt"
-	"github.com/rs/zerolog/log"
+	"io"
 	"os"
+	"sort"
+	"strings"
 
+	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/silhouetteUA/k8s-controller/pkg/printers"
 )
 
+// kubeContextInfo is the output of kctl context show, and an item of kctl context list.
+type kubeContextInfo struct {
+	Name      string `json:"name"`
+	Current   bool   `json:"current"`
+	Cluster   string `json:"cluster"`
+	Namespace string `json:"namespace"`
+	User      string `json:"user"`
+	Server    string `json:"server"`
+}
+
+// kubeContextList is the output of kctl context list.
+type kubeContextList struct {
+	Contexts []kubeContextInfo `json:"contexts"`
+}
+
 var contextCmd = &cobra.Command{
 	Use:   "context",
-	Short: "Show current Kubernetes context and cluster info",
+	Short: "Show, list and switch Kubernetes contexts",
+	Long: `Works on the merged kubeconfig: --kubeconfig, or every file in $KUBECONFIG (a colon-separated
+list), or ~/.kube/config. Without a subcommand it runs show.`,
+	Run: runContextShow,
+}
+
+var contextShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the context kctl uses and its cluster",
+	Long:  "Shows the context selected with --context, or the current context of the kubeconfig.",
+	Args:  cobra.NoArgs,
+	Run:   runContextShow,
+}
+
+var contextListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the contexts of the kubeconfig",
+	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		kubeconfig := os.Getenv("KUBECONFIG")
-		if kubeconfig == "" {
-			home, err := os.UserHomeDir()
-			if err != nil {
-				fmt.Println("Error getting home directory:", err)
-				log.Error().Err(err).Msg("Error getting home directory")
-				return
+		contexts, err := listContexts(clientConfig(kubeconfig))
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to load kubeconfig")
+			os.Exit(1)
+		}
+		if len(contexts.Contexts) == 0 && printOptions.IsTable() {
+			fmt.Fprintln(cmd.ErrOrStderr(), "No contexts found")
+			return
+		}
+		out := printers.Output{
+			Object: contexts,
+			Columns: []printers.Column{
+				{Header: "CURRENT"},
+				{Header: "NAME"},
+				{Header: "CLUSTER"},
+				{Header: "NAMESPACE"},
+				{Header: "USER", Wide: true},
+				{Header: "SERVER", Wide: true},
+			},
+		}
+		for _, c := range contexts.Contexts {
+			current := ""
+			if c.Current {
+				current = "*"
 			}
-			kubeconfig = home + "/.kube/config"
+			out.Rows = append(out.Rows, []string{current, c.Name, c.Cluster, orNone(c.Namespace), c.User, c.Server})
+			out.Names = append(out.Names, "context/"+c.Name)
 		}
+		printOutput(cmd, out)
+	},
+}
 
-		config, err := clientcmd.LoadFromFile(kubeconfig)
-		if err != nil {
-			fmt.Println("Failed to load kubeconfig:", err)
-			return
+var contextUseCmd = &cobra.Command{
+	Use:   "use NAME",
+	Short: "Make NAME the current context",
+	Long: `Sets current-context in the kubeconfig, in the same file kubectl config use-context would write
+to. Later commands use NAME unless --context is given.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := useContext(clientConfig(kubeconfig), cmd.OutOrStdout(), args[0]); err != nil {
+			log.Error().Err(err).Msg("Failed to switch context")
+			os.Exit(1)
 		}
+	},
+}
 
-		currentContext := config.CurrentContext
-		fmt.Println("Current Context:", currentContext)
+func runContextShow(cmd *cobra.Command, args []string) {
+	info, err := showContext(clientConfig(kubeconfig))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load kubeconfig")
+		os.Exit(1)
+	}
+	printOutput(cmd, printers.Output{
+		Object: info,
+		Columns: []printers.Column{
+			{Header: "CURRENT CONTEXT"},
+			{Header: "CLUSTER"},
+			{Header: "NAMESPACE"},
+			{Header: "USER", Wide: true},
+			{Header: "SERVER", Wide: true},
+		},
+		Rows:  [][]string{{info.Name, info.Cluster, orNone(info.Namespace), info.User, info.Server}},
+		Names: []string{fmt.Sprintf("context/%s", info.Name)},
+	})
+}
 
-		ctxConfig := config.Contexts[currentContext]
-		if ctxConfig == nil {
-			fmt.Println("Context config not found")
-			log.Error().Msg("Context config not found")
-			return
-		}
+// selectedContext is --context, or the current context of raw.
+func selectedContext(raw clientcmdapi.Config) string {
+	if kubeContext != "" {
+		return kubeContext
+	}
+	return raw.CurrentContext
+}
 
-		fmt.Println("Cluster:", ctxConfig.Cluster)
-		fmt.Println("Namespace:", ctxConfig.Namespace)
-	},
+func contextInfo(raw clientcmdapi.Config, name string) kubeContextInfo {
+	ctx := raw.Contexts[name]
+	info := kubeContextInfo{
+		Name:      name,
+		Current:   name == selectedContext(raw),
+		Cluster:   ctx.Cluster,
+		Namespace: ctx.Namespace,
+		User:      ctx.AuthInfo,
+	}
+	if cluster := raw.Clusters[ctx.Cluster]; cluster != nil {
+		info.Server = cluster.Server
+	}
+	return info
+}
+
+func showContext(cc clientcmd.ClientConfig) (kubeContextInfo, error) {
+	raw, err := cc.RawConfig()
+	if err != nil {
+		return kubeContextInfo{}, err
+	}
+	name := selectedContext(raw)
+	if name == "" {
+		return kubeContextInfo{}, fmt.Errorf("no current context is set, choose one with kctl context use")
+	}
+	if raw.Contexts[name] == nil {
+		return kubeContextInfo{}, fmt.Errorf("context %q not found in %s", name, strings.Join(cc.ConfigAccess().GetLoadingPrecedence(), ":"))
+	}
+	return contextInfo(raw, name), nil
+}
+
+func listContexts(cc clientcmd.ClientConfig) (kubeContextList, error) {
+	raw, err := cc.RawConfig()
+	if err != nil {
+		return kubeContextList{}, err
+	}
+	list := kubeContextList{Contexts: []kubeContextInfo{}}
+	for name := range raw.Contexts {
+		list.Contexts = append(list.Contexts, contextInfo(raw, name))
+	}
+	sort.Slice(list.Contexts, func(i, j int) bool { return list.Contexts[i].Name < list.Contexts[j].Name })
+	return list, nil
+}
+
+func useContext(cc clientcmd.ClientConfig, out io.Writer, name string) error {
+	raw, err := cc.RawConfig()
+	if err != nil {
+		return err
+	}
+	if raw.Contexts[name] == nil {
+		return fmt.Errorf("context %q not found in %s", name, strings.Join(cc.ConfigAccess().GetLoadingPrecedence(), ":"))
+	}
+	raw.CurrentContext = name
+	if err := clientcmd.ModifyConfig(cc.ConfigAccess(), raw, true); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "Switched to context %q.\n", name)
+	return err
 }
 
 func init() {
 	rootCmd.AddCommand(contextCmd)
+	contextCmd.AddCommand(contextShowCmd, contextListCmd, contextUseCmd)
+	addOutputFlags(contextCmd)
+	addOutputFlags(contextShowCmd)
+	addOutputFlags(contextListCmd)
 }
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const kubeconfigDev = `apiVersion: v1
+kind: Config
+current-context: dev
+clusters:
+- name: dev
+  cluster:
+    server: https://dev.example.com
+contexts:
+- name: dev
+  context:
+    cluster: dev
+    user: alice
+    namespace: web
+users:
+- name: alice
+  user:
+    token: dev-token
+`
+
+const kubeconfigProd = `apiVersion: v1
+kind: Config
+clusters:
+- name: prod
+  cluster:
+    server: https://prod.example.com
+contexts:
+- name: prod
+  context:
+    cluster: prod
+    user: bob
+users:
+- name: bob
+  user:
+    token: prod-token
+`
+
+func TestKubeContexts(t *testing.T) {
+	dir := t.TempDir()
+	dev, prod := filepath.Join(dir, "dev.yaml"), filepath.Join(dir, "prod.yaml")
+	require.NoError(t, os.WriteFile(dev, []byte(kubeconfigDev), 0o600))
+	require.NoError(t, os.WriteFile(prod, []byte(kubeconfigProd), 0o600))
+	t.Setenv(clientcmd.RecommendedConfigPathEnvVar, dev+string(filepath.ListSeparator)+prod)
+	kubeContext = ""
+	defer func() { kubeContext = "" }()
+
+	// Both files are merged.
+	list, err := listContexts(clientConfig(""))
+	require.NoError(t, err)
+	require.Len(t, list.Contexts, 2)
+	require.Equal(t, kubeContextInfo{Name: "dev", Current: true, Cluster: "dev", Namespace: "web", User: "alice", Server: "https://dev.example.com"}, list.Contexts[0])
+	require.Equal(t, "prod", list.Contexts[1].Name)
+	require.False(t, list.Contexts[1].Current)
+	require.Equal(t, "web", contextNamespace(""))
+
+	cfg, err := getRestConfig("")
+	require.NoError(t, err)
+	require.Equal(t, "https://dev.example.com", cfg.Host)
+
+	// --context overrides the current context for every client.
+	kubeContext = "prod"
+	cfg, err = getRestConfig("")
+	require.NoError(t, err)
+	require.Equal(t, "https://prod.example.com", cfg.Host)
+	require.Equal(t, "default", contextNamespace(""), "prod has no namespace")
+	info, err := showContext(clientConfig(""))
+	require.NoError(t, err)
+	require.Equal(t, "prod", info.Name)
+	kubeContext = "missing"
+	_, err = showContext(clientConfig(""))
+	require.ErrorContains(t, err, `context "missing" not found`)
+	_, err = getRestConfig("")
+	require.Error(t, err)
+	kubeContext = ""
+
+	var out bytes.Buffer
+	require.NoError(t, useContext(clientConfig(""), &out, "prod"))
+	require.Equal(t, "Switched to context \"prod\".\n", out.String())
+	info, err = showContext(clientConfig(""))
+	require.NoError(t, err)
+	require.Equal(t, "prod", info.Name)
+	require.Equal(t, "https://prod.example.com", info.Server)
+	written, err := clientcmd.LoadFromFile(dev)
+	require.NoError(t, err)
+	require.Equal(t, "prod", written.CurrentContext, "current-context stays in the file that set it")
+	require.Error(t, useContext(clientConfig(""), &out, "missing"))
+
+	// An explicit --kubeconfig is used alone.
+	list, err = listContexts(clientConfig(prod))
+	require.NoError(t, err)
+	require.Len(t, list.Contexts, 1)
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// kubeconfig, kubeContext and namespace are the global --kubeconfig, --context and
+// --namespace flags. An empty namespace is replaced by the context's before a command runs.
+var kubeconfig string
+var kubeContext string
+var namespace string
+
+// clientConfig loads the kubeconfig the way kubectl does: kubeconfigPath if set, otherwise
+// the files listed in $KUBECONFIG merged in order, otherwise ~/.kube/config, falling back to
+// the in-cluster config. --context overrides the current context.
+func clientConfig(kubeconfigPath string) clientcmd.ClientConfig {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = kubeconfigPath
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: kubeContext}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides)
+}
+
+// getRestConfig is the REST config of the selected context.
+func getRestConfig(kubeconfigPath string) (*rest.Config, error) {
+	return clientConfig(kubeconfigPath).ClientConfig()
+}
+
+// contextNamespace is the namespace of the selected context, or default when it has none
+// or the kubeconfig cannot be read.
+func contextNamespace(kubeconfigPath string) string {
+	ns, _, err := clientConfig(kubeconfigPath).Namespace()
+	if err != nil || ns == "" {
+		return "default"
+	}
+	return ns
+}
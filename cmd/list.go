@@ -4,16 +4,22 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/tools/clientcmd"
-)
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
-var kubeconfig string
-var namespace string
+	frontendv1alpha2 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/frontendBackup"
+	frontendv1alpha1 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/v1alpha1"
+	"github.com/silhouetteUA/k8s-controller/pkg/printers"
+)
 
 var listCmd = &cobra.Command{
 	Use:   "list",
@@ -29,23 +35,87 @@ var listCmd = &cobra.Command{
 			log.Error().Err(err).Msg("Failed to list deployments")
 			os.Exit(1)
 		}
-		fmt.Printf("Found %d deployments in "+namespace+" namespace:\n", len(deployments.Items))
-		for _, d := range deployments.Items {
-			fmt.Println("-", d.Name)
+		if len(deployments.Items) == 0 && printOptions.IsTable() {
+			printNoResources(cmd, "deployments", namespace)
+			return
 		}
+		printOutput(cmd, deploymentsOutput(deployments))
 	},
 }
 
+// deploymentsOutput describes deployments for the printers.
+func deploymentsOutput(deployments *appsv1.DeploymentList) printers.Output {
+	deployments.TypeMeta = listTypeMeta(appsv1.SchemeGroupVersion.String(), "Deployment")
+	out := printers.Output{
+		Object: deployments,
+		Columns: []printers.Column{
+			{Header: "NAME"},
+			{Header: "READY"},
+			{Header: "IMAGE"},
+			{Header: "AGE"},
+			{Header: "UP-TO-DATE", Wide: true},
+			{Header: "AVAILABLE", Wide: true},
+			{Header: "CONTAINERS", Wide: true},
+			{Header: "SELECTOR", Wide: true},
+		},
+	}
+	for i := range deployments.Items {
+		d := &deployments.Items[i]
+		d.APIVersion, d.Kind = appsv1.SchemeGroupVersion.String(), "Deployment"
+		d.ManagedFields = nil
+		desired := int32(1)
+		if d.Spec.Replicas != nil {
+			desired = *d.Spec.Replicas
+		}
+		var containers, images []string
+		for _, c := range d.Spec.Template.Spec.Containers {
+			containers = append(containers, c.Name)
+			images = append(images, c.Image)
+		}
+		selector := "<none>"
+		if d.Spec.Selector != nil {
+			selector = metav1.FormatLabelSelector(d.Spec.Selector)
+		}
+		out.Rows = append(out.Rows, []string{
+			d.Name,
+			fmt.Sprintf("%d/%d", d.Status.ReadyReplicas, desired),
+			strings.Join(images, ","),
+			age(d.CreationTimestamp),
+			fmt.Sprint(d.Status.UpdatedReplicas),
+			fmt.Sprint(d.Status.AvailableReplicas),
+			strings.Join(containers, ","),
+			selector,
+		})
+		out.Names = append(out.Names, resourceName("Deployment", appsv1.GroupName, d.Name))
+	}
+	return out
+}
+
 func getKubeClient(kubeconfigPath string) (*kubernetes.Clientset, error) {
-	config, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+	config, err := getRestConfig(kubeconfigPath)
 	if err != nil {
 		return nil, err
 	}
 	return kubernetes.NewForConfig(config)
 }
 
+// getRuntimeClient returns a controller-runtime client that also knows the FrontendPage types
+// and CustomResourceDefinitions.
+func getRuntimeClient(kubeconfigPath string) (client.WithWatch, error) {
+	config, err := getRestConfig(kubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
+	scheme := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{clientgoscheme.AddToScheme, apiextensionsv1.AddToScheme, frontendv1alpha1.AddToScheme, frontendv1alpha2.AddToScheme} {
+		if err := add(scheme); err != nil {
+			return nil, err
+		}
+	}
+	return client.NewWithWatch(config, client.Options{Scheme: scheme})
+}
+
 func init() {
 	rootCmd.AddCommand(listCmd)
-	listCmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Path to the kubeconfig file")
-	listCmd.Flags().StringVar(&namespace, "namespace", "default", "Define the namespace, otherwise the default namespace is used")
+	addOutputFlags(listCmd)
 }
@@ -1,6 +1,13 @@
 package cmd
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
 
 func TestGetKubeClient_InvalidPath(t *testing.T) {
 	_, err := getKubeClient("/invalid/path")
@@ -8,3 +15,22 @@ func TestGetKubeClient_InvalidPath(t *testing.T) {
 		t.Error("expected error for invalid kubeconfig path")
 	}
 }
+
+func TestDeploymentsOutput(t *testing.T) {
+	list := &appsv1.DeploymentList{Items: []appsv1.Deployment{{
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: int32Ptr(3),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{
+				{Name: "web", Image: "nginx:1.27"}, {Name: "sidecar", Image: "busybox:1.36"},
+			}}},
+		},
+		Status: appsv1.DeploymentStatus{ReadyReplicas: 2, UpdatedReplicas: 3, AvailableReplicas: 2},
+	}}}
+	out := deploymentsOutput(list)
+	require.Equal(t, []string{"web", "2/3", "nginx:1.27,busybox:1.36", "<unknown>", "3", "2", "web,sidecar", "app=web"}, out.Rows[0])
+	require.Equal(t, []string{"deployment.apps/web"}, out.Names)
+	require.Equal(t, "DeploymentList", list.Kind)
+	require.Equal(t, "Deployment", list.Items[0].Kind, "items carry their kind for -o json and yaml")
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+
+	"github.com/silhouetteUA/k8s-controller/pkg/printers"
+)
+
+// printOptions holds -o and --no-headers of the command being run.
+var printOptions printers.Options
+
+// addOutputFlags registers the output flags on cmd and rejects an invalid format before
+// the command does any work.
+func addOutputFlags(cmd *cobra.Command) {
+	printers.AddFlags(cmd.Flags(), &printOptions)
+	cmd.PreRun = func(*cobra.Command, []string) {
+		if err := printOptions.Validate(); err != nil {
+			log.Error().Err(err).Msg("Invalid --output")
+			os.Exit(1)
+		}
+	}
+}
+
+// printOutput prints out in the format chosen with -o, exiting on failure.
+func printOutput(cmd *cobra.Command, out printers.Output) {
+	if err := printOptions.Print(cmd.OutOrStdout(), out); err != nil {
+		log.Error().Err(err).Msg("Failed to print output")
+		os.Exit(1)
+	}
+}
+
+// printNoResources tells the user, on stderr, that a table would be empty.
+func printNoResources(cmd *cobra.Command, kind, namespace string) {
+	if namespace == "" {
+		fmt.Fprintf(cmd.ErrOrStderr(), "No %s found\n", kind)
+		return
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "No %s found in namespace %q\n", kind, namespace)
+}
+
+// listTypeMeta is the TypeMeta of a list of kind in group/version, e.g. apps/v1.
+func listTypeMeta(groupVersion, kind string) metav1.TypeMeta {
+	return metav1.TypeMeta{APIVersion: groupVersion, Kind: kind + "List"}
+}
+
+// resourceName is the -o name form of an object, e.g. deployment.apps/web.
+func resourceName(kind, group, name string) string {
+	resource := strings.ToLower(kind)
+	if group != "" {
+		resource += "." + group
+	}
+	return resource + "/" + name
+}
+
+func age(t metav1.Time) string {
+	if t.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(time.Since(t.Time))
+}
+
+// orNone renders an empty cell as <none>.
+func orNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
@@ -4,20 +4,35 @@ import (
 	"fmt"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"github.com/silhouetteUA/k8s-controller/pkg/config"
 	cobalias "github.com/spf13/cobra"
 	"os"
 	"strings"
 )
 
 var logLevel string
+var configFile string
+
+// configLoader and effectiveConfig are set before any command runs.
+var configLoader *config.Loader
+var effectiveConfig *config.Config
 
 var rootCmd = &cobalias.Command{
 	Use:   "kctl",
 	Short: "kctl is a custom Kubernetes controller CLI",
 	Long:  `"kctl" is a tool to test and run components of your custom Kubernetes controller`,
 	PersistentPreRun: func(cmd *cobalias.Command, args []string) {
-		level := parseLogLevel(logLevel)
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			log.Error().Err(err).Msg("Invalid configuration")
+			os.Exit(1)
+		}
+		effectiveConfig = cfg
+		level := parseLogLevel(cfg.LogLevel)
 		configureLogger(level)
+		if namespace == "" {
+			namespace = contextNamespace(kubeconfig)
+		}
 	},
 	Run: func(cmd *cobalias.Command, args []string) {
 		if len(args) == 0 {
@@ -33,6 +48,20 @@ var rootCmd = &cobalias.Command{
 	},
 }
 
+// loadConfig layers the config file and KCTL_* variables under the flags of cmd and
+// copies the result into the flag variables.
+func loadConfig(cmd *cobalias.Command) (*config.Config, error) {
+	configLoader = &config.Loader{Path: configFile, Flags: cmd.Flags()}
+	cfg, err := configLoader.Load()
+	if err != nil {
+		return nil, err
+	}
+	if err := config.ApplyToFlags(cfg, cmd.Flags()); err != nil {
+		return nil, err
+	}
+	return cfg, cfg.Validate()
+}
+
 func configureLogger(level zerolog.Level) {
 	// Set global time format and log level
 	zerolog.TimeFieldFormat = "2006-01-02 15:04:05.000"
@@ -101,5 +130,9 @@ func Execute() {
 }
 
 func init() {
-	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "Set log level: trace, debug, info, warn, error")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", config.Default().LogLevel, "Set log level: trace, debug, info, warn, error")
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "Path to a YAML config file (default: $KCTL_CONFIG, then "+config.DefaultPath()+" if it exists)")
+	rootCmd.PersistentFlags().StringVar(&kubeconfig, "kubeconfig", "", "Path to the kubeconfig file (default: $KUBECONFIG, a colon-separated list, then ~/.kube/config)")
+	rootCmd.PersistentFlags().StringVar(&kubeContext, "context", "", "Kubeconfig context to use (default: the current context)")
+	rootCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", "", "Kubernetes namespace (default: the context's namespace, or default)")
 }
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"github.com/buaazp/fasthttprouter"
 	"github.com/go-logr/zerologr"
@@ -9,29 +11,72 @@ import (
 	"github.com/silhouetteUA/k8s-controller/pkg/api"
 	frontendv1alpha2 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/frontendBackup"
 	frontendv1alpha1 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/v1alpha1"
+	"github.com/silhouetteUA/k8s-controller/pkg/auth"
+	"github.com/silhouetteUA/k8s-controller/pkg/certwatch"
+	"github.com/silhouetteUA/k8s-controller/pkg/config"
 	"github.com/silhouetteUA/k8s-controller/pkg/controller"
+	"github.com/silhouetteUA/k8s-controller/pkg/events"
+	"github.com/silhouetteUA/k8s-controller/pkg/health"
 	"github.com/silhouetteUA/k8s-controller/pkg/informer"
+	"github.com/silhouetteUA/k8s-controller/pkg/policy"
+	"github.com/silhouetteUA/k8s-controller/pkg/scope"
 	"github.com/spf13/cobra"
 	"github.com/valyala/fasthttp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/clientcmd"
+	"maps"
+	"net"
 	"os"
+	"os/signal"
 	ctrlruntime "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"strings"
+	"syscall"
+	"time"
 )
 
 var serverPort int
-var serverKubeconfig string
+var watchNS string
 var serverInCluster bool
 var enableLeaderElection bool
 var metricsPort int
+var probePort int
+var enableAuth bool
+var authCacheTTL time.Duration
+var tlsCertFile string
+var tlsKeyFile string
+var clientCAFile string
+var authzMode string
+var serverReadTimeout time.Duration
+var serverWriteTimeout time.Duration
+var serverIdleTimeout time.Duration
+var serverMaxBodySize int
+var shutdownTimeout time.Duration
+var resourcesConfig string
+var eventSinks []string
+var eventWebhookURL string
+var eventJournalFile string
+var eventNATSURL string
+var eventNATSSubject string
+var eventQueueSize int
+var eventMaxAttempts int
+var policyConfigMap string
+var orphanScanInterval time.Duration
+var orphanGC bool
+var watchNamespaces string
+var leaderElectionNamespace string
 
 var serverCmd = &cobra.Command{
 	Use:   "server",
@@ -39,13 +84,75 @@ var serverCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		level := parseLogLevel(logLevel)
 		configureLogger(level)
-		clientset, err := getServerKubeClient(serverKubeconfig, serverInCluster)
+		clientset, err := getServerKubeClient(kubeconfig, serverInCluster)
 		if err != nil {
 			log.Error().Err(err).Msg("Failed to create Kubernetes client")
 			os.Exit(1)
 		}
-		ctx := context.Background()
-		go informer.StartInformerFactory(ctx, clientset, namespace)
+		if cmd.Flags().Changed("watch-ns") && !cmd.Flags().Changed("watch-namespaces") {
+			watchNamespaces = watchNS
+		}
+		watchScope, err := scope.Parse(watchNamespaces)
+		if err != nil {
+			log.Error().Err(err).Msg("Invalid --watch-namespaces")
+			os.Exit(1)
+		}
+		electionNamespace := leaderElectionNamespace
+		if electionNamespace == "" {
+			electionNamespace = watchScope.Default()
+		}
+		// A single signal-aware root context. The informer and manager contexts are
+		// detached from its cancellation so shutdown can run in order: HTTP server
+		// first, then informers, then the manager.
+		rootCtx, stop := newRootContext(cmd.Context())
+		defer stop()
+		mgrCtx, stopManager := context.WithCancel(context.WithoutCancel(rootCtx))
+		defer stopManager()
+		informerCtx, stopInformers := context.WithCancel(mgrCtx)
+		defer stopInformers()
+
+		addr := fmt.Sprintf(":%d", serverPort)
+		ln, err := newHTTPListener(rootCtx, addr)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to listen for FastHTTP server")
+			os.Exit(1)
+		}
+
+		// The event bus outlives the informers so it can drain their last events.
+		eventsCtx, stopEvents := context.WithCancel(mgrCtx)
+		defer stopEvents()
+		eventBus, err := newEventBus()
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to configure event sinks")
+			os.Exit(1)
+		}
+		if err := eventBus.Start(eventsCtx); err != nil {
+			log.Error().Err(err).Msg("Failed to start event bus")
+			os.Exit(1)
+		}
+
+		if err := watchScope.Start(mgrCtx, clientset, informer.DefaultResyncPeriod); err != nil {
+			log.Error().Err(err).Msg("Failed to watch namespaces matching --watch-namespaces")
+			os.Exit(1)
+		}
+		informers := informer.NewInformerManager(clientset, informer.Options{Scope: watchScope, Events: eventBus})
+		if err := informers.Start(informerCtx); err != nil {
+			log.Error().Err(err).Msg("Failed to start informers")
+			os.Exit(1)
+		}
+		go func() {
+			if err := informers.WaitForSync(informerCtx); err != nil {
+				log.Error().Err(err).Msg("Informers failed to sync")
+			}
+		}()
+		var dynamicInformers *informer.DynamicInformerManager
+		if resourcesConfig != "" {
+			dynamicInformers, err = startDynamicInformers(informerCtx, resourcesConfig, eventBus)
+			if err != nil {
+				log.Error().Err(err).Msg("Failed to start dynamic informers")
+				os.Exit(1)
+			}
+		}
 		logf.SetLogger(zap.New(zap.UseDevMode(true)))
 		logf.SetLogger(zerologr.New(&log.Logger))
 		// Start controller-runtime manager and controller
@@ -62,52 +169,163 @@ var serverCmd = &cobra.Command{
 			log.Error().Err(err).Msg("Failed to add FrontendPageBackup scheme")
 			os.Exit(1)
 		}
-		mgr, err := ctrlruntime.NewManager(ctrlruntime.GetConfigOrDie(), manager.Options{
+		restConfig, err := getServerRestConfig(kubeconfig, serverInCluster)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to load Kubernetes config")
+			os.Exit(1)
+		}
+		mgr, err := ctrlruntime.NewManager(restConfig, manager.Options{
 			Scheme:                  scheme, // ADD YOUR OWN SCHEME, NOT A DEFAULT ONE !!!!!!
 			LeaderElection:          enableLeaderElection,
 			LeaderElectionID:        "k8s-controller-leader-election",
-			LeaderElectionNamespace: namespace,
+			LeaderElectionNamespace: electionNamespace,
 			Metrics:                 server.Options{BindAddress: fmt.Sprintf(":%d", metricsPort)},
-			Cache:                   cache.Options{DefaultNamespaces: map[string]cache.Config{namespace: {}}},
+			HealthProbeBindAddress:  fmt.Sprintf(":%d", probePort),
+			Cache:                   managerCacheOptions(watchScope, electionNamespace),
 		})
 		if err != nil {
 			log.Error().Err(err).Msg("Failed to create controller-runtime manager")
 			os.Exit(1)
 		}
-		if err := controller.AddDeploymentController(mgr); err != nil {
+		readiness := []health.Check{
+			health.InformerSynced(func() bool {
+				return informers.HasSynced() && (dynamicInformers == nil || dynamicInformers.HasSynced())
+			}),
+			health.ManagerCacheSynced(mgr.GetCache()),
+			health.LeaderElected(enableLeaderElection, mgr.Elected()),
+			health.APIServerReachable(clientset.Discovery().RESTClient()),
+		}
+		if err := mgr.AddHealthzCheck("ping", healthz.Ping); err != nil {
+			log.Error().Err(err).Msg("Failed to add manager health check")
+			os.Exit(1)
+		}
+		for _, c := range readiness {
+			if err := mgr.AddReadyzCheck(c.Name, health.Checker(c)); err != nil {
+				log.Error().Err(err).Msg("Failed to add manager readiness check")
+				os.Exit(1)
+			}
+		}
+		policyReports := policy.NewReports()
+		policyLoader := &policy.Loader{Client: mgr.GetClient(), Namespace: electionNamespace, Name: policyConfigMap}
+		policyLoader.SetDefault(effectiveConfig.Server.Policy.Default)
+		policyChanged := make(chan event.GenericEvent, 1)
+		if err := controller.AddDeploymentController(mgr, controller.DeploymentControllerOptions{
+			PolicyNamespace: electionNamespace,
+			PolicyConfigMap: policyConfigMap,
+			Reports:         policyReports,
+			Scope:           watchScope,
+			Policy:          policyLoader,
+			PolicyChanged:   policyChanged,
+		}); err != nil {
 			log.Error().Err(err).Msg("Failed to add deployment controller")
 			os.Exit(1)
 		}
-		if err := controller.AddSecretController(mgr); err != nil {
+		if err := controller.AddSecretController(mgr, watchScope); err != nil {
 			log.Error().Err(err).Msg("Failed to add secret controller")
 			os.Exit(1)
 		}
-		if err := controller.AddFrontendController(mgr); err != nil {
+		if err := controller.AddFrontendController(mgr, watchScope); err != nil {
 			log.Error().Err(err).Msg("Failed to add frontend controller")
 			os.Exit(1)
 		}
-		if err := controller.AddFrontendPageBackupController(mgr); err != nil {
+		if err := controller.AddFrontendPageBackupController(mgr, watchScope); err != nil {
 			log.Error().Err(err).Msg("Failed to add frontend controller")
 			os.Exit(1)
 		}
+		if orphanScanInterval > 0 {
+			if err := mgr.Add(&controller.OrphanScanner{
+				Client:         mgr.GetClient(),
+				Scope:          watchScope,
+				Interval:       orphanScanInterval,
+				GarbageCollect: orphanGC,
+			}); err != nil {
+				log.Error().Err(err).Msg("Failed to add orphan scanner")
+				os.Exit(1)
+			}
+		}
+		managerErr := make(chan error, 1)
 		go func() {
-			log.Info().Msg("Starting controller-runtime manager ... --watch-namespace=" + namespace)
-			if err := mgr.Start(cmd.Context()); err != nil {
+			log.Info().Msg("Starting controller-runtime manager ... --watch-namespaces=" + watchScope.String())
+			err := mgr.Start(mgrCtx)
+			if err != nil {
 				log.Error().Err(err).Msg("Manager exited with error")
-				os.Exit(1)
+				stop() // shut the rest down too
+			}
+			managerErr <- err
+		}()
+		go func() {
+			err := configLoader.Watch(rootCtx, effectiveConfig, func(old, cfg *config.Config) {
+				reloadConfig(old, cfg, policyLoader, policyChanged)
+			})
+			if err != nil {
+				log.Error().Err(err).Msg("Failed to watch the config file, changes need a restart")
 			}
 		}()
+		protect := func(h fasthttp.RequestHandler) fasthttp.RequestHandler { return h }
+		if enableAuth {
+			authenticators := auth.Union{}
+			if clientCAFile != "" {
+				authenticators = append(authenticators, auth.ClientCertAuthenticator{})
+			}
+			authenticators = append(authenticators, auth.NewTokenReviewAuthenticator(clientset, authCacheTTL))
+			protect = auth.Middleware(authenticators)
+		} else {
+			log.Warn().Msg("REST API authentication is disabled")
+		}
 		router := fasthttprouter.New()
 		frontendAPI := &api.FrontendPageAPI{
 			K8sClient: mgr.GetClient(),
-			Namespace: namespace,
-		}
-		router.GET("/api/frontendpages", frontendAPI.ListFrontendPages)
-		//curl -X POST -H "Content-Type: application/json" --data-binary "@config/crd/frontendPage_post.json" http://localhost:8080/api/frontendpages
-		router.POST("/api/frontendpages", frontendAPI.CreateFrontendPage)
-		router.GET("/api/frontendpages/:name", frontendAPI.GetFrontendPage)
-		router.PUT("/api/frontendpages/:name", frontendAPI.UpdateFrontendPage)
-		router.DELETE("/api/frontendpages/:name", frontendAPI.DeleteFrontendPage)
+			Scope:     watchScope,
+		}
+		informerAPI := &api.InformerAPI{
+			Listers: informers,
+			Scope:   watchScope,
+		}
+		resourcesAPI := &api.ResourcesAPI{Informers: dynamicInformers}
+		reportsAPI := &api.ReportsAPI{Reports: policyReports, Scope: watchScope}
+		if enableAuth {
+			switch authzMode {
+			case auth.AuthzModeSAR:
+				frontendAPI.Authorizer = &auth.SubjectAccessReviewer{Client: clientset}
+				informerAPI.Authorizer = frontendAPI.Authorizer
+				resourcesAPI.Authorizer = frontendAPI.Authorizer
+				reportsAPI.Authorizer = frontendAPI.Authorizer
+			case auth.AuthzModeImpersonate:
+				// Cache-backed reads cannot be impersonated, so they are checked with SubjectAccessReview.
+				informerAPI.Authorizer = &auth.SubjectAccessReviewer{Client: clientset}
+				resourcesAPI.Authorizer = informerAPI.Authorizer
+				reportsAPI.Authorizer = informerAPI.Authorizer
+				frontendAPI.Impersonator = &auth.ImpersonatingClientFactory{
+					Config: mgr.GetConfig(),
+					Scheme: mgr.GetScheme(),
+					Mapper: mgr.GetRESTMapper(),
+				}
+			case auth.AuthzModeNone:
+				log.Warn().Msg("REST API authorization is disabled, requests run with the controller's privileges")
+			default:
+				log.Error().Msgf("Unknown --authz-mode %q", authzMode)
+				os.Exit(1)
+			}
+		}
+		router.GET("/api/frontendpages", protect(frontendAPI.ListFrontendPages))
+		//curl -X POST -H "Content-Type: application/json" -H "Authorization: Bearer $TOKEN" --data-binary "@config/crd/frontendPage_post.json" http://localhost:8080/api/frontendpages
+		router.POST("/api/frontendpages", protect(frontendAPI.CreateFrontendPage))
+		router.POST("/api/frontendpages/render", protect(frontendAPI.RenderFrontendPages))
+		router.GET("/api/frontendpages/:name", protect(frontendAPI.GetFrontendPage))
+		router.PUT("/api/frontendpages/:name", protect(frontendAPI.UpdateFrontendPage))
+		router.DELETE("/api/frontendpages/:name", protect(frontendAPI.DeleteFrontendPage))
+		router.GET("/api/deployments", protect(informerAPI.ListDeployments))
+		router.GET("/api/deployments/:name", protect(informerAPI.GetDeployment))
+		router.GET("/api/secrets", protect(informerAPI.ListSecrets))
+		router.GET("/api/resources", protect(resourcesAPI.ListWatchedResources))
+		router.GET("/api/resources/:group/:version/:resource", protect(resourcesAPI.ListResources))
+		router.GET("/api/resources/:group/:version/:resource/:name", protect(resourcesAPI.GetResource))
+		router.GET("/api/reports/deployments", protect(reportsAPI.DeploymentReports))
+		router.GET("/openapi.json", api.ServeOpenAPI)
+		router.GET("/healthz", health.Handler("healthz", health.Ping()))
+		router.GET("/livez", health.Handler("livez", health.Ping()))
+		router.GET("/readyz", health.Handler("readyz", append([]health.Check{health.Ping()}, readiness...)...))
+		router.GET("/swagger/", api.ServeSwaggerUI)
 		//OLD way, can just parse the methods
 		//handler := func(ctx *fasthttp.RequestCtx) {
 		//	uuid := uuid.New().String()
@@ -125,40 +343,6 @@ var serverCmd = &cobra.Command{
 		//		if err != nil {
 		//			return
 		//		}
-		//	case "/deployments":
-		//		log.Info().Msg("Deployments request received")
-		//		ctx.Response.Header.Set("Content-Type", "application/json")
-		//		deployments := informer.GetDeploymentNames()
-		//		log.Info().Msgf("Deployments: %v", deployments)
-		//		ctx.SetStatusCode(200)
-		//		ctx.Write([]byte("[")) //nolint:errcheck
-		//		for i, name := range deployments {
-		//			ctx.WriteString("\"") //nolint:errcheck
-		//			ctx.WriteString(name) //nolint:errcheck
-		//			ctx.WriteString("\"") //nolint:errcheck
-		//			if i < len(deployments)-1 {
-		//				ctx.WriteString(",") //nolint:errcheck
-		//			}
-		//		}
-		//		ctx.Write([]byte("]")) //nolint:errcheck
-		//		return
-		//	case "/secrets":
-		//		log.Info().Msg("Secrets request received")
-		//		ctx.Response.Header.Set("Content-Type", "application/json")
-		//		secrets := informer.GetSecretNames()
-		//		log.Info().Msgf("Secrets: %v", secrets)
-		//		ctx.SetStatusCode(200)
-		//		ctx.Write([]byte("[")) //nolint:errcheck
-		//		for i, name := range secrets {
-		//			ctx.WriteString("\"") //nolint:errcheck
-		//			ctx.WriteString(name) //nolint:errcheck
-		//			ctx.WriteString("\"") //nolint:errcheck
-		//			if i < len(secrets)-1 {
-		//				ctx.WriteString(",") //nolint:errcheck
-		//			}
-		//		}
-		//		ctx.Write([]byte("]")) //nolint:errcheck
-		//		return
 		//	default:
 		//		log.Info().
 		//			Str("request_id", uuid).
@@ -173,35 +357,297 @@ var serverCmd = &cobra.Command{
 		//		}
 		//	}
 		//}
-		addr := fmt.Sprintf(":%d", serverPort)
+		srv := newHTTPServer(router.Handler) // here  you can switch between the handlers old=handler and new=router.Handler
 		log.Info().Msgf("Starting FastHTTP server on %s port", addr)
-		if err := fasthttp.ListenAndServe(addr, router.Handler); err != nil { // here  you can switch between the handlers old=handler and new=router.Handler
-			log.Error().Err(err).Msg("Error starting FastHTTP server")
+		failed := false
+		if err := serveHTTP(rootCtx, srv, ln, shutdownTimeout); err != nil {
+			log.Error().Err(err).Msg("FastHTTP server error")
+			failed = true
+		}
+		log.Info().Msg("Stopping informers...")
+		stopInformers()
+		informers.Shutdown()
+		if dynamicInformers != nil {
+			dynamicInformers.Shutdown()
+		}
+		log.Info().Msg("Draining event sinks...")
+		closeEventBus(eventBus, stopEvents, shutdownTimeout)
+		log.Info().Msg("Stopping controller-runtime manager...")
+		stopManager()
+		if err := <-managerErr; err != nil {
+			failed = true
+		}
+		if failed {
 			os.Exit(1)
 		}
+		log.Info().Msg("Shutdown complete")
 	},
 }
 
+// reloadConfig applies the settings that are safe to change at runtime, the log level and
+// the default policy, and logs every other changed key as needing a restart.
+func reloadConfig(old, cfg *config.Config, policies *policy.Loader, policyChanged chan<- event.GenericEvent) {
+	policyUpdated := false
+	for _, key := range config.Diff(old, cfg) {
+		switch {
+		case key == "logLevel":
+			configureLogger(parseLogLevel(cfg.LogLevel))
+			log.Info().Msgf("Log level changed to %s", cfg.LogLevel)
+		case strings.HasPrefix(key, "server.policy.default."):
+			policyUpdated = true
+		default:
+			log.Warn().Msgf("Config %s changed, restart the server to apply it", key)
+		}
+	}
+	if !policyUpdated {
+		return
+	}
+	policies.SetDefault(cfg.Server.Policy.Default)
+	log.Info().Msg("Default deployment policy changed, re-auditing Deployments")
+	// One pending trigger re-audits everything, so a full channel needs no second one.
+	select {
+	case policyChanged <- event.GenericEvent{Object: &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: policies.Namespace, Name: policies.Name}}}:
+	default:
+	}
+}
+
 func getServerKubeClient(kubeconfigPath string, inCluster bool) (*kubernetes.Clientset, error) {
-	var config *rest.Config
-	var err error
+	config, err := getServerRestConfig(kubeconfigPath, inCluster)
+	if err != nil {
+		return nil, err
+	}
+	return kubernetes.NewForConfig(config)
+}
+
+func getServerRestConfig(kubeconfigPath string, inCluster bool) (*rest.Config, error) {
 	if inCluster {
-		config, err = rest.InClusterConfig()
-	} else {
-		config, err = clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+		return rest.InClusterConfig()
 	}
+	return getRestConfig(kubeconfigPath)
+}
+
+// startDynamicInformers watches the resources listed in the config file at path.
+func startDynamicInformers(ctx context.Context, path string, publisher events.Publisher) (*informer.DynamicInformerManager, error) {
+	cfg, err := informer.LoadDynamicConfig(path)
 	if err != nil {
 		return nil, err
 	}
-	return kubernetes.NewForConfig(config)
+	cfg.Events = publisher
+	restConfig, err := getServerRestConfig(kubeconfig, serverInCluster)
+	if err != nil {
+		return nil, err
+	}
+	client, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	m, err := informer.NewDynamicInformerManager(client, *cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.Start(ctx); err != nil {
+		return nil, err
+	}
+	go func() {
+		if err := m.WaitForSync(ctx); err != nil {
+			log.Error().Err(err).Msg("Dynamic informers failed to sync")
+		}
+	}()
+	return m, nil
+}
+
+// managerCacheOptions limits the manager cache to the listed namespaces, plus the policy
+// ConfigMap's namespace for ConfigMaps. Selector and * scopes cache every namespace and
+// leave the filtering to the controllers.
+func managerCacheOptions(s *scope.Scope, policyNamespace string) cache.Options {
+	if s.ClusterWide() {
+		return cache.Options{}
+	}
+	namespaces := map[string]cache.Config{}
+	for _, ns := range s.Namespaces() {
+		namespaces[ns] = cache.Config{}
+	}
+	opts := cache.Options{DefaultNamespaces: namespaces}
+	if _, ok := namespaces[policyNamespace]; !ok && policyNamespace != "" {
+		configMaps := maps.Clone(namespaces)
+		configMaps[policyNamespace] = cache.Config{}
+		opts.ByObject = map[client.Object]cache.ByObject{&corev1.ConfigMap{}: {Namespaces: configMaps}}
+	}
+	return opts
+}
+
+// newEventBus builds the change event bus from the --event-* flags.
+func newEventBus() (*events.Bus, error) {
+	var sinks []events.Sink
+	for _, name := range eventSinks {
+		switch name {
+		case "log":
+			sinks = append(sinks, events.LogSink{})
+		case "webhook":
+			if eventWebhookURL == "" {
+				return nil, fmt.Errorf("the webhook event sink needs --event-webhook-url")
+			}
+			sinks = append(sinks, events.NewWebhookSink(eventWebhookURL))
+		case "nats":
+			client, err := events.NewNATSClient(eventNATSURL)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, &events.StreamSink{Client: client, Subject: eventNATSSubject, PerKind: true})
+		case "file":
+			if eventJournalFile == "" {
+				return nil, fmt.Errorf("the file event sink needs --event-journal-file")
+			}
+			journal, err := events.NewFileJournal(eventJournalFile, true)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, journal)
+		default:
+			return nil, fmt.Errorf("unknown event sink %q, want log, webhook, nats or file", name)
+		}
+	}
+	return events.NewBus(events.Options{QueueSize: eventQueueSize, MaxAttempts: eventMaxAttempts}, sinks...), nil
+}
+
+// closeEventBus waits up to timeout for the sinks to drain, then abandons pending retries.
+func closeEventBus(bus *events.Bus, cancel context.CancelFunc, timeout time.Duration) {
+	done := make(chan error, 1)
+	go func() { done <- bus.Close() }()
+	var err error
+	select {
+	case err = <-done:
+	case <-time.After(timeout):
+		cancel()
+		err = <-done
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to close event sinks")
+	}
+	for name, st := range bus.Stats() {
+		log.Info().Str("sink", name).Uint64("sent", st.Sent).Interface("dropped", st.Dropped).Msg("Event sink closed")
+	}
+}
+
+// newRootContext returns a context cancelled on SIGINT or SIGTERM.
+func newRootContext(parent context.Context) (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(parent, os.Interrupt, syscall.SIGTERM)
+}
+
+// newHTTPServer returns a FastHTTP server configured from the server flags.
+func newHTTPServer(handler fasthttp.RequestHandler) *fasthttp.Server {
+	return &fasthttp.Server{
+		Handler:            handler,
+		Name:               "kctl",
+		ReadTimeout:        serverReadTimeout,
+		WriteTimeout:       serverWriteTimeout,
+		IdleTimeout:        serverIdleTimeout,
+		MaxRequestBodySize: serverMaxBodySize,
+		CloseOnShutdown:    true,
+	}
+}
+
+// newHTTPListener listens on addr, wrapping the listener in TLS when a certificate
+// is configured. The certificate is reloaded from disk whenever it changes.
+func newHTTPListener(ctx context.Context, addr string) (net.Listener, error) {
+	if tlsCertFile == "" && clientCAFile != "" {
+		return nil, fmt.Errorf("--client-ca-file requires --tls-cert-file and --tls-key-file")
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if tlsCertFile == "" {
+		return ln, nil
+	}
+	tlsConfig, err := buildServerTLSConfig(clientCAFile)
+	if err != nil {
+		_ = ln.Close()
+		return nil, err
+	}
+	watcher, err := certwatch.New(tlsCertFile, tlsKeyFile)
+	if err != nil {
+		_ = ln.Close()
+		return nil, err
+	}
+	go watcher.Start(ctx)
+	tlsConfig.GetCertificate = watcher.GetCertificate
+	return tls.NewListener(ln, tlsConfig), nil
+}
+
+// serveHTTP serves srv on ln until ctx is cancelled, then stops accepting new
+// connections and waits up to timeout for in-flight requests to finish.
+func serveHTTP(ctx context.Context, srv *fasthttp.Server, ln net.Listener, timeout time.Duration) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve(ln)
+	}()
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+	log.Info().Msg("Shutting down FastHTTP server, draining in-flight requests...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := srv.ShutdownWithContext(shutdownCtx); err != nil {
+		return err
+	}
+	return <-serveErr
+}
+
+// buildServerTLSConfig returns the server TLS settings. When clientCAFile is set,
+// client certificates signed by that CA are verified and used for authentication.
+func buildServerTLSConfig(clientCAFile string) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if clientCAFile == "" {
+		return cfg, nil
+	}
+	caPEM, err := os.ReadFile(clientCAFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no certificates found in %s", clientCAFile)
+	}
+	cfg.ClientCAs = pool
+	cfg.ClientAuth = tls.VerifyClientCertIfGiven
+	return cfg, nil
 }
 
 func init() {
 	rootCmd.AddCommand(serverCmd)
-	serverCmd.Flags().IntVar(&serverPort, "port", 8080, "Port to run the server on")
-	serverCmd.Flags().StringVar(&serverKubeconfig, "kubeconfig", "", "Path to the kubeconfig file")
-	serverCmd.Flags().BoolVar(&serverInCluster, "in-cluster", false, "Use in-cluster Kubernetes config")
-	serverCmd.Flags().StringVar(&namespace, "watch-ns", "default", "Define the namespace to be watched by the informer, otherwise the default namespace is used")
-	serverCmd.Flags().BoolVar(&enableLeaderElection, "enable-leader-election", true, "Enable leader election for controller manager")
-	serverCmd.Flags().IntVar(&metricsPort, "metrics-port", 8081, "Port for controller manager metrics")
+	defaults := config.Default()
+	serverCmd.Flags().IntVar(&serverPort, "port", defaults.Server.Port, "Port to run the server on")
+	serverCmd.Flags().BoolVar(&serverInCluster, "in-cluster", defaults.Server.InCluster, "Use in-cluster Kubernetes config")
+	serverCmd.Flags().StringVar(&watchNamespaces, "watch-namespaces", defaults.Server.WatchNamespaces, "Namespaces to watch: a comma-separated list, a label selector on namespaces (e.g. team=web), or * for all")
+	serverCmd.Flags().StringVar(&watchNS, "watch-ns", defaults.Server.WatchNamespaces, "Single namespace to watch")
+	_ = serverCmd.Flags().MarkDeprecated("watch-ns", "use --watch-namespaces")
+	serverCmd.Flags().StringVar(&leaderElectionNamespace, "leader-election-namespace", defaults.Server.LeaderElection.Namespace, "Namespace of the leader election lease and the policy ConfigMap (default: the first watched namespace, or default)")
+	serverCmd.Flags().BoolVar(&enableLeaderElection, "enable-leader-election", defaults.Server.LeaderElection.Enabled, "Enable leader election for controller manager")
+	serverCmd.Flags().IntVar(&metricsPort, "metrics-port", defaults.Server.MetricsPort, "Port for controller manager metrics")
+	serverCmd.Flags().IntVar(&probePort, "health-probe-port", defaults.Server.HealthProbePort, "Port for controller manager health probes (/healthz, /readyz)")
+	serverCmd.Flags().BoolVar(&enableAuth, "enable-auth", defaults.Server.Auth.Enabled, "Require authentication (bearer token or client certificate) for the REST API")
+	serverCmd.Flags().StringVar(&authzMode, "authz-mode", defaults.Server.Auth.AuthzMode, "REST API authorization: sar (SubjectAccessReview per request), impersonate (act as the caller) or none")
+	serverCmd.Flags().DurationVar(&authCacheTTL, "auth-cache-ttl", defaults.Server.Auth.CacheTTL.Duration, "How long TokenReview results are cached")
+	serverCmd.Flags().StringVar(&tlsCertFile, "tls-cert-file", defaults.Server.TLS.CertFile, "Path to the TLS certificate; enables HTTPS when set")
+	serverCmd.Flags().StringVar(&tlsKeyFile, "tls-key-file", defaults.Server.TLS.KeyFile, "Path to the TLS private key")
+	serverCmd.Flags().DurationVar(&serverReadTimeout, "read-timeout", defaults.Server.HTTP.ReadTimeout.Duration, "Maximum duration for reading a full request")
+	serverCmd.Flags().DurationVar(&serverWriteTimeout, "write-timeout", defaults.Server.HTTP.WriteTimeout.Duration, "Maximum duration for writing a response")
+	serverCmd.Flags().DurationVar(&serverIdleTimeout, "idle-timeout", defaults.Server.HTTP.IdleTimeout.Duration, "Maximum time to keep an idle keep-alive connection open")
+	serverCmd.Flags().IntVar(&serverMaxBodySize, "max-body-size", defaults.Server.HTTP.MaxBodySize, "Maximum request body size in bytes")
+	serverCmd.Flags().DurationVar(&shutdownTimeout, "shutdown-timeout", defaults.Server.HTTP.ShutdownTimeout.Duration, "How long to wait for in-flight requests on shutdown")
+	serverCmd.Flags().StringVar(&resourcesConfig, "resources-config", defaults.Server.ResourcesConfig, "Path to a YAML file listing extra resources (GVRs) to cache and serve under /api/resources")
+	serverCmd.Flags().StringSliceVar(&eventSinks, "event-sinks", defaults.Server.Events.Sinks, "Sinks for resource change events: log, webhook, nats, file")
+	serverCmd.Flags().StringVar(&eventWebhookURL, "event-webhook-url", defaults.Server.Events.WebhookURL, "URL the webhook event sink POSTs change events to")
+	serverCmd.Flags().StringVar(&eventJournalFile, "event-journal-file", defaults.Server.Events.JournalFile, "File the file event sink appends change events to, one JSON object per line")
+	serverCmd.Flags().StringVar(&eventNATSURL, "event-nats-url", defaults.Server.Events.NATSURL, "NATS server the nats event sink publishes to")
+	serverCmd.Flags().StringVar(&eventNATSSubject, "event-nats-subject", defaults.Server.Events.NATSSubject, "Subject prefix for the nats event sink; events go to <prefix>.<Kind>.<Type>")
+	serverCmd.Flags().IntVar(&eventQueueSize, "event-queue-size", defaults.Server.Events.QueueSize, "Per-sink event queue size; events are dropped when it is full")
+	serverCmd.Flags().IntVar(&eventMaxAttempts, "event-max-attempts", defaults.Server.Events.MaxAttempts, "Delivery attempts per event and sink before it is dropped")
+	serverCmd.Flags().StringVar(&policyConfigMap, "policy-configmap", defaults.Server.Policy.ConfigMap, "ConfigMap in the leader election namespace holding the Deployment audit policy under policy.yaml; reloaded on change")
+	serverCmd.Flags().DurationVar(&orphanScanInterval, "orphan-scan-interval", defaults.Server.Orphans.ScanInterval.Duration, "How often to scan for orphaned or drifted FrontendPage children; 0 disables the scan")
+	serverCmd.Flags().BoolVar(&orphanGC, "orphan-gc", defaults.Server.Orphans.GC, "Delete orphaned FrontendPage children found by the scan (drifted objects are only reported)")
+	serverCmd.Flags().StringVar(&clientCAFile, "client-ca-file", defaults.Server.TLS.ClientCAFile, "Path to a CA bundle used to verify client certificates (mTLS authentication)")
 }
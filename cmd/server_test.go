@@ -1,7 +1,22 @@
 package cmd
 
 import (
+	"context"
+	"net"
+	"os"
+	"syscall"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/silhouetteUA/k8s-controller/pkg/config"
+	"github.com/silhouetteUA/k8s-controller/pkg/policy"
+	"github.com/silhouetteUA/k8s-controller/pkg/scope"
 )
 
 func TestServerCommandDefined(t *testing.T) {
@@ -16,3 +31,94 @@ func TestServerCommandDefined(t *testing.T) {
 		t.Error("expected 'port' flag to be defined")
 	}
 }
+
+func TestServeHTTP_DrainsInFlightRequestOnSIGTERM(t *testing.T) {
+	ctx, stop := newRootContext(context.Background())
+	defer stop()
+
+	started := make(chan struct{})
+	srv := newHTTPServer(func(ctx *fasthttp.RequestCtx) {
+		close(started)
+		time.Sleep(500 * time.Millisecond)
+		ctx.SetBodyString("done")
+	})
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	served := make(chan error, 1)
+	go func() {
+		served <- serveHTTP(ctx, srv, ln, 5*time.Second)
+	}()
+
+	type result struct {
+		status int
+		body   string
+		err    error
+	}
+	response := make(chan result, 1)
+	go func() {
+		status, body, err := fasthttp.Get(nil, "http://"+ln.Addr().String()+"/")
+		response <- result{status, string(body), err}
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("request never reached the handler")
+	}
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGTERM))
+
+	res := <-response
+	require.NoError(t, res.err)
+	require.Equal(t, fasthttp.StatusOK, res.status)
+	require.Equal(t, "done", res.body)
+
+	select {
+	case err := <-served:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not shut down after SIGTERM")
+	}
+
+	// The listener is closed, new connections are refused.
+	_, _, err = fasthttp.Get(nil, "http://"+ln.Addr().String()+"/")
+	require.Error(t, err)
+}
+
+func TestManagerCacheOptions(t *testing.T) {
+	all, err := scope.Parse(scope.All)
+	require.NoError(t, err)
+	require.Empty(t, managerCacheOptions(all, "default").DefaultNamespaces)
+
+	list, err := scope.Parse("web,api")
+	require.NoError(t, err)
+	opts := managerCacheOptions(list, "web")
+	require.Equal(t, map[string]cache.Config{"web": {}, "api": {}}, opts.DefaultNamespaces)
+	require.Empty(t, opts.ByObject, "the policy namespace is already cached")
+
+	opts = managerCacheOptions(list, "kctl-system")
+	require.Len(t, opts.ByObject, 1)
+	for obj, byObject := range opts.ByObject {
+		require.IsType(t, &corev1.ConfigMap{}, obj)
+		require.Equal(t, map[string]cache.Config{"web": {}, "api": {}, "kctl-system": {}}, byObject.Namespaces)
+	}
+	require.NotContains(t, opts.DefaultNamespaces, "kctl-system")
+}
+
+func TestReloadConfig(t *testing.T) {
+	loader := &policy.Loader{Name: ""}
+	changed := make(chan event.GenericEvent, 1)
+
+	old, cfg := config.Default(), config.Default()
+	cfg.Server.Port = 9090
+	reloadConfig(old, cfg, loader, changed)
+	require.Empty(t, changed, "restart-only settings are not applied")
+
+	cfg.Server.Policy.Default.MinReplicas = 3
+	reloadConfig(old, cfg, loader, changed)
+	reloadConfig(old, cfg, loader, changed) // a pending trigger is enough, this must not block
+	require.Len(t, changed, 1)
+	p, err := loader.Current(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, 3, p.MinReplicas)
+}
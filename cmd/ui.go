@@ -0,0 +1,608 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	frontendv1alpha2 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/frontendBackup"
+	frontendv1alpha1 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/v1alpha1"
+	"github.com/silhouetteUA/k8s-controller/pkg/printers"
+	"github.com/silhouetteUA/k8s-controller/pkg/render"
+)
+
+// Terminal control sequences used by kctl ui.
+const (
+	altScreenOn  = "\x1b[?1049h\x1b[?25l"
+	altScreenOff = "\x1b[?25h\x1b[?1049l"
+	clearScreen  = "\x1b[H\x1b[2J"
+	reverseVideo = "\x1b[7m"
+	resetVideo   = "\x1b[0m"
+)
+
+const uiHelp = "↑/↓ select  +/- scale  e edit contents  b back up now  v events  r refresh  q quit"
+
+var uiAllNamespaces bool
+
+var uiCmd = &cobra.Command{
+	Use:   "ui",
+	Short: "Browse FrontendPages in an interactive terminal dashboard",
+	Long: `Shows the FrontendPages of the namespace with the readiness of their Deployments, the size of
+their ConfigMaps and the status of their backups, updated live as the cluster changes.
+
+Keys: ↑/↓ or k/j select a page, + and - scale it, e edits its contents in $KCTL_EDITOR or $EDITOR,
+b runs its backup CronJob now, v shows its events, r reloads and q quits.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ns := namespace
+		if uiAllNamespaces {
+			ns = ""
+		}
+		runWithWatchClient("run the dashboard", func(ctx context.Context, c client.WithWatch) error {
+			return runUI(ctx, c, os.Stdin, cmd.OutOrStdout(), ns)
+		})
+	},
+}
+
+// dashboardRow is a FrontendPage with the state of the objects built for it.
+type dashboardRow struct {
+	Page  frontendv1alpha1.FrontendPage
+	Ready int32
+	// ConfigMapBytes is the size of the page's ConfigMap data, -1 when it does not exist.
+	ConfigMapBytes int
+	Backups        []frontendv1alpha2.FrontendPageBackup
+}
+
+func (r dashboardRow) key() types.NamespacedName {
+	return types.NamespacedName{Namespace: r.Page.Namespace, Name: r.Page.Name}
+}
+
+// dashboard is the state of kctl ui. It is driven by handleKey and reload and drawn by draw;
+// only runUI touches the terminal.
+type dashboard struct {
+	c client.Client
+	// namespace is listed; empty means every namespace.
+	namespace string
+	rows      []dashboardRow
+	selected  int
+	// status is the result of the last action, shown above the help line.
+	status string
+	// events is the event table of the selected page while it is shown.
+	events *printers.Output
+	// edit opens a file in the user's editor.
+	edit func(path string) error
+}
+
+// loadDashboard lists the pages of namespace with their Deployments, ConfigMaps and backups.
+func loadDashboard(ctx context.Context, c client.Client, namespace string) ([]dashboardRow, error) {
+	var pages frontendv1alpha1.FrontendPageList
+	if err := c.List(ctx, &pages, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	var deployments appsv1.DeploymentList
+	if err := c.List(ctx, &deployments, client.InNamespace(namespace), client.HasLabels{render.LabelFrontendPage}); err != nil {
+		return nil, err
+	}
+	var configMaps corev1.ConfigMapList
+	if err := c.List(ctx, &configMaps, client.InNamespace(namespace), client.HasLabels{render.LabelFrontendPage}); err != nil {
+		return nil, err
+	}
+	var backups frontendv1alpha2.FrontendPageBackupList
+	if err := c.List(ctx, &backups, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+
+	ready := map[types.NamespacedName]int32{}
+	for _, d := range deployments.Items {
+		ready[types.NamespacedName{Namespace: d.Namespace, Name: d.Labels[render.LabelFrontendPage]}] = d.Status.ReadyReplicas
+	}
+	sizes := map[types.NamespacedName]int{}
+	for _, cm := range configMaps.Items {
+		size := 0
+		for k, v := range cm.Data {
+			size += len(k) + len(v)
+		}
+		for k, v := range cm.BinaryData {
+			size += len(k) + len(v)
+		}
+		sizes[types.NamespacedName{Namespace: cm.Namespace, Name: cm.Labels[render.LabelFrontendPage]}] = size
+	}
+	pageBackups := map[types.NamespacedName][]frontendv1alpha2.FrontendPageBackup{}
+	for _, b := range backups.Items {
+		key := types.NamespacedName{Namespace: b.Namespace, Name: b.Spec.FrontendPageRef}
+		pageBackups[key] = append(pageBackups[key], b)
+	}
+
+	rows := make([]dashboardRow, 0, len(pages.Items))
+	for _, p := range pages.Items {
+		key := types.NamespacedName{Namespace: p.Namespace, Name: p.Name}
+		size, ok := sizes[key]
+		if !ok {
+			size = -1
+		}
+		rows = append(rows, dashboardRow{Page: p, Ready: ready[key], ConfigMapBytes: size, Backups: pageBackups[key]})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Page.Namespace != rows[j].Page.Namespace {
+			return rows[i].Page.Namespace < rows[j].Page.Namespace
+		}
+		return rows[i].Page.Name < rows[j].Page.Name
+	})
+	return rows, nil
+}
+
+// dashboardOutput describes the rows for the printers.
+func dashboardOutput(rows []dashboardRow, withNamespace bool) printers.Output {
+	var out printers.Output
+	if withNamespace {
+		out.Columns = append(out.Columns, printers.Column{Header: "NAMESPACE"})
+	}
+	out.Columns = append(out.Columns,
+		printers.Column{Header: "NAME"},
+		printers.Column{Header: "READY"},
+		printers.Column{Header: "IMAGE"},
+		printers.Column{Header: "CONFIGMAP"},
+		printers.Column{Header: "BACKUP"},
+		printers.Column{Header: "AGE"},
+	)
+	for _, r := range rows {
+		var row []string
+		if withNamespace {
+			row = append(row, r.Page.Namespace)
+		}
+		configMap := "<none>"
+		if r.ConfigMapBytes >= 0 {
+			configMap = fmt.Sprintf("%d bytes", r.ConfigMapBytes)
+		}
+		row = append(row, r.Page.Name, fmt.Sprintf("%d/%d", r.Ready, r.Page.Spec.Replicas), r.Page.Spec.Image,
+			configMap, backupSummary(r.Backups), age(r.Page.CreationTimestamp))
+		out.Rows = append(out.Rows, row)
+	}
+	return out
+}
+
+// backupSummary is the status and age of the most recent backup of a page.
+func backupSummary(backups []frontendv1alpha2.FrontendPageBackup) string {
+	if len(backups) == 0 {
+		return "<none>"
+	}
+	latest := backups[0]
+	for _, b := range backups[1:] {
+		if b.Status.LastBackupTime != nil && (latest.Status.LastBackupTime == nil || latest.Status.LastBackupTime.Before(b.Status.LastBackupTime)) {
+			latest = b
+		}
+	}
+	if latest.Status.LastBackupTime == nil {
+		return "never"
+	}
+	status := latest.Status.Status
+	if status == "" {
+		status = "done"
+	}
+	return fmt.Sprintf("%s %s ago", status, age(*latest.Status.LastBackupTime))
+}
+
+// reload lists the pages again, keeping the selected page selected.
+func (d *dashboard) reload(ctx context.Context) {
+	var selected types.NamespacedName
+	if page, ok := d.current(); ok {
+		selected = page.key()
+	}
+	rows, err := loadDashboard(ctx, d.c, d.namespace)
+	if err != nil {
+		d.status = "Error: " + err.Error()
+		return
+	}
+	d.rows = rows
+	d.selected = min(d.selected, max(len(rows)-1, 0))
+	for i, r := range rows {
+		if r.key() == selected {
+			d.selected = i
+		}
+	}
+	if d.events != nil {
+		d.showEvents(ctx)
+	}
+}
+
+func (d *dashboard) current() (dashboardRow, bool) {
+	if d.selected < 0 || d.selected >= len(d.rows) {
+		return dashboardRow{}, false
+	}
+	return d.rows[d.selected], true
+}
+
+// handleKey runs the action bound to key and reports whether the dashboard should close.
+func (d *dashboard) handleKey(ctx context.Context, key string) bool {
+	if key == "ctrl+c" {
+		return true
+	}
+	if d.events != nil {
+		if key == "esc" || key == "q" || key == "v" {
+			d.events = nil
+		}
+		return false
+	}
+	switch key {
+	case "q":
+		return true
+	case "up", "k":
+		d.selected = max(d.selected-1, 0)
+		return false
+	case "down", "j":
+		d.selected = min(d.selected+1, max(len(d.rows)-1, 0))
+		return false
+	case "r":
+		d.status = ""
+		d.reload(ctx)
+		return false
+	}
+
+	row, ok := d.current()
+	if !ok {
+		return false
+	}
+	var err error
+	switch key {
+	case "+", "-":
+		replicas := row.Page.Spec.Replicas + 1
+		if key == "-" {
+			replicas = max(row.Page.Spec.Replicas-1, 0)
+		}
+		if err = scaleFrontendPage(ctx, d.c, row.key(), replicas); err == nil {
+			d.status = fmt.Sprintf("frontendpage %q scaled to %d", row.Page.Name, replicas)
+		}
+	case "e":
+		var buf bytes.Buffer
+		if err = editPageContents(ctx, d.c, &buf, row.key(), d.edit); err == nil {
+			d.status = strings.TrimSpace(buf.String())
+		}
+	case "b":
+		var job string
+		if job, err = triggerBackup(ctx, d.c, row.key()); err == nil {
+			d.status = fmt.Sprintf("job %q created", job)
+		}
+	case "v":
+		d.events = &printers.Output{}
+		d.showEvents(ctx)
+		return false
+	default:
+		return false
+	}
+	if err != nil {
+		d.status = "Error: " + err.Error()
+		return false
+	}
+	d.reload(ctx)
+	return false
+}
+
+func (d *dashboard) showEvents(ctx context.Context) {
+	row, ok := d.current()
+	if !ok {
+		d.events = nil
+		return
+	}
+	events, err := pageEvents(ctx, d.c, row.key())
+	if err != nil {
+		d.status = "Error: " + err.Error()
+		d.events = nil
+		return
+	}
+	out := eventsOutput(events)
+	d.events = &out
+}
+
+// draw renders the dashboard for a terminal of the given size. Lines end in \r\n as the
+// terminal is in raw mode.
+func (d *dashboard) draw(w io.Writer, width, height int) error {
+	var lines []string
+	selectedLine := -1
+	if d.events != nil {
+		row, _ := d.current()
+		lines = append(lines, fmt.Sprintf("Events of frontendpage %s/%s (esc to go back)", row.Page.Namespace, row.Page.Name), "")
+		if len(d.events.Rows) == 0 {
+			lines = append(lines, "No events")
+		} else {
+			lines = append(lines, tableLines(*d.events)...)
+		}
+	} else {
+		where := fmt.Sprintf("namespace %s", d.namespace)
+		if d.namespace == "" {
+			where = "all namespaces"
+		}
+		lines = append(lines, fmt.Sprintf("kctl ui - %s - %d FrontendPage(s)", where, len(d.rows)), "")
+		if len(d.rows) == 0 {
+			lines = append(lines, "No FrontendPages found")
+		} else {
+			table := tableLines(dashboardOutput(d.rows, d.namespace == ""))
+			// Keep the selected row on screen; the header always stays.
+			visible := max(height-6, 1)
+			first := max(d.selected-visible+1, 0)
+			lines = append(lines, "  "+table[0])
+			for i, line := range table[1:] {
+				if i < first || i >= first+visible {
+					continue
+				}
+				prefix := "  "
+				if i == d.selected {
+					prefix = "> "
+					selectedLine = len(lines)
+				}
+				lines = append(lines, prefix+line)
+			}
+		}
+	}
+	for len(lines) < height-2 {
+		lines = append(lines, "")
+	}
+	lines = append(lines[:max(height-2, 0)], d.status, uiHelp)
+
+	var b strings.Builder
+	b.WriteString(clearScreen)
+	for i, line := range lines {
+		line = truncate(line, width)
+		if i == selectedLine {
+			line = reverseVideo + line + strings.Repeat(" ", max(width-utf8.RuneCountInString(line), 0)) + resetVideo
+		}
+		b.WriteString(line)
+		if i < len(lines)-1 {
+			b.WriteString("\r\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// tableLines prints out as a table and splits it into lines.
+func tableLines(out printers.Output) []string {
+	var buf bytes.Buffer
+	if err := (printers.Options{Output: printers.Table}).Print(&buf, out); err != nil {
+		return []string{err.Error()}
+	}
+	return strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+}
+
+func truncate(s string, width int) string {
+	if width <= 0 || utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	return string([]rune(s)[:width])
+}
+
+// editPageContents opens the contents of a page in edit and updates the page with the
+// result. Like editFrontendPage, a change made meanwhile is a conflict, not an overwrite.
+func editPageContents(ctx context.Context, c client.Client, out io.Writer, key types.NamespacedName, edit func(path string) error) error {
+	var page frontendv1alpha1.FrontendPage
+	if err := c.Get(ctx, key, &page); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp("", "kctl-contents-"+key.Name+"-*.html")
+	if err != nil {
+		return err
+	}
+	path := f.Name()
+	_, err = f.WriteString(page.Spec.Contents)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	keep := false
+	defer func() {
+		if !keep {
+			_ = os.Remove(path)
+		}
+	}()
+	if err != nil {
+		return err
+	}
+
+	if err := edit(path); err != nil {
+		return fmt.Errorf("editor: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if string(data) == page.Spec.Contents {
+		_, err := fmt.Fprintln(out, "Edit cancelled, no changes made")
+		return err
+	}
+	page.Spec.Contents = string(data)
+	if err := c.Update(ctx, &page); err != nil {
+		keep = true
+		if apierrors.IsConflict(err) {
+			return fmt.Errorf("frontendpage %q was modified while it was being edited, your changes are saved in %s", key.Name, path)
+		}
+		return fmt.Errorf("%w (your changes are saved in %s)", err, path)
+	}
+	_, err = fmt.Fprintf(out, "frontendpage %q contents updated\n", key.Name)
+	return err
+}
+
+// triggerBackup runs the backup CronJob of a page now, as kubectl create job --from=cronjob
+// does, and returns the name of the Job.
+func triggerBackup(ctx context.Context, c client.Client, page types.NamespacedName) (string, error) {
+	var cron batchv1.CronJob
+	err := c.Get(ctx, types.NamespacedName{Namespace: page.Namespace, Name: render.BackupCronJobName(page.Name)}, &cron)
+	if apierrors.IsNotFound(err) {
+		return "", fmt.Errorf("frontendpage %q has no FrontendPageBackup", page.Name)
+	}
+	if err != nil {
+		return "", err
+	}
+	isController := true
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        fmt.Sprintf("%s-manual-%d", cron.Name, time.Now().Unix()),
+			Namespace:   cron.Namespace,
+			Labels:      cron.Spec.JobTemplate.Labels,
+			Annotations: map[string]string{"cronjob.kubernetes.io/instantiate": "manual"},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: batchv1.SchemeGroupVersion.String(),
+				Kind:       "CronJob",
+				Name:       cron.Name,
+				UID:        cron.UID,
+				Controller: &isController,
+			}},
+		},
+		Spec: cron.Spec.JobTemplate.Spec,
+	}
+	if err := c.Create(ctx, job); err != nil {
+		return "", err
+	}
+	return job.Name, nil
+}
+
+// runUI runs the dashboard on the terminal in until q is pressed. The pages are reloaded
+// whenever a page, Deployment, ConfigMap or backup in the namespace changes.
+func runUI(ctx context.Context, c client.WithWatch, in *os.File, out io.Writer, namespace string) error {
+	fd := int(in.Fd())
+	if !term.IsTerminal(fd) {
+		return errors.New("kctl ui needs an interactive terminal")
+	}
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(out, altScreenOn)
+	defer func() {
+		fmt.Fprint(out, altScreenOff)
+		_ = term.Restore(fd, state)
+	}()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	d := &dashboard{c: c, namespace: namespace}
+	// The editor gets the normal screen and a cooked terminal while it runs.
+	d.edit = func(path string) error {
+		fmt.Fprint(out, altScreenOff)
+		_ = term.Restore(fd, state)
+		err := runEditor(path)
+		if _, rawErr := term.MakeRaw(fd); err == nil {
+			err = rawErr
+		}
+		fmt.Fprint(out, altScreenOn)
+		return err
+	}
+
+	changed := make(chan struct{}, 1)
+	watchErr := make(chan error, 1)
+	go func() {
+		targets := []watchTarget{
+			{&frontendv1alpha1.FrontendPageList{}, types.NamespacedName{Namespace: namespace}},
+			{&appsv1.DeploymentList{}, types.NamespacedName{Namespace: namespace}},
+			{&corev1.ConfigMapList{}, types.NamespacedName{Namespace: namespace}},
+			{&frontendv1alpha2.FrontendPageBackupList{}, types.NamespacedName{Namespace: namespace}},
+		}
+		err := waitUntil(ctx, c, targets, func(context.Context) (bool, error) {
+			select {
+			case changed <- struct{}{}:
+			default:
+			}
+			return false, nil
+		})
+		if ctx.Err() == nil {
+			watchErr <- err
+		}
+	}()
+
+	keys := make(chan string)
+	resume := make(chan struct{})
+	go readKeys(ctx, in, keys, resume)
+	// Ages go stale without changes, so redraw now and then.
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	d.reload(ctx)
+	for {
+		width, height, err := term.GetSize(fd)
+		if err != nil {
+			width, height = 80, 24
+		}
+		if err := d.draw(out, width, height); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changed:
+			d.reload(ctx)
+		case err := <-watchErr:
+			d.status = "Error: live updates stopped: " + err.Error()
+		case <-ticker.C:
+		case key := <-keys:
+			quit := d.handleKey(ctx, key)
+			if quit {
+				return nil
+			}
+			resume <- struct{}{}
+		}
+	}
+}
+
+// readKeys sends each key read from in, then waits for resume before reading on, so that
+// an editor started for a key gets the terminal to itself.
+func readKeys(ctx context.Context, in io.Reader, keys chan<- string, resume <-chan struct{}) {
+	buf := make([]byte, 16)
+	for {
+		n, err := in.Read(buf)
+		if err != nil {
+			return
+		}
+		key := parseKey(buf[:n])
+		if key == "" {
+			continue
+		}
+		select {
+		case keys <- key:
+		case <-ctx.Done():
+			return
+		}
+		select {
+		case <-resume:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// parseKey names the key in a chunk of raw terminal input.
+func parseKey(b []byte) string {
+	switch {
+	case len(b) == 0:
+		return ""
+	case b[0] == 3:
+		return "ctrl+c"
+	case bytes.Equal(b, []byte("\x1b[A")), bytes.Equal(b, []byte("\x1bOA")):
+		return "up"
+	case bytes.Equal(b, []byte("\x1b[B")), bytes.Equal(b, []byte("\x1bOB")):
+		return "down"
+	case len(b) == 1 && b[0] == 0x1b:
+		return "esc"
+	case b[0] == 0x1b:
+		return ""
+	}
+	return string(b[:1])
+}
+
+func init() {
+	rootCmd.AddCommand(uiCmd)
+	uiCmd.Flags().BoolVarP(&uiAllNamespaces, "all-namespaces", "A", false, "Show FrontendPages in every namespace")
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	frontendv1alpha2 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/frontendBackup"
+	frontendv1alpha1 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/v1alpha1"
+	"github.com/silhouetteUA/k8s-controller/pkg/render"
+)
+
+func TestDashboard(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, frontendv1alpha1.AddToScheme(scheme))
+	require.NoError(t, frontendv1alpha2.AddToScheme(scheme))
+	children := map[string]string{render.LabelFrontendPage: "home"}
+	lastBackup := metav1.NewTime(time.Now().Add(-time.Hour))
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&frontendv1alpha1.FrontendPage{
+			ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "web"},
+			Spec:       frontendv1alpha1.FrontendPageSpec{Contents: "<h1>home</h1>", Image: "nginx:1.27", Replicas: 2},
+		},
+		&frontendv1alpha1.FrontendPage{
+			ObjectMeta: metav1.ObjectMeta{Name: "about", Namespace: "web"},
+			Spec:       frontendv1alpha1.FrontendPageSpec{Image: "nginx:1.27", Replicas: 1},
+		},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "web", Labels: children}, Status: appsv1.DeploymentStatus{ReadyReplicas: 1}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "web", Labels: children}, Data: map[string]string{"contents": "<h1>home</h1>"}},
+		&frontendv1alpha2.FrontendPageBackup{
+			ObjectMeta: metav1.ObjectMeta{Name: "home-nightly", Namespace: "web"},
+			Spec:       frontendv1alpha2.FrontendPageBackupSpec{FrontendPageRef: "home", Schedule: "0 2 * * *"},
+			Status:     frontendv1alpha2.FrontendPageBackupStatus{LastBackupTime: &lastBackup, Status: "success"},
+		},
+		&batchv1.CronJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "backup-home", Namespace: "web", UID: "cron-uid"},
+			Spec:       batchv1.CronJobSpec{Schedule: "0 2 * * *", JobTemplate: batchv1.JobTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: children}}},
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "home.1", Namespace: "web"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Deployment", Name: "home"},
+			Reason:         "ScalingReplicaSet", Message: "Scaled up replica set home-abc to 2", Type: "Normal",
+			LastTimestamp: metav1.NewTime(time.Now().Add(-time.Minute)),
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "about.1", Namespace: "web"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Deployment", Name: "about"},
+			Reason:         "ScalingReplicaSet", Type: "Normal",
+		},
+	).Build()
+	ctx := context.Background()
+
+	d := &dashboard{c: c, namespace: "web"}
+	d.reload(ctx)
+	require.Empty(t, d.status)
+	out := dashboardOutput(d.rows, false)
+	require.Equal(t, []string{"about", "0/1", "nginx:1.27", "<none>", "<none>"}, out.Rows[0][:5])
+	require.Equal(t, []string{"home", "1/2", "nginx:1.27", "21 bytes", "success 60m ago"}, out.Rows[1][:5])
+
+	require.False(t, d.handleKey(ctx, "down"))
+	require.False(t, d.handleKey(ctx, "down"), "the selection stops at the last page")
+	require.Equal(t, 1, d.selected)
+	var screen bytes.Buffer
+	require.NoError(t, d.draw(&screen, 40, 10))
+	lines := strings.Split(screen.String(), "\r\n")
+	require.Len(t, lines, 10)
+	require.True(t, strings.HasPrefix(lines[4], reverseVideo+"> home"))
+	require.Equal(t, truncate(uiHelp, 40), lines[9], "lines are cut to the terminal width")
+
+	key := types.NamespacedName{Namespace: "web", Name: "home"}
+	var page frontendv1alpha1.FrontendPage
+	require.False(t, d.handleKey(ctx, "+"))
+	require.Equal(t, `frontendpage "home" scaled to 3`, d.status)
+	require.NoError(t, c.Get(ctx, key, &page))
+	require.Equal(t, 3, page.Spec.Replicas)
+	require.Equal(t, 1, d.selected, "the selection follows the page across reloads")
+
+	d.edit = func(path string) error { return os.WriteFile(path, []byte("<h1>new home</h1>"), 0o600) }
+	require.False(t, d.handleKey(ctx, "e"))
+	require.Equal(t, `frontendpage "home" contents updated`, d.status)
+	require.NoError(t, c.Get(ctx, key, &page))
+	require.Equal(t, "<h1>new home</h1>", page.Spec.Contents)
+
+	require.False(t, d.handleKey(ctx, "b"))
+	require.Contains(t, d.status, `job "backup-home-manual-`)
+	var jobs batchv1.JobList
+	require.NoError(t, c.List(ctx, &jobs, client.InNamespace("web")))
+	require.Len(t, jobs.Items, 1)
+	require.Equal(t, "cron-uid", string(jobs.Items[0].OwnerReferences[0].UID))
+
+	require.False(t, d.handleKey(ctx, "v"))
+	require.NotNil(t, d.events)
+	require.Len(t, d.events.Rows, 1)
+	require.Equal(t, "deployment/home", d.events.Rows[0][3])
+	require.False(t, d.handleKey(ctx, "q"), "q leaves the events first")
+	require.Nil(t, d.events)
+
+	require.False(t, d.handleKey(ctx, "up"))
+	require.False(t, d.handleKey(ctx, "b"))
+	require.Equal(t, `Error: frontendpage "about" has no FrontendPageBackup`, d.status)
+	require.True(t, d.handleKey(ctx, "q"))
+}
+
+func TestParseKey(t *testing.T) {
+	for in, want := range map[string]string{
+		"\x1b[A": "up", "\x1bOB": "down", "\x1b": "esc", "\x03": "ctrl+c", "\x1b[5~": "", "q": "q", "+": "+",
+	} {
+		require.Equal(t, want, parseKey([]byte(in)), "%q", in)
+	}
+}
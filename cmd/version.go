@@ -1,7 +1,7 @@
 package cmd
 
 import (
-	"fmt"
+	"github.com/silhouetteUA/k8s-controller/pkg/printers"
 	cobalias "github.com/spf13/cobra"
 	"runtime"
 )
@@ -17,13 +17,30 @@ var versionCmd = &cobalias.Command{
 	Short: "Prints CLI version",
 	Long:  "Usage: `executable version`",
 	Run: func(cmd *cobalias.Command, args []string) {
-		fmt.Printf("kctl version: %s\n", Version)
-		fmt.Printf("Commit: %s\n", Commit)
-		fmt.Printf("BuildDate: %s\n", BuildDate)
-		fmt.Printf("Go version: %s\n", runtime.Version())
+		info := versionInfo{Version: Version, Commit: Commit, BuildDate: BuildDate, GoVersion: runtime.Version()}
+		printOutput(cmd, printers.Output{
+			Object: info,
+			Columns: []printers.Column{
+				{Header: "VERSION"},
+				{Header: "COMMIT"},
+				{Header: "BUILD DATE"},
+				{Header: "GO VERSION"},
+			},
+			Rows:  [][]string{{info.Version, info.Commit, info.BuildDate, info.GoVersion}},
+			Names: []string{info.Version},
+		})
 	},
 }
 
+// versionInfo is the output of kctl version.
+type versionInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"buildDate"`
+	GoVersion string `json:"goVersion"`
+}
+
 func init() {
 	rootCmd.AddCommand(versionCmd)
+	addOutputFlags(versionCmd)
 }
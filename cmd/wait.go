@@ -0,0 +1,312 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	frontendv1alpha2 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/frontendBackup"
+	frontendv1alpha1 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/v1alpha1"
+)
+
+// Exit codes of wait and rollout status besides 0 (done) and 1 (error), so CI pipelines
+// can tell a slow rollout from a broken one.
+const (
+	exitTimeout       = 2
+	exitRolloutFailed = 3
+)
+
+// exitError makes runWithClient exit with code instead of 1.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+var waitFor string
+var waitTimeout time.Duration
+
+// waitKinds are the resource types kctl wait accepts, by name, plural and short name.
+var waitKinds = map[string]schema.GroupVersionKind{}
+
+func init() {
+	for _, k := range []struct {
+		gvk   schema.GroupVersionKind
+		names []string
+	}{
+		{frontendv1alpha1.SchemeGroupVersion.WithKind("FrontendPage"), []string{"frontendpage", "frontendpages", "fp"}},
+		{frontendv1alpha2.SchemeGroupVersion.WithKind("FrontendPageBackup"), []string{"frontendpagebackup", "frontendpagebackups", "fpb"}},
+		{appsv1.SchemeGroupVersion.WithKind("Deployment"), []string{"deployment", "deployments", "deploy"}},
+	} {
+		for _, name := range k.names {
+			waitKinds[name] = k.gvk
+		}
+	}
+}
+
+var waitCmd = &cobra.Command{
+	Use:   "wait (TYPE/NAME... | TYPE NAME...) --for=condition=COND[=VALUE] | --for=delete",
+	Short: "Wait for FrontendPages, FrontendPageBackups or Deployments to reach a condition",
+	Long: `Watches the objects until each has the condition (status True unless a value is given) for its
+current generation, or is deleted. TYPE is frontendpage (fp), frontendpagebackup (fpb) or
+deployment (deploy).
+
+Exits with 0 once every object is done, 2 when --timeout expires and 1 on any other error, such
+as an object that does not exist when waiting for a condition.`,
+	Example: `  kctl wait fp/home --for=condition=Ready --timeout=2m
+  kctl wait fp home about --for=delete
+  kctl wait deploy/home --for=condition=Available=True`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runWithWatchClient("wait", func(ctx context.Context, c client.WithWatch) error {
+			targets, err := parseWaitTargets(args)
+			if err != nil {
+				return err
+			}
+			cond, err := parseWaitFor(waitFor)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := context.WithTimeout(ctx, waitTimeout)
+			defer cancel()
+			for _, obj := range targets {
+				if err := waitForObject(ctx, c, cmd.OutOrStdout(), obj, cond); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	},
+}
+
+// waitCondition is a parsed --for.
+type waitCondition struct {
+	delete bool
+	// condType and status of a condition=TYPE[=STATUS].
+	condType string
+	status   string
+}
+
+func parseWaitFor(s string) (waitCondition, error) {
+	if s == "delete" {
+		return waitCondition{delete: true}, nil
+	}
+	cond, ok := strings.CutPrefix(s, "condition=")
+	if !ok || cond == "" {
+		return waitCondition{}, fmt.Errorf("--for must be condition=COND[=VALUE] or delete, got %q", s)
+	}
+	condType, status, found := strings.Cut(cond, "=")
+	if !found {
+		status = "True"
+	}
+	return waitCondition{condType: condType, status: status}, nil
+}
+
+// parseWaitTargets turns TYPE/NAME... or TYPE NAME... into objects carrying the kind,
+// namespace and name to wait for.
+func parseWaitTargets(args []string) ([]*unstructured.Unstructured, error) {
+	var targets []*unstructured.Unstructured
+	add := func(kind, name string) error {
+		gvk, ok := waitKinds[strings.ToLower(kind)]
+		if !ok {
+			return fmt.Errorf("unknown resource type %q, use frontendpage, frontendpagebackup or deployment", kind)
+		}
+		if name == "" {
+			return fmt.Errorf("no name given for %s", kind)
+		}
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(gvk)
+		obj.SetNamespace(namespace)
+		obj.SetName(name)
+		targets = append(targets, obj)
+		return nil
+	}
+	if !strings.Contains(args[0], "/") {
+		if len(args) == 1 {
+			return nil, fmt.Errorf("no name given for %s", args[0])
+		}
+		for _, name := range args[1:] {
+			if err := add(args[0], name); err != nil {
+				return nil, err
+			}
+		}
+		return targets, nil
+	}
+	for _, arg := range args {
+		kind, name, ok := strings.Cut(arg, "/")
+		if !ok {
+			return nil, fmt.Errorf("%q is not TYPE/NAME", arg)
+		}
+		if err := add(kind, name); err != nil {
+			return nil, err
+		}
+	}
+	return targets, nil
+}
+
+// waitForObject watches target until cond holds and reports it on out.
+func waitForObject(ctx context.Context, c client.WithWatch, out io.Writer, target *unstructured.Unstructured, cond waitCondition) error {
+	gvk := target.GroupVersionKind()
+	ref := resourceName(gvk.Kind, gvk.Group, target.GetName())
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	key := client.ObjectKeyFromObject(target)
+
+	var uid types.UID
+	err := waitUntil(ctx, c, []watchTarget{{list, key}}, func(ctx context.Context) (bool, error) {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(gvk)
+		err := c.Get(ctx, key, obj)
+		if cond.delete {
+			if apierrors.IsNotFound(err) {
+				return true, nil
+			}
+			if err != nil {
+				return false, err
+			}
+			// An object recreated under the same name counts as deleted.
+			if uid == "" {
+				uid = obj.GetUID()
+			}
+			return obj.GetUID() != uid, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return conditionMet(obj, cond.condType, cond.status), nil
+	})
+	if err != nil {
+		return timeoutError(err, "waiting for %s", ref)
+	}
+	if cond.delete {
+		_, err = fmt.Fprintf(out, "%s deleted\n", ref)
+	} else {
+		_, err = fmt.Fprintf(out, "%s condition met\n", ref)
+	}
+	return err
+}
+
+// conditionMet reports whether obj has the condition with the given status, ignoring
+// conditions the controller set for an older generation of the spec.
+func conditionMet(obj *unstructured.Unstructured, condType, status string) bool {
+	if observed, found, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration"); found && observed < obj.GetGeneration() {
+		return false
+	}
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		cond, ok := c.(map[string]any)
+		if !ok || !strings.EqualFold(fmt.Sprint(cond["type"]), condType) {
+			continue
+		}
+		if observed, ok := cond["observedGeneration"].(int64); ok && observed < obj.GetGeneration() {
+			return false
+		}
+		return strings.EqualFold(fmt.Sprint(cond["status"]), status)
+	}
+	return false
+}
+
+// timeoutError turns an expired deadline into an exitTimeout error.
+func timeoutError(err error, format string, args ...any) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &exitError{code: exitTimeout, err: fmt.Errorf("timed out "+format, args...)}
+	}
+	return err
+}
+
+// watchTarget is an object waitUntil watches, by the list type of its kind and its key. An
+// empty name watches every object of the kind in the namespace.
+type watchTarget struct {
+	list client.ObjectList
+	key  types.NamespacedName
+}
+
+// waitUntil calls check now and again whenever one of the targets changes, until check
+// reports done or fails, or ctx ends. Watches the server closes are reopened, with a check
+// in between as events may have been missed.
+func waitUntil(ctx context.Context, c client.WithWatch, targets []watchTarget, check func(context.Context) (bool, error)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	changed := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+	errs := make(chan error, len(targets))
+	for _, t := range targets {
+		// The first watch is opened before the first check so that no change is missed.
+		w, err := watchObject(ctx, c, t)
+		if err != nil {
+			return err
+		}
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					w.Stop()
+					return
+				case _, ok := <-w.ResultChan():
+					if ok {
+						notify()
+						continue
+					}
+				}
+				w.Stop()
+				notify()
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(time.Second):
+				}
+				if w, err = watchObject(ctx, c, t); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+
+	for {
+		done, err := check(ctx)
+		if err != nil || done {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-errs:
+			return err
+		case <-changed:
+		}
+	}
+}
+
+func watchObject(ctx context.Context, c client.WithWatch, t watchTarget) (watch.Interface, error) {
+	if t.key.Name == "" {
+		return c.Watch(ctx, t.list, client.InNamespace(t.key.Namespace))
+	}
+	return c.Watch(ctx, t.list, client.InNamespace(t.key.Namespace), client.MatchingFields{"metadata.name": t.key.Name})
+}
+
+func init() {
+	rootCmd.AddCommand(waitCmd)
+	waitCmd.Flags().StringVar(&waitFor, "for", "", "condition=COND[=VALUE] or delete")
+	waitCmd.Flags().DurationVar(&waitTimeout, "timeout", 30*time.Second, "How long to wait before giving up")
+	_ = waitCmd.MarkFlagRequired("for")
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	frontendv1alpha1 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/v1alpha1"
+)
+
+func TestParseWait(t *testing.T) {
+	cond, err := parseWaitFor("condition=Ready")
+	require.NoError(t, err)
+	require.Equal(t, waitCondition{condType: "Ready", status: "True"}, cond)
+	cond, err = parseWaitFor("condition=Available=false")
+	require.NoError(t, err)
+	require.Equal(t, waitCondition{condType: "Available", status: "false"}, cond)
+	cond, err = parseWaitFor("delete")
+	require.NoError(t, err)
+	require.True(t, cond.delete)
+	for _, bad := range []string{"", "Ready", "condition="} {
+		_, err := parseWaitFor(bad)
+		require.Error(t, err, bad)
+	}
+
+	namespace = "web"
+	targets, err := parseWaitTargets([]string{"fp/home", "deploy/api"})
+	require.NoError(t, err)
+	require.Equal(t, "FrontendPage", targets[0].GetKind())
+	require.Equal(t, "web", targets[0].GetNamespace())
+	require.Equal(t, "Deployment", targets[1].GetKind())
+	targets, err = parseWaitTargets([]string{"frontendpagebackups", "nightly", "weekly"})
+	require.NoError(t, err)
+	require.Len(t, targets, 2)
+	require.Equal(t, "FrontendPageBackup", targets[1].GetKind())
+	for _, bad := range [][]string{{"fp"}, {"pod/x"}, {"fp/"}, {"fp/home", "about"}} {
+		_, err := parseWaitTargets(bad)
+		require.Error(t, err, bad)
+	}
+}
+
+func TestConditionMet(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"metadata": map[string]any{"generation": int64(2)},
+		"status": map[string]any{
+			"observedGeneration": int64(2),
+			"conditions": []any{
+				map[string]any{"type": "Ready", "status": "True", "observedGeneration": int64(2)},
+				map[string]any{"type": "Available", "status": "False"},
+			},
+		},
+	}}
+	require.True(t, conditionMet(obj, "Ready", "True"))
+	require.True(t, conditionMet(obj, "ready", "true"))
+	require.False(t, conditionMet(obj, "Available", "True"))
+	require.True(t, conditionMet(obj, "Available", "False"))
+	require.False(t, conditionMet(obj, "Progressing", "True"))
+
+	obj.SetGeneration(3)
+	require.False(t, conditionMet(obj, "Ready", "True"), "the status describes an older spec")
+}
+
+func waitClient(t *testing.T, objs ...client.Object) client.WithWatch {
+	s := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(s))
+	require.NoError(t, frontendv1alpha1.AddToScheme(s))
+	return fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build()
+}
+
+func TestWaitForObject(t *testing.T) {
+	page := &frontendv1alpha1.FrontendPage{ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "default"}}
+	c := waitClient(t, page)
+	target := &unstructured.Unstructured{}
+	target.SetGroupVersionKind(frontendv1alpha1.SchemeGroupVersion.WithKind("FrontendPage"))
+	target.SetNamespace("default")
+	target.SetName("home")
+	ctx := context.Background()
+	var out bytes.Buffer
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		page.Status.Conditions = []metav1.Condition{{Type: frontendv1alpha1.ConditionReady, Status: metav1.ConditionTrue}}
+		_ = c.Update(ctx, page)
+	}()
+	require.NoError(t, waitForObject(ctx, c, &out, target, waitCondition{condType: "Ready", status: "True"}))
+	require.Equal(t, "frontendpage.frontendpage.silhouetteua.io/home condition met\n", out.String())
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_ = c.Delete(ctx, page)
+	}()
+	out.Reset()
+	require.NoError(t, waitForObject(ctx, c, &out, target, waitCondition{delete: true}))
+	require.Equal(t, "frontendpage.frontendpage.silhouetteua.io/home deleted\n", out.String())
+
+	timeout, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	err := waitForObject(timeout, c, &out, target, waitCondition{condType: "Ready", status: "True"})
+	require.Error(t, err, "the page no longer exists")
+	var exit *exitError
+	require.False(t, errors.As(err, &exit))
+
+	require.NoError(t, c.Create(ctx, &frontendv1alpha1.FrontendPage{ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "default"}}))
+	err = waitForObject(timeout, c, &out, target, waitCondition{condType: "Ready", status: "True"})
+	require.ErrorAs(t, err, &exit)
+	require.Equal(t, exitTimeout, exit.code)
+}
+
+func TestRolloutStatus(t *testing.T) {
+	replicas := int32(2)
+	page := &frontendv1alpha1.FrontendPage{
+		ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "default", Generation: 2},
+		Status:     frontendv1alpha1.FrontendPageStatus{ObservedGeneration: 1},
+	}
+	dep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "default", Generation: 1},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+	}
+	c := waitClient(t, page, dep)
+	key := types.NamespacedName{Namespace: "default", Name: "home"}
+	ctx := context.Background()
+
+	// Play the controller: observe the spec, then roll the Deployment out.
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		page.Status.ObservedGeneration = 2
+		_ = c.Update(ctx, page)
+		time.Sleep(100 * time.Millisecond)
+		dep.Status = appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 1}
+		_ = c.Status().Update(ctx, dep)
+		time.Sleep(100 * time.Millisecond)
+		dep.Status.AvailableReplicas = 2
+		_ = c.Status().Update(ctx, dep)
+		page.Status.Conditions = []metav1.Condition{{Type: frontendv1alpha1.ConditionReady, Status: metav1.ConditionTrue, ObservedGeneration: 2}}
+		_ = c.Update(ctx, page)
+	}()
+	var out bytes.Buffer
+	require.NoError(t, rolloutStatus(ctx, c, &out, key))
+	require.Contains(t, out.String(), "Waiting for frontendpage spec update to be observed by the controller\n")
+	require.Contains(t, out.String(), "Waiting for rollout to finish: 1 of 2 updated replicas are available\n")
+	require.Contains(t, out.String(), `frontendpage "home" successfully rolled out`)
+
+	dep.Status.Conditions = []appsv1.DeploymentCondition{{Type: appsv1.DeploymentProgressing, Status: "False", Reason: "ProgressDeadlineExceeded"}}
+	require.NoError(t, c.Status().Update(ctx, dep))
+	err := rolloutStatus(ctx, c, &out, key)
+	var exit *exitError
+	require.ErrorAs(t, err, &exit)
+	require.Equal(t, exitRolloutFailed, exit.code)
+}
@@ -0,0 +1,10 @@
+// Package crd embeds the CustomResourceDefinitions generated by controller-gen for
+// FrontendPage and FrontendPageBackup, so kctl install can apply them.
+package crd
+
+import "embed"
+
+// Files holds the CRD manifests, one per file.
+//
+//go:embed frontendpage.silhouetteua.io_*.yaml
+var Files embed.FS
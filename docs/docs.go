@@ -0,0 +1,10 @@
+// Package docs embeds the OpenAPI (Swagger 2.0) document generated by swag
+// from the annotations in pkg/api. Regenerate it with `make swagger`.
+package docs
+
+import _ "embed"
+
+// SwaggerJSON is the generated OpenAPI document served at /openapi.json.
+//
+//go:embed swagger.json
+var SwaggerJSON []byte
@@ -2,6 +2,13 @@ package main
 
 import "github.com/silhouetteUA/k8s-controller/cmd"
 
+// @title kctl FrontendPage API
+// @version 1.0
+// @description REST API served by `kctl server` for managing FrontendPage resources.
+// @BasePath /
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
 func main() {
 
 	cmd.Execute()
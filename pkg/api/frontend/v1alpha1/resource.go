@@ -11,6 +11,24 @@ type FrontendPageSpec struct {
 	Replicas int    `json:"replicas"`
 }
 
+// ConditionReady is the FrontendPage condition that is True once the page's Deployment has
+// rolled out the current spec.
+const ConditionReady = "Ready"
+
+// Reasons of the Ready condition.
+const (
+	ReasonRolloutComplete   = "RolloutComplete"
+	ReasonRolloutInProgress = "RolloutInProgress"
+	ReasonRolloutFailed     = "RolloutFailed"
+)
+
+// FrontendPageStatus defines the observed state of FrontendPage
+type FrontendPageStatus struct {
+	// ObservedGeneration is the generation of the spec the conditions describe.
+	ObservedGeneration int64              `json:"observedGeneration,omitempty"`
+	Conditions         []metav1.Condition `json:"conditions,omitempty"`
+}
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:shortName=fp,singular=frontendpage,path=frontendpages,scope=Namespaced
@@ -18,7 +36,8 @@ type FrontendPage struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec FrontendPageSpec `json:"spec"`
+	Spec   FrontendPageSpec   `json:"spec"`
+	Status FrontendPageStatus `json:"status,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -5,33 +5,180 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/rs/zerolog/log"
 	"github.com/valyala/fasthttp"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	frontendv1alpha1 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/v1alpha1"
+	"github.com/silhouetteUA/k8s-controller/pkg/auth"
+	"github.com/silhouetteUA/k8s-controller/pkg/render"
+	"github.com/silhouetteUA/k8s-controller/pkg/scope"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // FrontendPageAPI provides handlers for FrontendPage resources.
 type FrontendPageAPI struct {
 	K8sClient client.Client
-	Namespace string // default namespace for simplicity
+	// Scope limits the namespaces requests may target with ?namespace=; requests without
+	// one use the scope's default namespace. Nil allows any namespace.
+	Scope *scope.Scope
+	// Authorizer, when set, checks each call against the caller's RBAC before using K8sClient.
+	Authorizer auth.Authorizer
+	// Impersonator, when set, performs each call through a client impersonating the caller.
+	Impersonator *auth.ImpersonatingClientFactory
+}
+
+// clientFor resolves the request's namespace, authorizes the request for verb on the named
+// FrontendPage (empty for collections) there and returns the client to perform it with.
+// On failure it writes the response and returns a nil client.
+func (api *FrontendPageAPI) clientFor(ctx *fasthttp.RequestCtx, verb, name string) (client.Client, string) {
+	namespace, ok := requestNamespace(ctx, api.Scope)
+	if !ok {
+		return nil, ""
+	}
+	if api.Authorizer == nil && api.Impersonator == nil {
+		return api.K8sClient, namespace
+	}
+	user, ok := auth.UserFrom(ctx)
+	if !ok {
+		ctx.SetStatusCode(fasthttp.StatusUnauthorized)
+		ctx.SetBodyString(`{"error":"unauthorized"}`)
+		return nil, ""
+	}
+	if api.Impersonator != nil {
+		c, err := api.Impersonator.ClientFor(user)
+		if err != nil {
+			writeError(ctx, err, fasthttp.StatusInternalServerError)
+			return nil, ""
+		}
+		return c, namespace
+	}
+	allowed := authorize(ctx, api.Authorizer, auth.ResourceAttributes{
+		Verb:      verb,
+		Group:     frontendv1alpha1.SchemeGroupVersion.Group,
+		Version:   frontendv1alpha1.SchemeGroupVersion.Version,
+		Resource:  "frontendpages",
+		Namespace: namespace,
+		Name:      name,
+	})
+	if !allowed {
+		return nil, ""
+	}
+	return api.K8sClient, namespace
+}
+
+// authorize checks attrs for the authenticated caller. A nil authorizer allows everything.
+// On denial or failure it writes the response and returns false.
+func authorize(ctx *fasthttp.RequestCtx, authorizer auth.Authorizer, attrs auth.ResourceAttributes) bool {
+	if authorizer == nil {
+		return true
+	}
+	user, ok := auth.UserFrom(ctx)
+	if !ok {
+		ctx.SetStatusCode(fasthttp.StatusUnauthorized)
+		ctx.SetBodyString(`{"error":"unauthorized"}`)
+		return false
+	}
+	decision, err := authorizer.Authorize(context.Background(), user, attrs)
+	if err != nil {
+		writeError(ctx, err, fasthttp.StatusInternalServerError)
+		return false
+	}
+	if !decision.Allowed {
+		log.Warn().Str("user", user.Username).Str("verb", attrs.Verb).Str("resource", attrs.Resource).Str("name", attrs.Name).
+			Msgf("Request denied: %s", decision.Reason)
+		writeForbidden(ctx, decision.Reason)
+		return false
+	}
+	return true
+}
+
+// writeError maps a Kubernetes API error to a response, using fallback for anything
+// other than an RBAC denial.
+func writeError(ctx *fasthttp.RequestCtx, err error, fallback int) {
+	if apierrors.IsForbidden(err) {
+		writeForbidden(ctx, err.Error())
+		return
+	}
+	writeJSONError(ctx, fallback, err.Error())
+}
+
+// writeJSONError writes {"error": msg} with status.
+func writeJSONError(ctx *fasthttp.RequestCtx, status int, msg string) {
+	body, _ := json.Marshal(map[string]string{"error": msg})
+	ctx.SetContentType("application/json")
+	ctx.SetStatusCode(status)
+	ctx.SetBody(body)
+}
+
+func writeForbidden(ctx *fasthttp.RequestCtx, reason string) {
+	body, _ := json.Marshal(map[string]string{"error": "forbidden", "reason": reason})
+	ctx.SetContentType("application/json")
+	ctx.SetStatusCode(fasthttp.StatusForbidden)
+	ctx.SetBody(body)
 }
 
 // --- Swagger-only structs for documentation ---
-// FrontendPageDoc is a simplified version for Swagger docs
-// @Description FrontendPage resource (Swagger only)
-type FrontendPageDoc struct {
-	Name     string `json:"name" example:"example-page"`
+// These mirror the JSON shape of frontendv1alpha1.FrontendPage so the generated
+// OpenAPI document can be used to build typed clients. TestDocStructsMatchTypes
+// fails when they drift from the real types.
+
+// FrontendPageSpecDoc mirrors frontendv1alpha1.FrontendPageSpec
+// @Description Desired state of a FrontendPage (Swagger only)
+type FrontendPageSpecDoc struct {
 	Contents string `json:"contents" example:"<h1>Hello</h1>"`
 	Image    string `json:"image" example:"nginx:latest"`
 	Replicas int    `json:"replicas" example:"2"`
 }
 
-// FrontendPageListDoc is a list of FrontendPageDoc
-// @Description List of FrontendPage resources (Swagger only)
-type FrontendPageListDoc struct {
-	Items []FrontendPageDoc `json:"items"`
+// ConditionDoc mirrors metav1.Condition
+// @Description Status condition (Swagger only)
+type ConditionDoc struct {
+	Type               string `json:"type" example:"Ready"`
+	Status             string `json:"status" enums:"True,False,Unknown" example:"True"`
+	ObservedGeneration int64  `json:"observedGeneration,omitempty" example:"3"`
+	LastTransitionTime string `json:"lastTransitionTime" format:"date-time"`
+	Reason             string `json:"reason" example:"RolloutComplete"`
+	Message            string `json:"message" example:"deployment \"home\" successfully rolled out"`
+}
+
+// FrontendPageStatusDoc mirrors frontendv1alpha1.FrontendPageStatus
+// @Description Observed state of a FrontendPage, set by the controller (Swagger only)
+type FrontendPageStatusDoc struct {
+	ObservedGeneration int64          `json:"observedGeneration,omitempty" example:"3"`
+	Conditions         []ConditionDoc `json:"conditions,omitempty"`
+}
+
+// ObjectMetaDoc is the subset of metav1.ObjectMeta relevant to API clients
+// @Description Object metadata (Swagger only)
+type ObjectMetaDoc struct {
+	Name              string            `json:"name" example:"example-page"`
+	Namespace         string            `json:"namespace,omitempty" example:"default"`
+	UID               string            `json:"uid,omitempty"`
+	ResourceVersion   string            `json:"resourceVersion,omitempty"`
+	Generation        int64             `json:"generation,omitempty"`
+	CreationTimestamp string            `json:"creationTimestamp,omitempty" format:"date-time"`
+	Labels            map[string]string `json:"labels,omitempty"`
+	Annotations       map[string]string `json:"annotations,omitempty"`
+}
+
+// FrontendPageDoc mirrors frontendv1alpha1.FrontendPage
+// @Description FrontendPage resource (Swagger only)
+type FrontendPageDoc struct {
+	APIVersion string                `json:"apiVersion" example:"frontendpage.silhouetteua.io/v1alpha1"`
+	Kind       string                `json:"kind" example:"FrontendPage"`
+	Metadata   ObjectMetaDoc         `json:"metadata"`
+	Spec       FrontendPageSpecDoc   `json:"spec"`
+	Status     FrontendPageStatusDoc `json:"status,omitempty"`
+}
+
+// FrontendPageUpdateDoc is the body accepted by UpdateFrontendPage
+// @Description FrontendPage update request, only the spec is applied (Swagger only)
+type FrontendPageUpdateDoc struct {
+	Spec FrontendPageSpecDoc `json:"spec"`
 }
 
 // ListFrontendPages godoc
@@ -39,14 +186,23 @@ type FrontendPageListDoc struct {
 // @Description Get all FrontendPage resources
 // @Tags frontendpages
 // @Produce json
-// @Success 200 {object} FrontendPageListDoc
+// @Param namespace query string false "Namespace; defaults to the first watched namespace"
+// @Success 200 {array} FrontendPageDoc
+// @Failure 500 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Security BearerAuth
 // @Router /api/frontendpages [get]
 func (api *FrontendPageAPI) ListFrontendPages(ctx *fasthttp.RequestCtx) {
+	c, namespace := api.clientFor(ctx, "list", "")
+	if c == nil {
+		return
+	}
 	list := &frontendv1alpha1.FrontendPageList{}
-	err := api.K8sClient.List(context.Background(), list, client.InNamespace(api.Namespace))
+	err := c.List(context.Background(), list, client.InNamespace(namespace))
 	if err != nil {
-		ctx.SetStatusCode(fasthttp.StatusInternalServerError)
-		ctx.SetBodyString(fmt.Sprintf(`{"error":"%v"}`, err))
+		writeError(ctx, err, fasthttp.StatusInternalServerError)
 		return
 	}
 	ctx.SetContentType("application/json")
@@ -62,8 +218,12 @@ func (api *FrontendPageAPI) ListFrontendPages(ctx *fasthttp.RequestCtx) {
 // @Tags frontendpages
 // @Produce json
 // @Param name path string true "FrontendPage name"
+// @Param namespace query string false "Namespace; defaults to the first watched namespace"
 // @Success 200 {object} FrontendPageDoc
 // @Failure 404 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Security BearerAuth
 // @Router /api/frontendpages/{name} [get]
 func (api *FrontendPageAPI) GetFrontendPage(ctx *fasthttp.RequestCtx) {
 	nameVal := ctx.UserValue("name")
@@ -73,11 +233,14 @@ func (api *FrontendPageAPI) GetFrontendPage(ctx *fasthttp.RequestCtx) {
 		return
 	}
 	name := nameVal.(string)
+	c, namespace := api.clientFor(ctx, "get", name)
+	if c == nil {
+		return
+	}
 	obj := &frontendv1alpha1.FrontendPage{}
-	err := api.K8sClient.Get(context.Background(), client.ObjectKey{Namespace: api.Namespace, Name: name}, obj)
+	err := c.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: name}, obj)
 	if err != nil {
-		ctx.SetStatusCode(fasthttp.StatusNotFound)
-		ctx.SetBodyString(fmt.Sprintf(`{"error":"%v"}`, err))
+		writeError(ctx, err, fasthttp.StatusNotFound)
 		return
 	}
 	ctx.SetContentType("application/json")
@@ -94,8 +257,13 @@ func (api *FrontendPageAPI) GetFrontendPage(ctx *fasthttp.RequestCtx) {
 // @Accept json
 // @Produce json
 // @Param body body FrontendPageDoc true "FrontendPage object"
+// @Param namespace query string false "Namespace; defaults to the first watched namespace"
 // @Success 201 {object} FrontendPageDoc
 // @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Security BearerAuth
 // @Router /api/frontendpages [post]
 func (api *FrontendPageAPI) CreateFrontendPage(ctx *fasthttp.RequestCtx) {
 	obj := &frontendv1alpha1.FrontendPage{}
@@ -104,11 +272,17 @@ func (api *FrontendPageAPI) CreateFrontendPage(ctx *fasthttp.RequestCtx) {
 		ctx.SetBodyString(fmt.Sprintf(`{"error":"%v"}`, err))
 		return
 	}
-	// Set namespace before creation
-	obj.Namespace = api.Namespace
-	if err := api.K8sClient.Create(context.Background(), obj); err != nil {
-		ctx.SetStatusCode(fasthttp.StatusInternalServerError)
-		ctx.SetBodyString(fmt.Sprintf(`{"error":"%v"}`, err))
+	c, namespace := api.clientFor(ctx, "create", "")
+	if c == nil {
+		return
+	}
+	if obj.Namespace != "" && obj.Namespace != namespace {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("metadata.namespace %q does not match the request namespace %q", obj.Namespace, namespace))
+		return
+	}
+	obj.Namespace = namespace
+	if err := c.Create(context.Background(), obj); err != nil {
+		writeError(ctx, err, fasthttp.StatusInternalServerError)
 		return
 	}
 	ctx.SetStatusCode(fasthttp.StatusCreated)
@@ -126,9 +300,14 @@ func (api *FrontendPageAPI) CreateFrontendPage(ctx *fasthttp.RequestCtx) {
 // @Accept json
 // @Produce json
 // @Param name path string true "FrontendPage name"
-// @Param body body FrontendPageDoc true "FrontendPage object"
+// @Param body body FrontendPageUpdateDoc true "FrontendPage spec"
+// @Param namespace query string false "Namespace; defaults to the first watched namespace"
 // @Success 200 {object} FrontendPageDoc
 // @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Security BearerAuth
 // @Router /api/frontendpages/{name} [put]
 func (api *FrontendPageAPI) UpdateFrontendPage(ctx *fasthttp.RequestCtx) {
 	nameVal := ctx.UserValue("name")
@@ -138,13 +317,16 @@ func (api *FrontendPageAPI) UpdateFrontendPage(ctx *fasthttp.RequestCtx) {
 		return
 	}
 	name := nameVal.(string)
+	c, namespace := api.clientFor(ctx, "update", name)
+	if c == nil {
+		return
+	}
 
 	// Fetch the existing object to get the current resourceVersion
 	existing := &frontendv1alpha1.FrontendPage{}
-	err := api.K8sClient.Get(context.Background(), client.ObjectKey{Namespace: api.Namespace, Name: name}, existing)
+	err := c.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: name}, existing)
 	if err != nil {
-		ctx.SetStatusCode(fasthttp.StatusNotFound)
-		ctx.SetBodyString(fmt.Sprintf(`{"error":"%v"}`, err))
+		writeError(ctx, err, fasthttp.StatusNotFound)
 		return
 	}
 
@@ -159,9 +341,8 @@ func (api *FrontendPageAPI) UpdateFrontendPage(ctx *fasthttp.RequestCtx) {
 	}
 	existing.Spec = patch.Spec
 
-	if err := api.K8sClient.Update(context.Background(), existing); err != nil {
-		ctx.SetStatusCode(fasthttp.StatusInternalServerError)
-		ctx.SetBodyString(fmt.Sprintf(`{"error":"%v"}`, err))
+	if err := c.Update(context.Background(), existing); err != nil {
+		writeError(ctx, err, fasthttp.StatusInternalServerError)
 		return
 	}
 	ctx.SetContentType("application/json")
@@ -176,8 +357,12 @@ func (api *FrontendPageAPI) UpdateFrontendPage(ctx *fasthttp.RequestCtx) {
 // @Description Delete a FrontendPage by name
 // @Tags frontendpages
 // @Param name path string true "FrontendPage name"
+// @Param namespace query string false "Namespace; defaults to the first watched namespace"
 // @Success 204 {object} nil
 // @Failure 404 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Security BearerAuth
 // @Router /api/frontendpages/{name} [delete]
 func (api *FrontendPageAPI) DeleteFrontendPage(ctx *fasthttp.RequestCtx) {
 	nameVal := ctx.UserValue("name")
@@ -187,16 +372,89 @@ func (api *FrontendPageAPI) DeleteFrontendPage(ctx *fasthttp.RequestCtx) {
 		return
 	}
 	name := nameVal.(string)
+	c, namespace := api.clientFor(ctx, "delete", name)
+	if c == nil {
+		return
+	}
 	obj := &frontendv1alpha1.FrontendPage{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
-			Namespace: api.Namespace,
+			Namespace: namespace,
 		},
 	}
-	if err := api.K8sClient.Delete(context.Background(), obj); err != nil {
-		ctx.SetStatusCode(fasthttp.StatusNotFound)
-		ctx.SetBodyString(fmt.Sprintf(`{"error":"%v"}`, err))
+	if err := c.Delete(context.Background(), obj); err != nil {
+		writeError(ctx, err, fasthttp.StatusNotFound)
 		return
 	}
 	ctx.SetStatusCode(fasthttp.StatusNoContent)
 }
+
+// RenderListDoc is the response of RenderFrontendPages
+// @Description Objects the controller would create, as a v1 List (Swagger only)
+type RenderListDoc struct {
+	APIVersion string           `json:"apiVersion" example:"v1"`
+	Kind       string           `json:"kind" example:"List"`
+	Items      []map[string]any `json:"items"`
+}
+
+// RenderFrontendPages godoc
+// @Summary Render the objects of FrontendPages
+// @Description Build the ConfigMap, Deployment and backup CronJobs the controller would create for a FrontendPage, without touching the cluster. The body is a FrontendPage, or a v1 List of FrontendPages and the FrontendPageBackups that reference them.
+// @Tags frontendpages
+// @Accept json
+// @Produce json
+// @Param body body FrontendPageDoc true "FrontendPage object, or a List of FrontendPages and FrontendPageBackups"
+// @Param namespace query string false "Namespace; defaults to the first watched namespace"
+// @Success 200 {object} RenderListDoc
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/frontendpages/render [post]
+func (api *FrontendPageAPI) RenderFrontendPages(ctx *fasthttp.RequestCtx) {
+	body := &unstructured.Unstructured{}
+	if err := body.UnmarshalJSON(ctx.PostBody()); err != nil {
+		writeError(ctx, err, fasthttp.StatusBadRequest)
+		return
+	}
+	namespace, ok := requestNamespace(ctx, api.Scope)
+	if !ok {
+		return
+	}
+	var objs []*unstructured.Unstructured
+	if body.IsList() {
+		_ = body.EachListItem(func(obj runtime.Object) error {
+			objs = append(objs, obj.(*unstructured.Unstructured))
+			return nil
+		})
+	} else {
+		objs = append(objs, body)
+	}
+	for _, obj := range objs {
+		if obj.GetNamespace() != "" && obj.GetNamespace() != namespace {
+			writeJSONError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("metadata.namespace %q of %q does not match the request namespace %q", obj.GetNamespace(), obj.GetName(), namespace))
+			return
+		}
+	}
+	inputs, err := render.Inputs(objs, namespace)
+	if err != nil {
+		writeError(ctx, err, fasthttp.StatusBadRequest)
+		return
+	}
+	items := []any{}
+	for _, in := range inputs {
+		manifests, err := render.Manifests(in.Page, in.Backups)
+		if err != nil {
+			writeError(ctx, err, fasthttp.StatusBadRequest)
+			return
+		}
+		for _, m := range manifests {
+			items = append(items, m.Object)
+		}
+	}
+	ctx.SetContentType("application/json")
+	err = json.NewEncoder(ctx).Encode(map[string]any{"apiVersion": "v1", "kind": "List", "items": items})
+	if err != nil {
+		return
+	}
+}
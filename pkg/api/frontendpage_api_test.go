@@ -0,0 +1,218 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/silhouetteUA/k8s-controller/docs"
+	frontendv1alpha1 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/v1alpha1"
+	"github.com/silhouetteUA/k8s-controller/pkg/auth"
+	"github.com/silhouetteUA/k8s-controller/pkg/policy"
+	"github.com/silhouetteUA/k8s-controller/pkg/rollout"
+	"github.com/silhouetteUA/k8s-controller/pkg/scope"
+)
+
+// jsonFields returns the JSON field names of a struct type, flattening inline embedded structs.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" || f.Tag.Get("swaggerignore") == "true" {
+			continue
+		}
+		if name == "" && f.Anonymous {
+			for k, v := range jsonFields(f.Type) {
+				fields[k] = v
+			}
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+func keys(m map[string]reflect.Type) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestDocStructsMatchTypes(t *testing.T) {
+	require.Equal(t,
+		keys(jsonFields(reflect.TypeOf(frontendv1alpha1.FrontendPage{}))),
+		keys(jsonFields(reflect.TypeOf(FrontendPageDoc{}))),
+		"FrontendPageDoc fields drifted from FrontendPage")
+
+	spec := jsonFields(reflect.TypeOf(frontendv1alpha1.FrontendPageSpec{}))
+	specDoc := jsonFields(reflect.TypeOf(FrontendPageSpecDoc{}))
+	require.Equal(t, keys(spec), keys(specDoc), "FrontendPageSpecDoc fields drifted from FrontendPageSpec")
+	for name, typ := range spec {
+		require.Equal(t, typ.Kind(), specDoc[name].Kind(), "FrontendPageSpecDoc.%s has a different type", name)
+	}
+	require.Equal(t,
+		keys(jsonFields(reflect.TypeOf(frontendv1alpha1.FrontendPageStatus{}))),
+		keys(jsonFields(reflect.TypeOf(FrontendPageStatusDoc{}))),
+		"FrontendPageStatusDoc fields drifted from FrontendPageStatus")
+	require.Equal(t,
+		keys(jsonFields(reflect.TypeOf(metav1.Condition{}))),
+		keys(jsonFields(reflect.TypeOf(ConditionDoc{}))),
+		"ConditionDoc fields drifted from metav1.Condition")
+
+	meta := jsonFields(reflect.TypeOf(metav1.ObjectMeta{}))
+	for name := range jsonFields(reflect.TypeOf(ObjectMetaDoc{})) {
+		require.Contains(t, meta, name, "ObjectMetaDoc.%s does not exist in metav1.ObjectMeta", name)
+	}
+}
+
+func TestSwaggerDefinitionsMatchDocStructs(t *testing.T) {
+	var spec struct {
+		Definitions map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"definitions"`
+	}
+	require.NoError(t, json.Unmarshal(docs.SwaggerJSON, &spec))
+
+	for name, typ := range map[string]reflect.Type{
+		"api.FrontendPageDoc":       reflect.TypeOf(FrontendPageDoc{}),
+		"api.FrontendPageSpecDoc":   reflect.TypeOf(FrontendPageSpecDoc{}),
+		"api.FrontendPageStatusDoc": reflect.TypeOf(FrontendPageStatusDoc{}),
+		"api.ConditionDoc":          reflect.TypeOf(ConditionDoc{}),
+		"api.FrontendPageUpdateDoc": reflect.TypeOf(FrontendPageUpdateDoc{}),
+		"api.ObjectMetaDoc":         reflect.TypeOf(ObjectMetaDoc{}),
+		"api.DeploymentSummary":     reflect.TypeOf(DeploymentSummary{}),
+		"api.DeploymentDetail":      reflect.TypeOf(DeploymentDetail{}),
+		"api.SecretSummary":         reflect.TypeOf(SecretSummary{}),
+		"api.WatchedResourceDoc":    reflect.TypeOf(WatchedResourceDoc{}),
+		"api.DeploymentReportDoc":   reflect.TypeOf(DeploymentReportDoc{}),
+		"api.RenderListDoc":         reflect.TypeOf(RenderListDoc{}),
+		"rollout.Status":            reflect.TypeOf(rollout.Status{}),
+		"policy.Report":             reflect.TypeOf(policy.Report{}),
+		"policy.CheckResult":        reflect.TypeOf(policy.CheckResult{}),
+		"policy.Summary":            reflect.TypeOf(policy.Summary{}),
+	} {
+		def, ok := spec.Definitions[name]
+		require.True(t, ok, "definition %s missing from docs/swagger.json, run `make swagger`", name)
+		var props []string
+		for p := range def.Properties {
+			props = append(props, p)
+		}
+		sort.Strings(props)
+		require.Equal(t, keys(jsonFields(typ)), props, "definition %s is stale, run `make swagger`", name)
+	}
+}
+
+func TestServeOpenAPI(t *testing.T) {
+	ctx := &fasthttp.RequestCtx{}
+	ServeOpenAPI(ctx)
+	require.Equal(t, "application/json", string(ctx.Response.Header.ContentType()))
+	require.True(t, json.Valid(ctx.Response.Body()))
+
+	ctx = &fasthttp.RequestCtx{}
+	ServeSwaggerUI(ctx)
+	require.Contains(t, string(ctx.Response.Body()), "/openapi.json")
+}
+
+type staticAuthenticator struct{ user *auth.UserInfo }
+
+func (s staticAuthenticator) Authenticate(*fasthttp.RequestCtx) (*auth.UserInfo, error) {
+	return s.user, nil
+}
+
+type verbAuthorizer struct{ allowed map[string]bool }
+
+func (v verbAuthorizer) Authorize(_ context.Context, _ *auth.UserInfo, attrs auth.ResourceAttributes) (auth.Decision, error) {
+	if v.allowed[attrs.Verb] {
+		return auth.Decision{Allowed: true}, nil
+	}
+	return auth.Decision{Reason: "RBAC: cannot " + attrs.Verb + " frontendpages"}, nil
+}
+
+func TestFrontendPageAPI_Authorization(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, frontendv1alpha1.AddToScheme(scheme))
+	page := &frontendv1alpha1.FrontendPage{
+		ObjectMeta: metav1.ObjectMeta{Name: "page", Namespace: "default"},
+		Spec:       frontendv1alpha1.FrontendPageSpec{Contents: "hi", Image: "nginx", Replicas: 1},
+	}
+	api := &FrontendPageAPI{
+		K8sClient:  fake.NewClientBuilder().WithScheme(scheme).WithObjects(page).Build(),
+		Authorizer: verbAuthorizer{allowed: map[string]bool{"get": true}},
+	}
+	protect := auth.Middleware(staticAuthenticator{user: &auth.UserInfo{Username: "alice"}})
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.SetUserValue("name", "page")
+	protect(api.GetFrontendPage)(ctx)
+	require.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+
+	ctx = &fasthttp.RequestCtx{}
+	ctx.SetUserValue("name", "page")
+	protect(api.DeleteFrontendPage)(ctx)
+	require.Equal(t, fasthttp.StatusForbidden, ctx.Response.StatusCode())
+	require.Contains(t, string(ctx.Response.Body()), "RBAC: cannot delete frontendpages")
+
+	// Without an authenticated user the authorizer cannot be consulted.
+	ctx = &fasthttp.RequestCtx{}
+	api.ListFrontendPages(ctx)
+	require.Equal(t, fasthttp.StatusUnauthorized, ctx.Response.StatusCode())
+}
+
+func TestRenderFrontendPages(t *testing.T) {
+	watched, err := scope.Parse("web,other")
+	require.NoError(t, err)
+	api := &FrontendPageAPI{Scope: watched}
+	render := func(body, namespace string) *fasthttp.RequestCtx {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetBodyString(body)
+		if namespace != "" {
+			ctx.QueryArgs().Set("namespace", namespace)
+		}
+		api.RenderFrontendPages(ctx)
+		return ctx
+	}
+
+	ctx := render(`{"apiVersion":"v1","kind":"List","items":[
+		{"apiVersion":"frontendpage.silhouetteua.io/v1alpha1","kind":"FrontendPage","metadata":{"name":"home"},"spec":{"contents":"hi","image":"nginx","replicas":2}},
+		{"apiVersion":"frontendpage.silhouetteua.io/v1alpha1","kind":"FrontendPageBackup","metadata":{"name":"nightly"},"spec":{"frontendPageRef":"home","schedule":"0 2 * * *"}}]}`, "")
+	require.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode(), string(ctx.Response.Body()))
+	var list RenderListDoc
+	require.NoError(t, json.Unmarshal(ctx.Response.Body(), &list))
+	require.Len(t, list.Items, 3)
+	var kinds []string
+	for _, item := range list.Items {
+		kinds = append(kinds, item["kind"].(string))
+		require.Equal(t, "web", item["metadata"].(map[string]any)["namespace"], "the scope's default namespace is used")
+	}
+	require.Equal(t, []string{"ConfigMap", "Deployment", "CronJob"}, kinds)
+
+	page := `{"apiVersion":"frontendpage.silhouetteua.io/v1alpha1","kind":"FrontendPage","metadata":{"name":"home","namespace":"web"},"spec":{"image":"nginx","replicas":1}}`
+	require.Equal(t, fasthttp.StatusOK, render(page, "web").Response.StatusCode())
+	errorOf := func(ctx *fasthttp.RequestCtx, status int) string {
+		require.Equal(t, status, ctx.Response.StatusCode())
+		require.Equal(t, "application/json", string(ctx.Response.Header.ContentType()))
+		var body map[string]string
+		require.NoError(t, json.Unmarshal(ctx.Response.Body(), &body))
+		return body["error"]
+	}
+	require.Equal(t, `metadata.namespace "web" of "home" does not match the request namespace "other"`, errorOf(render(page, "other"), fasthttp.StatusBadRequest))
+	require.Equal(t, fasthttp.StatusNotFound, render(page, "kube-system").Response.StatusCode())
+	require.Contains(t, errorOf(render(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"x"}}`, ""), fasthttp.StatusBadRequest), `cannot render ConfigMap "x"`)
+	require.Equal(t, fasthttp.StatusBadRequest, render(`not json`, "").Response.StatusCode())
+}
@@ -0,0 +1,332 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/silhouetteUA/k8s-controller/pkg/auth"
+	"github.com/silhouetteUA/k8s-controller/pkg/informer"
+	"github.com/silhouetteUA/k8s-controller/pkg/rollout"
+	"github.com/silhouetteUA/k8s-controller/pkg/scope"
+)
+
+// ListerSource provides the shared informer caches the read-only endpoints are served from.
+type ListerSource interface {
+	// DeploymentIndexer and SecretIndexer return the cache holding namespace, carrying
+	// informer.DeploymentIndexers and informer.SecretIndexers for the image, owner, label
+	// and type queries. The cache may hold other namespaces too.
+	DeploymentIndexer(namespace string) cache.Indexer
+	SecretIndexer(namespace string) cache.Indexer
+	HasSynced() bool
+}
+
+// InformerAPI serves Deployments and Secrets from the shared informer cache, so reads
+// never hit the API server.
+type InformerAPI struct {
+	Listers ListerSource
+	// Scope limits the namespaces requests may target with ?namespace=; requests without
+	// one use the scope's default namespace. Nil allows any namespace.
+	Scope *scope.Scope
+	// Authorizer, when set, checks the caller may read the resource. Responses come from
+	// the controller's cache, so this is also used when the FrontendPage API impersonates.
+	Authorizer auth.Authorizer
+}
+
+// DeploymentSummary is the list view of a Deployment.
+// @Description Deployment summary served from the informer cache
+type DeploymentSummary struct {
+	Name              string            `json:"name" example:"web"`
+	Namespace         string            `json:"namespace" example:"default"`
+	Labels            map[string]string `json:"labels,omitempty"`
+	Images            []string          `json:"images"`
+	Replicas          int32             `json:"replicas" example:"2"`
+	ReadyReplicas     int32             `json:"readyReplicas" example:"2"`
+	UpdatedReplicas   int32             `json:"updatedReplicas" example:"2"`
+	AvailableReplicas int32             `json:"availableReplicas" example:"2"`
+	CreationTimestamp metav1.Time       `json:"creationTimestamp" swaggertype:"string" format:"date-time"`
+}
+
+// DeploymentDetail is the detail view of a Deployment, including its rollout status.
+// @Description Deployment detail served from the informer cache
+type DeploymentDetail struct {
+	DeploymentSummary
+	Selector           map[string]string            `json:"selector,omitempty"`
+	Strategy           string                       `json:"strategy" example:"RollingUpdate"`
+	Generation         int64                        `json:"generation"`
+	ObservedGeneration int64                        `json:"observedGeneration"`
+	Conditions         []appsv1.DeploymentCondition `json:"conditions,omitempty" swaggerignore:"true"`
+	Rollout            rollout.Status               `json:"rollout"`
+}
+
+// SecretSummary is the list view of a Secret. It never carries secret data, nor
+// annotations, which may embed it (e.g. last-applied-configuration).
+// @Description Secret metadata served from the informer cache (never includes data)
+type SecretSummary struct {
+	Name              string            `json:"name" example:"db-credentials"`
+	Namespace         string            `json:"namespace" example:"default"`
+	Labels            map[string]string `json:"labels,omitempty"`
+	Type              string            `json:"type" example:"Opaque"`
+	Keys              int               `json:"keys" example:"2"`
+	CreationTimestamp metav1.Time       `json:"creationTimestamp" swaggertype:"string" format:"date-time"`
+}
+
+func summarizeDeployment(d *appsv1.Deployment) DeploymentSummary {
+	images := make([]string, 0, len(d.Spec.Template.Spec.Containers))
+	for _, c := range d.Spec.Template.Spec.Containers {
+		images = append(images, c.Image)
+	}
+	replicas := int32(1)
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	return DeploymentSummary{
+		Name:              d.Name,
+		Namespace:         d.Namespace,
+		Labels:            d.Labels,
+		Images:            images,
+		Replicas:          replicas,
+		ReadyReplicas:     d.Status.ReadyReplicas,
+		UpdatedReplicas:   d.Status.UpdatedReplicas,
+		AvailableReplicas: d.Status.AvailableReplicas,
+		CreationTimestamp: d.CreationTimestamp,
+	}
+}
+
+func summarizeSecret(s *corev1.Secret) SecretSummary {
+	return SecretSummary{
+		Name:              s.Name,
+		Namespace:         s.Namespace,
+		Labels:            s.Labels,
+		Type:              string(s.Type),
+		Keys:              len(s.Data),
+		CreationTimestamp: s.CreationTimestamp,
+	}
+}
+
+// labelSelector parses the labelSelector query parameter. On failure it writes a 400 and returns false.
+func labelSelector(ctx *fasthttp.RequestCtx) (labels.Selector, bool) {
+	selector, err := labels.Parse(string(ctx.QueryArgs().Peek("labelSelector")))
+	if err != nil {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("invalid labelSelector: %v", err))
+		return nil, false
+	}
+	return selector, true
+}
+
+// requestNamespace returns the namespace from the namespace query parameter, or the
+// scope's default. It writes a 404 and returns false when the namespace isn't watched.
+func requestNamespace(ctx *fasthttp.RequestCtx, s *scope.Scope) (string, bool) {
+	namespace := string(ctx.QueryArgs().Peek("namespace"))
+	if namespace == "" {
+		namespace = s.Default()
+	}
+	if !s.Contains(namespace) {
+		writeJSONError(ctx, fasthttp.StatusNotFound, fmt.Sprintf("namespace %q is not watched", namespace))
+		return "", false
+	}
+	return namespace, true
+}
+
+// indexQuery builds an informer.Query for namespace from the label, owner and the given
+// extra query parameters. On failure it writes a 400 and returns false.
+func (api *InformerAPI) indexQuery(ctx *fasthttp.RequestCtx, namespace, extra string) (informer.Query, bool) {
+	args := ctx.QueryArgs()
+	q := informer.Query{
+		Namespace: namespace,
+		Label:     string(args.Peek("label")),
+		Owner:     string(args.Peek("owner")),
+	}
+	if q.Owner != "" && strings.Count(q.Owner, "/") != 1 {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, fmt.Sprintf("invalid owner %q, want Kind/name", q.Owner))
+		return q, false
+	}
+	switch extra {
+	case informer.IndexByImage:
+		q.Image = string(args.Peek("image"))
+	case informer.IndexBySecretType:
+		q.Type = string(args.Peek("type"))
+	}
+	return q, true
+}
+
+// cacheReady writes a 503 and returns false until the informer caches have synced.
+func (api *InformerAPI) cacheReady(ctx *fasthttp.RequestCtx) bool {
+	if api.Listers == nil || !api.Listers.HasSynced() {
+		ctx.SetStatusCode(fasthttp.StatusServiceUnavailable)
+		ctx.SetBodyString(`{"error":"informer cache not synced yet"}`)
+		return false
+	}
+	return true
+}
+
+func writeJSON(ctx *fasthttp.RequestCtx, v any) {
+	ctx.SetContentType("application/json")
+	err := json.NewEncoder(ctx).Encode(v)
+	if err != nil {
+		return
+	}
+}
+
+// ListDeployments godoc
+// @Summary List Deployments
+// @Description List Deployments from the informer cache. The image, owner and label filters are index lookups, not scans.
+// @Tags deployments
+// @Produce json
+// @Param namespace query string false "Namespace; defaults to the first watched namespace"
+// @Param labelSelector query string false "Label selector, e.g. app=web,tier!=cache"
+// @Param image query string false "Container image, with or without tag, e.g. nginx or nginx:1.27"
+// @Param owner query string false "Owner reference as Kind/name, e.g. FrontendPage/home"
+// @Param label query string false "Label key or key=value"
+// @Success 200 {array} DeploymentSummary
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/deployments [get]
+func (api *InformerAPI) ListDeployments(ctx *fasthttp.RequestCtx) {
+	namespace, ok := requestNamespace(ctx, api.Scope)
+	if !ok {
+		return
+	}
+	if !authorize(ctx, api.Authorizer, auth.ResourceAttributes{Verb: "list", Group: "apps", Version: "v1", Resource: "deployments", Namespace: namespace}) {
+		return
+	}
+	selector, ok := labelSelector(ctx)
+	if !ok {
+		return
+	}
+	q, ok := api.indexQuery(ctx, namespace, informer.IndexByImage)
+	if !ok || !api.cacheReady(ctx) {
+		return
+	}
+	deployments, err := informer.QueryDeployments(api.Listers.DeploymentIndexer(namespace), q)
+	if err != nil {
+		writeError(ctx, err, fasthttp.StatusInternalServerError)
+		return
+	}
+	out := make([]DeploymentSummary, 0, len(deployments))
+	for _, d := range deployments {
+		if selector.Matches(labels.Set(d.Labels)) {
+			out = append(out, summarizeDeployment(d))
+		}
+	}
+	writeJSON(ctx, out)
+}
+
+// GetDeployment godoc
+// @Summary Get a Deployment
+// @Description Get a Deployment from the informer cache, including its rollout status
+// @Tags deployments
+// @Produce json
+// @Param name path string true "Deployment name"
+// @Param namespace query string false "Namespace; defaults to the first watched namespace"
+// @Success 200 {object} DeploymentDetail
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/deployments/{name} [get]
+func (api *InformerAPI) GetDeployment(ctx *fasthttp.RequestCtx) {
+	name, _ := ctx.UserValue("name").(string)
+	if name == "" {
+		ctx.SetStatusCode(fasthttp.StatusBadRequest)
+		ctx.SetBodyString(`{"error":"missing name parameter"}`)
+		return
+	}
+	namespace, ok := requestNamespace(ctx, api.Scope)
+	if !ok {
+		return
+	}
+	if !authorize(ctx, api.Authorizer, auth.ResourceAttributes{Verb: "get", Group: "apps", Version: "v1", Resource: "deployments", Namespace: namespace, Name: name}) {
+		return
+	}
+	if !api.cacheReady(ctx) {
+		return
+	}
+	d, err := appslisters.NewDeploymentLister(api.Listers.DeploymentIndexer(namespace)).Deployments(namespace).Get(name)
+	if err != nil {
+		status := fasthttp.StatusInternalServerError
+		if apierrors.IsNotFound(err) {
+			status = fasthttp.StatusNotFound
+		}
+		writeError(ctx, err, status)
+		return
+	}
+	strategy := string(d.Spec.Strategy.Type)
+	if strategy == "" {
+		strategy = string(appsv1.RollingUpdateDeploymentStrategyType)
+	}
+	detail := DeploymentDetail{
+		DeploymentSummary:  summarizeDeployment(d),
+		Strategy:           strategy,
+		Generation:         d.Generation,
+		ObservedGeneration: d.Status.ObservedGeneration,
+		Conditions:         d.Status.Conditions,
+		Rollout:            rollout.DeploymentStatus(d),
+	}
+	if d.Spec.Selector != nil {
+		detail.Selector = d.Spec.Selector.MatchLabels
+	}
+	writeJSON(ctx, detail)
+}
+
+// ListSecrets godoc
+// @Summary List Secrets
+// @Description List Secret metadata from the informer cache. The type, owner and label filters are index lookups, not scans. Secret data is never returned.
+// @Tags secrets
+// @Produce json
+// @Param namespace query string false "Namespace; defaults to the first watched namespace"
+// @Param labelSelector query string false "Label selector, e.g. app=web"
+// @Param type query string false "Secret type, e.g. kubernetes.io/tls"
+// @Param owner query string false "Owner reference as Kind/name, e.g. FrontendPage/home"
+// @Param label query string false "Label key or key=value"
+// @Success 200 {array} SecretSummary
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/secrets [get]
+func (api *InformerAPI) ListSecrets(ctx *fasthttp.RequestCtx) {
+	namespace, ok := requestNamespace(ctx, api.Scope)
+	if !ok {
+		return
+	}
+	if !authorize(ctx, api.Authorizer, auth.ResourceAttributes{Verb: "list", Version: "v1", Resource: "secrets", Namespace: namespace}) {
+		return
+	}
+	selector, ok := labelSelector(ctx)
+	if !ok {
+		return
+	}
+	q, ok := api.indexQuery(ctx, namespace, informer.IndexBySecretType)
+	if !ok || !api.cacheReady(ctx) {
+		return
+	}
+	secrets, err := informer.QuerySecrets(api.Listers.SecretIndexer(namespace), q)
+	if err != nil {
+		writeError(ctx, err, fasthttp.StatusInternalServerError)
+		return
+	}
+	out := make([]SecretSummary, 0, len(secrets))
+	for _, sec := range secrets {
+		if selector.Matches(labels.Set(sec.Labels)) {
+			out = append(out, summarizeSecret(sec))
+		}
+	}
+	writeJSON(ctx, out)
+}
@@ -0,0 +1,207 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/silhouetteUA/k8s-controller/pkg/informer"
+	"github.com/silhouetteUA/k8s-controller/pkg/rollout"
+	"github.com/silhouetteUA/k8s-controller/pkg/scope"
+)
+
+type indexerListers struct {
+	deployments cache.Indexer
+	secrets     cache.Indexer
+	synced      bool
+}
+
+func (l *indexerListers) DeploymentIndexer(string) cache.Indexer { return l.deployments }
+
+func (l *indexerListers) SecretIndexer(string) cache.Indexer { return l.secrets }
+
+func (l *indexerListers) HasSynced() bool { return l.synced }
+
+func newIndexerListers(t *testing.T, objs ...any) *indexerListers {
+	l := &indexerListers{
+		deployments: cache.NewIndexer(cache.MetaNamespaceKeyFunc, informer.DeploymentIndexers()),
+		secrets:     cache.NewIndexer(cache.MetaNamespaceKeyFunc, informer.SecretIndexers()),
+		synced:      true,
+	}
+	for _, obj := range objs {
+		switch o := obj.(type) {
+		case *appsv1.Deployment:
+			require.NoError(t, l.deployments.Add(o))
+		case *corev1.Secret:
+			require.NoError(t, l.secrets.Add(o))
+		}
+	}
+	return l
+}
+
+func testDeployment(name, app string) *appsv1.Deployment {
+	replicas := int32(2)
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": app}, Generation: 1},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": app}},
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "c", Image: "nginx:1.27"}}}},
+		},
+		Status: appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 2, UpdatedReplicas: 2, ReadyReplicas: 2, AvailableReplicas: 2},
+	}
+}
+
+func TestInformerAPI_Deployments(t *testing.T) {
+	api := &InformerAPI{
+		Listers: newIndexerListers(t, testDeployment("web", "web"), testDeployment("cache", "redis")),
+	}
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/api/deployments?labelSelector=app%3Dweb")
+	api.ListDeployments(ctx)
+	require.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+	var list []DeploymentSummary
+	require.NoError(t, json.Unmarshal(ctx.Response.Body(), &list))
+	require.Len(t, list, 1)
+	require.Equal(t, "web", list[0].Name)
+	require.Equal(t, []string{"nginx:1.27"}, list[0].Images)
+
+	ctx = &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/api/deployments?labelSelector=app%3D%3D%3D")
+	api.ListDeployments(ctx)
+	require.Equal(t, fasthttp.StatusBadRequest, ctx.Response.StatusCode())
+
+	ctx = &fasthttp.RequestCtx{}
+	ctx.SetUserValue("name", "web")
+	api.GetDeployment(ctx)
+	require.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+	var detail DeploymentDetail
+	require.NoError(t, json.Unmarshal(ctx.Response.Body(), &detail))
+	require.Equal(t, rollout.PhaseComplete, detail.Rollout.Phase)
+	require.Equal(t, "RollingUpdate", detail.Strategy)
+
+	ctx = &fasthttp.RequestCtx{}
+	ctx.SetUserValue("name", "missing")
+	api.GetDeployment(ctx)
+	require.Equal(t, fasthttp.StatusNotFound, ctx.Response.StatusCode())
+}
+
+func TestInformerAPI_IndexQueries(t *testing.T) {
+	owned := testDeployment("home", "web")
+	owned.OwnerReferences = []metav1.OwnerReference{{Kind: "FrontendPage", Name: "home"}}
+	owned.Spec.Template.Spec.Containers[0].Image = "docker.io/library/nginx:1.25"
+	other := testDeployment("cache", "redis")
+	other.Spec.Template.Spec.Containers[0].Image = "redis:7"
+	tls := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "tls", Namespace: "default"}, Type: corev1.SecretTypeTLS}
+	opaque := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"}, Type: corev1.SecretTypeOpaque}
+	api := &InformerAPI{Listers: newIndexerListers(t, owned, other, testDeployment("web", "web"), tls, opaque)}
+
+	names := func(uri string, handler fasthttp.RequestHandler) []string {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI(uri)
+		handler(ctx)
+		require.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode(), string(ctx.Response.Body()))
+		var list []struct{ Name string }
+		require.NoError(t, json.Unmarshal(ctx.Response.Body(), &list))
+		out := []string{}
+		for _, item := range list {
+			out = append(out, item.Name)
+		}
+		return out
+	}
+	require.Equal(t, []string{"home", "web"}, names("/api/deployments?image=nginx", api.ListDeployments))
+	require.Equal(t, []string{"home"}, names("/api/deployments?image=nginx:1.25", api.ListDeployments))
+	require.Equal(t, []string{"home"}, names("/api/deployments?owner=FrontendPage/home", api.ListDeployments))
+	require.Equal(t, []string{"cache"}, names("/api/deployments?label=app%3Dredis", api.ListDeployments))
+	require.Equal(t, []string{"home", "web"}, names("/api/deployments?image=nginx&label=app&labelSelector=app%3Dweb", api.ListDeployments))
+	require.Empty(t, names("/api/deployments?image=nginx&owner=FrontendPage/other", api.ListDeployments))
+	require.Equal(t, []string{"tls"}, names("/api/secrets?type=kubernetes.io/tls", api.ListSecrets))
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/api/deployments?owner=home")
+	api.ListDeployments(ctx)
+	require.Equal(t, fasthttp.StatusBadRequest, ctx.Response.StatusCode())
+	var body map[string]string
+	require.NoError(t, json.Unmarshal(ctx.Response.Body(), &body))
+	require.Equal(t, `invalid owner "home", want Kind/name`, body["error"])
+}
+
+func TestInformerAPI_SecretsNeverExposeData(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "db", Namespace: "default",
+			Annotations: map[string]string{"kubectl.kubernetes.io/last-applied-configuration": `{"data":{"password":"aHVudGVyMg=="}}`},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{"password": []byte("hunter2")},
+	}
+	api := &InformerAPI{Listers: newIndexerListers(t, secret)}
+
+	ctx := &fasthttp.RequestCtx{}
+	api.ListSecrets(ctx)
+	require.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+	body := string(ctx.Response.Body())
+	require.Contains(t, body, `"name":"db"`)
+	require.Contains(t, body, `"keys":1`)
+	require.NotContains(t, body, "hunter2")
+	require.NotContains(t, body, "aHVudGVyMg==")
+}
+
+func TestInformerAPI_NotSynced(t *testing.T) {
+	listers := newIndexerListers(t)
+	listers.synced = false
+	api := &InformerAPI{Listers: listers}
+
+	ctx := &fasthttp.RequestCtx{}
+	api.ListSecrets(ctx)
+	require.Equal(t, fasthttp.StatusServiceUnavailable, ctx.Response.StatusCode())
+}
+
+func TestInformerAPI_Namespaces(t *testing.T) {
+	staging := testDeployment("api", "api")
+	staging.Namespace = "staging"
+	watched, err := scope.Parse("staging,default")
+	require.NoError(t, err)
+	api := &InformerAPI{Listers: newIndexerListers(t, testDeployment("web", "web"), staging), Scope: watched}
+
+	var body []byte
+	list := func(uri string) (int, []DeploymentSummary) {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI(uri)
+		api.ListDeployments(ctx)
+		body = ctx.Response.Body()
+		var out []DeploymentSummary
+		if ctx.Response.StatusCode() == fasthttp.StatusOK {
+			require.NoError(t, json.Unmarshal(body, &out))
+		}
+		return ctx.Response.StatusCode(), out
+	}
+	status, out := list("/api/deployments")
+	require.Equal(t, fasthttp.StatusOK, status)
+	require.Len(t, out, 1)
+	require.Equal(t, "api", out[0].Name, "the first listed namespace is the default")
+
+	status, out = list("/api/deployments?namespace=default")
+	require.Equal(t, fasthttp.StatusOK, status)
+	require.Len(t, out, 1)
+	require.Equal(t, "web", out[0].Name)
+
+	status, _ = list("/api/deployments?namespace=kube-system")
+	require.Equal(t, fasthttp.StatusNotFound, status)
+	var notWatched map[string]string
+	require.NoError(t, json.Unmarshal(body, &notWatched))
+	require.Equal(t, `namespace "kube-system" is not watched`, notWatched["error"])
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/api/deployments/web?namespace=default")
+	ctx.SetUserValue("name", "web")
+	api.GetDeployment(ctx)
+	require.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+}
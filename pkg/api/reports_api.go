@@ -0,0 +1,68 @@
+package api
+
+import (
+	"slices"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/silhouetteUA/k8s-controller/pkg/auth"
+	"github.com/silhouetteUA/k8s-controller/pkg/policy"
+	"github.com/silhouetteUA/k8s-controller/pkg/scope"
+)
+
+// ReportsAPI serves the Deployment policy audit results.
+type ReportsAPI struct {
+	Reports *policy.Reports
+	// Scope hides reports of namespaces that are no longer watched. Nil shows all.
+	Scope *scope.Scope
+	// Authorizer, when set, checks the caller may list Deployments.
+	Authorizer auth.Authorizer
+}
+
+// DeploymentReportDoc is the response of /api/reports/deployments.
+// @Description Deployment policy audit report
+type DeploymentReportDoc struct {
+	Summary     policy.Summary  `json:"summary"`
+	Deployments []policy.Report `json:"deployments"`
+}
+
+// DeploymentReports godoc
+// @Summary Deployment policy report
+// @Description Aggregated results of the Deployment policy audit across the watched namespaces, or one namespace. Use failing=true to list only non-compliant Deployments; the summary always covers all of them.
+// @Tags reports
+// @Produce json
+// @Param namespace query string false "Only report on this namespace"
+// @Param failing query bool false "Only list non-compliant Deployments"
+// @Param check query string false "Only list Deployments failing this check, e.g. probes"
+// @Success 200 {object} DeploymentReportDoc
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/reports/deployments [get]
+func (api *ReportsAPI) DeploymentReports(ctx *fasthttp.RequestCtx) {
+	namespace := string(ctx.QueryArgs().Peek("namespace"))
+	if !authorize(ctx, api.Authorizer, auth.ResourceAttributes{Verb: "list", Group: "apps", Version: "v1", Resource: "deployments", Namespace: namespace}) {
+		return
+	}
+	var reports []policy.Report
+	if api.Reports != nil {
+		for _, r := range api.Reports.List() {
+			if api.Scope.Contains(r.Namespace) && (namespace == "" || r.Namespace == namespace) {
+				reports = append(reports, r)
+			}
+		}
+	}
+	out := DeploymentReportDoc{Summary: policy.Summarize(reports), Deployments: []policy.Report{}}
+	failing := ctx.QueryArgs().GetBool("failing")
+	check := string(ctx.QueryArgs().Peek("check"))
+	for _, r := range reports {
+		if failing && r.Compliant {
+			continue
+		}
+		if check != "" && !slices.Contains(r.Violations, check) {
+			continue
+		}
+		out.Deployments = append(out.Deployments, r)
+	}
+	writeJSON(ctx, out)
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/silhouetteUA/k8s-controller/pkg/policy"
+)
+
+func TestReportsAPI_DeploymentReports(t *testing.T) {
+	reports := policy.NewReports()
+	for name, image := range map[string]string{"pinned": "nginx:1.27", "floating": "nginx"} {
+		reports.Set(policy.Evaluate(policy.Policy{DisallowLatestTag: true}, &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "web", Image: image}},
+			}}},
+		}))
+	}
+	api := &ReportsAPI{Reports: reports}
+
+	get := func(uri string) DeploymentReportDoc {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI(uri)
+		api.DeploymentReports(ctx)
+		require.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+		var doc DeploymentReportDoc
+		require.NoError(t, json.Unmarshal(ctx.Response.Body(), &doc))
+		return doc
+	}
+
+	all := get("/api/reports/deployments")
+	require.Equal(t, policy.Summary{Total: 2, Compliant: 1, Violations: map[string]int{policy.CheckNoLatestTag: 1}}, all.Summary)
+	require.Len(t, all.Deployments, 2)
+
+	failing := get("/api/reports/deployments?failing=true")
+	require.Equal(t, all.Summary, failing.Summary)
+	require.Len(t, failing.Deployments, 1)
+	require.Equal(t, "floating", failing.Deployments[0].Name)
+
+	require.Len(t, get("/api/reports/deployments?check=probes").Deployments, 0)
+}
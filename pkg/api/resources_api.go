@@ -0,0 +1,184 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/valyala/fasthttp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/silhouetteUA/k8s-controller/pkg/auth"
+	"github.com/silhouetteUA/k8s-controller/pkg/informer"
+)
+
+// coreGroupAlias is the path segment used for the core ("") API group.
+const coreGroupAlias = "core"
+
+// ResourcesAPI serves any resource watched by the dynamic informers.
+type ResourcesAPI struct {
+	Informers *informer.DynamicInformerManager
+	// Authorizer, when set, checks the caller may read the resource.
+	Authorizer auth.Authorizer
+}
+
+// WatchedResourceDoc describes a resource served under /api/resources.
+// @Description Resource watched by the dynamic informers
+type WatchedResourceDoc struct {
+	Group    string `json:"group" example:"frontendpage.silhouetteua.io"`
+	Version  string `json:"version" example:"v1alpha1"`
+	Resource string `json:"resource" example:"frontendpages"`
+	Path     string `json:"path" example:"/api/resources/frontendpage.silhouetteua.io/v1alpha1/frontendpages"`
+}
+
+func resourcePath(gvr schema.GroupVersionResource) string {
+	group := gvr.Group
+	if group == "" {
+		group = coreGroupAlias
+	}
+	return fmt.Sprintf("/api/resources/%s/%s/%s", group, gvr.Version, gvr.Resource)
+}
+
+func gvrFrom(ctx *fasthttp.RequestCtx) schema.GroupVersionResource {
+	group, _ := ctx.UserValue("group").(string)
+	if group == coreGroupAlias {
+		group = ""
+	}
+	version, _ := ctx.UserValue("version").(string)
+	resource, _ := ctx.UserValue("resource").(string)
+	return schema.GroupVersionResource{Group: group, Version: version, Resource: resource}
+}
+
+// redactSecret strips data from core Secrets so the generic endpoint never leaks it.
+func redactSecret(gvr schema.GroupVersionResource, obj *unstructured.Unstructured) *unstructured.Unstructured {
+	if gvr.Group != "" || gvr.Resource != "secrets" {
+		return obj
+	}
+	obj = obj.DeepCopy()
+	unstructured.RemoveNestedField(obj.Object, "data")
+	unstructured.RemoveNestedField(obj.Object, "stringData")
+	obj.SetAnnotations(nil)
+	return obj
+}
+
+func (api *ResourcesAPI) writeLookupError(ctx *fasthttp.RequestCtx, gvr schema.GroupVersionResource, err error) {
+	switch {
+	case errors.Is(err, informer.ErrNotWatched):
+		ctx.SetStatusCode(fasthttp.StatusNotFound)
+		ctx.SetBodyString(fmt.Sprintf(`{"error":"%s is not watched"}`, gvr.String()))
+	case apierrors.IsNotFound(err):
+		writeError(ctx, err, fasthttp.StatusNotFound)
+	default:
+		writeError(ctx, err, fasthttp.StatusInternalServerError)
+	}
+}
+
+// ListWatchedResources godoc
+// @Summary List watched resources
+// @Description List the resources configured for the dynamic informers
+// @Tags resources
+// @Produce json
+// @Success 200 {array} WatchedResourceDoc
+// @Failure 401 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/resources [get]
+func (api *ResourcesAPI) ListWatchedResources(ctx *fasthttp.RequestCtx) {
+	out := []WatchedResourceDoc{}
+	if api.Informers != nil {
+		for _, gvr := range api.Informers.Resources() {
+			out = append(out, WatchedResourceDoc{Group: gvr.Group, Version: gvr.Version, Resource: gvr.Resource, Path: resourcePath(gvr)})
+		}
+	}
+	writeJSON(ctx, out)
+}
+
+// ListResources godoc
+// @Summary List objects of a watched resource
+// @Description List cached objects of any resource configured for the dynamic informers. Use "core" as the group for the core API group.
+// @Tags resources
+// @Produce json
+// @Param group path string true "API group, or core"
+// @Param version path string true "API version"
+// @Param resource path string true "Resource (plural)"
+// @Param namespace query string false "Namespace; all watched namespaces when omitted"
+// @Param labelSelector query string false "Label selector"
+// @Success 200 {array} object
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/resources/{group}/{version}/{resource} [get]
+func (api *ResourcesAPI) ListResources(ctx *fasthttp.RequestCtx) {
+	gvr := gvrFrom(ctx)
+	namespace := string(ctx.QueryArgs().Peek("namespace"))
+	if !authorize(ctx, api.Authorizer, auth.ResourceAttributes{Verb: "list", Group: gvr.Group, Version: gvr.Version, Resource: gvr.Resource, Namespace: namespace}) {
+		return
+	}
+	selector, ok := labelSelector(ctx)
+	if !ok || !api.cacheReady(ctx) {
+		return
+	}
+	objs, err := api.Informers.List(gvr, namespace, selector)
+	if err != nil {
+		api.writeLookupError(ctx, gvr, err)
+		return
+	}
+	out := make([]map[string]interface{}, 0, len(objs))
+	for _, obj := range objs {
+		out = append(out, redactSecret(gvr, obj).Object)
+	}
+	writeJSON(ctx, out)
+}
+
+// GetResource godoc
+// @Summary Get an object of a watched resource
+// @Description Get a cached object of any resource configured for the dynamic informers
+// @Tags resources
+// @Produce json
+// @Param group path string true "API group, or core"
+// @Param version path string true "API version"
+// @Param resource path string true "Resource (plural)"
+// @Param name path string true "Object name"
+// @Param namespace query string false "Namespace; omit for cluster-scoped resources"
+// @Success 200 {object} object
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Security BearerAuth
+// @Router /api/resources/{group}/{version}/{resource}/{name} [get]
+func (api *ResourcesAPI) GetResource(ctx *fasthttp.RequestCtx) {
+	gvr := gvrFrom(ctx)
+	name, _ := ctx.UserValue("name").(string)
+	namespace := string(ctx.QueryArgs().Peek("namespace"))
+	if !authorize(ctx, api.Authorizer, auth.ResourceAttributes{Verb: "get", Group: gvr.Group, Version: gvr.Version, Resource: gvr.Resource, Namespace: namespace, Name: name}) {
+		return
+	}
+	if !api.cacheReady(ctx) {
+		return
+	}
+	obj, err := api.Informers.Get(gvr, namespace, name)
+	if err != nil {
+		api.writeLookupError(ctx, gvr, err)
+		return
+	}
+	writeJSON(ctx, redactSecret(gvr, obj).Object)
+}
+
+// cacheReady writes a 503 and returns false until the dynamic informer caches have synced.
+func (api *ResourcesAPI) cacheReady(ctx *fasthttp.RequestCtx) bool {
+	if api.Informers == nil {
+		ctx.SetStatusCode(fasthttp.StatusNotFound)
+		ctx.SetBodyString(`{"error":"no resources are configured, see --resources-config"}`)
+		return false
+	}
+	if !api.Informers.HasSynced() {
+		ctx.SetStatusCode(fasthttp.StatusServiceUnavailable)
+		ctx.SetBodyString(`{"error":"informer cache not synced yet"}`)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/silhouetteUA/k8s-controller/pkg/informer"
+)
+
+func newResourcesAPI(t *testing.T, objs ...runtime.Object) *ResourcesAPI {
+	t.Helper()
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			{Group: "frontendpage.silhouetteua.io", Version: "v1alpha1", Resource: "frontendpages"}: "FrontendPageList",
+			{Version: "v1", Resource: "secrets"}:                                                    "SecretList",
+		}, objs...)
+	m, err := informer.NewDynamicInformerManager(client, informer.DynamicConfig{Resources: []informer.ResourceConfig{
+		{Group: "frontendpage.silhouetteua.io", Version: "v1alpha1", Resource: "frontendpages"},
+		{Version: "v1", Resource: "secrets"},
+	}})
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(func() { cancel(); m.Shutdown() })
+	require.NoError(t, m.Start(ctx))
+	require.NoError(t, m.WaitForSync(ctx))
+	return &ResourcesAPI{Informers: m}
+}
+
+func resourceRequest(group, version, resource, name, query string) *fasthttp.RequestCtx {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/api/resources?" + query)
+	ctx.SetUserValue("group", group)
+	ctx.SetUserValue("version", version)
+	ctx.SetUserValue("resource", resource)
+	if name != "" {
+		ctx.SetUserValue("name", name)
+	}
+	return ctx
+}
+
+func TestResourcesAPI(t *testing.T) {
+	page := &unstructured.Unstructured{}
+	page.SetAPIVersion("frontendpage.silhouetteua.io/v1alpha1")
+	page.SetKind("FrontendPage")
+	page.SetNamespace("default")
+	page.SetName("home")
+	page.SetLabels(map[string]string{"team": "web"})
+	secret := &unstructured.Unstructured{Object: map[string]interface{}{"data": map[string]interface{}{"password": "aHVudGVyMg=="}}}
+	secret.SetAPIVersion("v1")
+	secret.SetKind("Secret")
+	secret.SetNamespace("default")
+	secret.SetName("db")
+	api := newResourcesAPI(t, page, secret)
+
+	ctx := &fasthttp.RequestCtx{}
+	api.ListWatchedResources(ctx)
+	var watched []WatchedResourceDoc
+	require.NoError(t, json.Unmarshal(ctx.Response.Body(), &watched))
+	require.Len(t, watched, 2)
+	require.Equal(t, "/api/resources/core/v1/secrets", watched[0].Path)
+
+	ctx = resourceRequest("frontendpage.silhouetteua.io", "v1alpha1", "frontendpages", "", "labelSelector=team%3Dweb")
+	api.ListResources(ctx)
+	require.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+	var list []map[string]interface{}
+	require.NoError(t, json.Unmarshal(ctx.Response.Body(), &list))
+	require.Len(t, list, 1)
+
+	ctx = resourceRequest("frontendpage.silhouetteua.io", "v1alpha1", "frontendpages", "home", "namespace=default")
+	api.GetResource(ctx)
+	require.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+	require.Contains(t, string(ctx.Response.Body()), `"name":"home"`)
+
+	ctx = resourceRequest("core", "v1", "secrets", "db", "namespace=default")
+	api.GetResource(ctx)
+	require.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+	require.NotContains(t, string(ctx.Response.Body()), "aHVudGVyMg==")
+
+	ctx = resourceRequest("core", "v1", "pods", "", "")
+	api.ListResources(ctx)
+	require.Equal(t, fasthttp.StatusNotFound, ctx.Response.StatusCode())
+
+	ctx = resourceRequest("frontendpage.silhouetteua.io", "v1alpha1", "frontendpages", "missing", "namespace=default")
+	api.GetResource(ctx)
+	require.Equal(t, fasthttp.StatusNotFound, ctx.Response.StatusCode())
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"github.com/valyala/fasthttp"
+
+	"github.com/silhouetteUA/k8s-controller/docs"
+)
+
+// swaggerUIHTML renders Swagger UI from the public CDN and points it at the
+// embedded OpenAPI document.
+const swaggerUIHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8" />
+  <title>kctl FrontendPage API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css" />
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.onload = () => {
+      window.ui = SwaggerUIBundle({ url: "/openapi.json", dom_id: "#swagger-ui" });
+    };
+  </script>
+</body>
+</html>`
+
+// ServeOpenAPI returns the OpenAPI document embedded at build time.
+func ServeOpenAPI(ctx *fasthttp.RequestCtx) {
+	ctx.SetContentType("application/json")
+	ctx.SetBody(docs.SwaggerJSON)
+}
+
+// ServeSwaggerUI returns a Swagger UI page backed by /openapi.json.
+func ServeSwaggerUI(ctx *fasthttp.RequestCtx) {
+	ctx.SetContentType("text/html; charset=utf-8")
+	ctx.SetBodyString(swaggerUIHTML)
+}
@@ -0,0 +1,228 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/valyala/fasthttp"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// userKey is the RequestCtx user value under which the authenticated UserInfo is stored.
+const userKey = "auth.user"
+
+// UserInfo describes an authenticated caller.
+type UserInfo struct {
+	Username string
+	UID      string
+	Groups   []string
+	Extra    map[string][]string
+}
+
+// Authenticator resolves the caller of a request.
+// It returns (nil, nil) when the request carries no credentials it understands.
+type Authenticator interface {
+	Authenticate(ctx *fasthttp.RequestCtx) (*UserInfo, error)
+}
+
+// ErrUnauthenticated is returned when credentials were presented but rejected.
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// UserFrom returns the authenticated user stored on the request, if any.
+func UserFrom(ctx *fasthttp.RequestCtx) (*UserInfo, bool) {
+	u, ok := ctx.UserValue(userKey).(*UserInfo)
+	return u, ok
+}
+
+// Union tries each authenticator in order and returns the first user resolved.
+type Union []Authenticator
+
+func (u Union) Authenticate(ctx *fasthttp.RequestCtx) (*UserInfo, error) {
+	for _, a := range u {
+		user, err := a.Authenticate(ctx)
+		if err != nil || user != nil {
+			return user, err
+		}
+	}
+	return nil, nil
+}
+
+// ClientCertAuthenticator authenticates callers by a TLS client certificate that
+// was already verified against the client CA during the handshake.
+// The certificate CommonName becomes the username and Organizations the groups.
+type ClientCertAuthenticator struct{}
+
+func (ClientCertAuthenticator) Authenticate(ctx *fasthttp.RequestCtx) (*UserInfo, error) {
+	state := ctx.TLSConnectionState()
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil, nil
+	}
+	cert := state.VerifiedChains[0][0]
+	if cert.Subject.CommonName == "" {
+		return nil, ErrUnauthenticated
+	}
+	return &UserInfo{
+		Username: cert.Subject.CommonName,
+		Groups:   cert.Subject.Organization,
+	}, nil
+}
+
+type tokenCacheEntry struct {
+	user    *UserInfo
+	expires time.Time
+}
+
+// TokenReviewAuthenticator validates bearer tokens with the Kubernetes TokenReview API.
+// Results, including rejections, are cached by token hash for CacheTTL.
+type TokenReviewAuthenticator struct {
+	Client    kubernetes.Interface
+	Audiences []string
+	CacheTTL  time.Duration
+
+	mu    sync.Mutex
+	cache map[string]tokenCacheEntry
+}
+
+// NewTokenReviewAuthenticator returns a TokenReviewAuthenticator with an empty cache.
+func NewTokenReviewAuthenticator(client kubernetes.Interface, cacheTTL time.Duration, audiences ...string) *TokenReviewAuthenticator {
+	return &TokenReviewAuthenticator{
+		Client:    client,
+		Audiences: audiences,
+		CacheTTL:  cacheTTL,
+		cache:     map[string]tokenCacheEntry{},
+	}
+}
+
+func (a *TokenReviewAuthenticator) Authenticate(ctx *fasthttp.RequestCtx) (*UserInfo, error) {
+	header := string(ctx.Request.Header.Peek(fasthttp.HeaderAuthorization))
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok {
+		return nil, nil
+	}
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil, ErrUnauthenticated
+	}
+
+	sum := sha256.Sum256([]byte(token))
+	key := hex.EncodeToString(sum[:])
+	if user, hit := a.lookup(key); hit {
+		if user == nil {
+			return nil, ErrUnauthenticated
+		}
+		return user, nil
+	}
+
+	review, err := a.Client.AuthenticationV1().TokenReviews().Create(ctx, &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token, Audiences: a.Audiences},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		// Do not cache transport errors, the token may well be valid.
+		return nil, err
+	}
+	if !review.Status.Authenticated {
+		if review.Status.Error != "" {
+			log.Debug().Msgf("TokenReview rejected token: %s", review.Status.Error)
+		}
+		a.store(key, nil)
+		return nil, ErrUnauthenticated
+	}
+
+	user := &UserInfo{
+		Username: review.Status.User.Username,
+		UID:      review.Status.User.UID,
+		Groups:   review.Status.User.Groups,
+	}
+	if len(review.Status.User.Extra) > 0 {
+		user.Extra = map[string][]string{}
+		for k, v := range review.Status.User.Extra {
+			user.Extra[k] = v
+		}
+	}
+	a.store(key, user)
+	return user, nil
+}
+
+func (a *TokenReviewAuthenticator) lookup(key string) (*UserInfo, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	entry, ok := a.cache[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(a.cache, key)
+		return nil, false
+	}
+	return entry.user, true
+}
+
+func (a *TokenReviewAuthenticator) store(key string, user *UserInfo) {
+	if a.CacheTTL <= 0 {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.cache == nil {
+		a.cache = map[string]tokenCacheEntry{}
+	}
+	now := time.Now()
+	// Sweep expired entries so the cache cannot grow without bound.
+	for k, e := range a.cache {
+		if now.After(e.expires) {
+			delete(a.cache, k)
+		}
+	}
+	a.cache[key] = tokenCacheEntry{user: user, expires: now.Add(a.CacheTTL)}
+}
+
+// Middleware rejects requests that the authenticator cannot resolve to a user
+// with 401, or with 503 when the credentials could not be checked, and stores the
+// user on the request for downstream handlers.
+func Middleware(authn Authenticator) func(fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			user, err := authn.Authenticate(ctx)
+			if err != nil && !errors.Is(err, ErrUnauthenticated) {
+				// The credentials could not be checked, e.g. the API server is unreachable;
+				// a 401 would make clients discard a token that may well be valid.
+				log.Error().Err(err).
+					Str("method", string(ctx.Method())).
+					Str("path", string(ctx.Path())).
+					Str("remote_addr", ctx.RemoteAddr().String()).
+					Msg("Authentication failed")
+				ctx.SetContentType("application/json")
+				ctx.SetStatusCode(fasthttp.StatusServiceUnavailable)
+				ctx.SetBodyString(`{"error":"authentication unavailable"}`)
+				return
+			}
+			if user == nil {
+				log.Warn().
+					Str("method", string(ctx.Method())).
+					Str("path", string(ctx.Path())).
+					Str("remote_addr", ctx.RemoteAddr().String()).
+					Msg("Unauthenticated request")
+				ctx.Response.Header.Set(fasthttp.HeaderWWWAuthenticate, `Bearer realm="kctl"`)
+				ctx.SetContentType("application/json")
+				ctx.SetStatusCode(fasthttp.StatusUnauthorized)
+				ctx.SetBodyString(`{"error":"unauthorized"}`)
+				return
+			}
+			ctx.SetUserValue(userKey, user)
+			log.Info().
+				Str("user", user.Username).
+				Str("method", string(ctx.Method())).
+				Str("path", string(ctx.Path())).
+				Str("remote_addr", ctx.RemoteAddr().String()).
+				Msg("Incoming request")
+			next(ctx)
+		}
+	}
+}
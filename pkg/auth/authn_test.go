@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// fakeTokenReviews answers TokenReviews: "good-token" authenticates as alice, anything else is rejected.
+func fakeTokenReviews(calls *int) *fake.Clientset {
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		*calls++
+		review := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview).DeepCopy()
+		if review.Spec.Token == "good-token" {
+			review.Status.Authenticated = true
+			review.Status.User = authenticationv1.UserInfo{Username: "alice", Groups: []string{"devs"}}
+		} else {
+			review.Status.Error = "invalid token"
+		}
+		return true, review, nil
+	})
+	return clientset
+}
+
+func requestWithToken(token string) *fasthttp.RequestCtx {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/api/frontendpages")
+	if token != "" {
+		ctx.Request.Header.Set(fasthttp.HeaderAuthorization, "Bearer "+token)
+	}
+	return ctx
+}
+
+func TestTokenReviewAuthenticator(t *testing.T) {
+	calls := 0
+	authn := NewTokenReviewAuthenticator(fakeTokenReviews(&calls), time.Minute)
+
+	user, err := authn.Authenticate(requestWithToken("good-token"))
+	require.NoError(t, err)
+	require.Equal(t, "alice", user.Username)
+	require.Equal(t, []string{"devs"}, user.Groups)
+
+	// Served from cache
+	_, err = authn.Authenticate(requestWithToken("good-token"))
+	require.NoError(t, err)
+	require.Equal(t, 1, calls)
+
+	user, err = authn.Authenticate(requestWithToken("bad-token"))
+	require.ErrorIs(t, err, ErrUnauthenticated)
+	require.Nil(t, user)
+
+	user, err = authn.Authenticate(requestWithToken(""))
+	require.NoError(t, err)
+	require.Nil(t, user)
+}
+
+func TestMiddleware(t *testing.T) {
+	calls := 0
+	authn := Union{ClientCertAuthenticator{}, NewTokenReviewAuthenticator(fakeTokenReviews(&calls), time.Minute)}
+	var seen *UserInfo
+	handler := Middleware(authn)(func(ctx *fasthttp.RequestCtx) {
+		seen, _ = UserFrom(ctx)
+		ctx.SetStatusCode(fasthttp.StatusOK)
+	})
+
+	ctx := requestWithToken("")
+	handler(ctx)
+	require.Equal(t, fasthttp.StatusUnauthorized, ctx.Response.StatusCode())
+	require.NotEmpty(t, ctx.Response.Header.Peek(fasthttp.HeaderWWWAuthenticate))
+
+	ctx = requestWithToken("bad-token")
+	handler(ctx)
+	require.Equal(t, fasthttp.StatusUnauthorized, ctx.Response.StatusCode())
+	require.Nil(t, seen)
+
+	ctx = requestWithToken("good-token")
+	handler(ctx)
+	require.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+	require.Equal(t, "alice", seen.Username)
+
+	// A failed TokenReview is an outage, not a rejected token.
+	failing := fake.NewSimpleClientset()
+	failing.PrependReactor("create", "tokenreviews", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("connection refused")
+	})
+	handler = Middleware(NewTokenReviewAuthenticator(failing, time.Minute))(func(ctx *fasthttp.RequestCtx) {
+		ctx.SetStatusCode(fasthttp.StatusOK)
+	})
+	ctx = requestWithToken("good-token")
+	handler(ctx)
+	require.Equal(t, fasthttp.StatusServiceUnavailable, ctx.Response.StatusCode())
+	require.Empty(t, ctx.Response.Header.Peek(fasthttp.HeaderWWWAuthenticate))
+}
@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestSubjectAccessReviewer(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview).DeepCopy()
+		attrs := review.Spec.ResourceAttributes
+		if review.Spec.User == "alice" && attrs.Verb == "get" && attrs.Resource == "frontendpages" {
+			review.Status.Allowed = true
+			review.Status.Reason = `RBAC: allowed by RoleBinding "pages-readers/default"`
+		}
+		return true, review, nil
+	})
+	authz := &SubjectAccessReviewer{Client: clientset}
+	alice := &UserInfo{Username: "alice", Groups: []string{"devs"}}
+
+	decision, err := authz.Authorize(context.Background(), alice, ResourceAttributes{Verb: "get", Resource: "frontendpages", Namespace: "default"})
+	require.NoError(t, err)
+	require.True(t, decision.Allowed)
+
+	decision, err = authz.Authorize(context.Background(), alice, ResourceAttributes{Verb: "delete", Resource: "frontendpages", Namespace: "default"})
+	require.NoError(t, err)
+	require.False(t, decision.Allowed)
+	require.Contains(t, decision.Reason, `cannot delete resource "frontendpages"`)
+}
+
+func TestImpersonatingClientFactory(t *testing.T) {
+	var mu sync.Mutex
+	var users []string
+	conns := 0
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		users = append(users, r.Header.Get("Impersonate-User"))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"home","namespace":"web"}}`))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			mu.Lock()
+			conns++
+			mu.Unlock()
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+	base := &rest.Config{Host: server.URL}
+	f := &ImpersonatingClientFactory{Config: base, Scheme: scheme, Mapper: mapper}
+
+	for _, name := range []string{"alice", "bob"} {
+		c, err := f.ClientFor(&UserInfo{Username: name, Groups: []string{"devs"}})
+		require.NoError(t, err)
+		var cm corev1.ConfigMap
+		require.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: "web", Name: "home"}, &cm))
+	}
+	require.Equal(t, []string{"alice", "bob"}, users)
+	require.Equal(t, 1, conns, "clients must share one transport")
+	require.Empty(t, base.Impersonate.UserName, "base config must not be mutated")
+}
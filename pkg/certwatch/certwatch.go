@@ -0,0 +1,95 @@
+package certwatch
+
+import (
+	"context"
+	"crypto/tls"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog/log"
+)
+
+// CertWatcher serves a TLS key pair from disk and reloads it whenever the
+// files change, so rotated certificates are picked up without a restart.
+type CertWatcher struct {
+	certFile string
+	keyFile  string
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+
+	watcher *fsnotify.Watcher
+}
+
+// New loads the key pair and prepares a watcher for its directories.
+func New(certFile, keyFile string) (*CertWatcher, error) {
+	cw := &CertWatcher{certFile: certFile, keyFile: keyFile}
+	if err := cw.reload(); err != nil {
+		return nil, err
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	// Watch the directories rather than the files: Kubernetes Secret volumes
+	// swap a symlink, which replaces the files instead of writing to them.
+	dirs := map[string]struct{}{filepath.Dir(certFile): {}, filepath.Dir(keyFile): {}}
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			_ = watcher.Close()
+			return nil, err
+		}
+	}
+	cw.watcher = watcher
+	return cw, nil
+}
+
+// GetCertificate returns the current certificate; use it as tls.Config.GetCertificate.
+func (cw *CertWatcher) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cw.mu.RLock()
+	defer cw.mu.RUnlock()
+	return cw.cert, nil
+}
+
+// Start reloads the key pair on file changes until ctx is cancelled.
+func (cw *CertWatcher) Start(ctx context.Context) {
+	defer func() {
+		_ = cw.watcher.Close()
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-cw.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Has(fsnotify.Chmod) {
+				continue
+			}
+			if err := cw.reload(); err != nil {
+				// Keep serving the previous certificate, the pair may be mid-update.
+				log.Warn().Err(err).Msg("Failed to reload TLS certificate")
+				continue
+			}
+			log.Info().Msgf("Reloaded TLS certificate from %s", cw.certFile)
+		case err, ok := <-cw.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Error().Err(err).Msg("TLS certificate watcher error")
+		}
+	}
+}
+
+func (cw *CertWatcher) reload() error {
+	cert, err := tls.LoadX509KeyPair(cw.certFile, cw.keyFile)
+	if err != nil {
+		return err
+	}
+	cw.mu.Lock()
+	cw.cert = &cert
+	cw.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,66 @@
+package certwatch
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeKeyPair writes a self-signed certificate for commonName into dir.
+func writeKeyPair(t *testing.T, dir, commonName string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tls.key"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tls.crt"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+}
+
+func commonName(t *testing.T, cw *CertWatcher) string {
+	cert, err := cw.GetCertificate(nil)
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	return leaf.Subject.CommonName
+}
+
+func TestCertWatcher_ReloadsOnChange(t *testing.T) {
+	dir := t.TempDir()
+	writeKeyPair(t, dir, "first")
+
+	cw, err := New(filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"))
+	require.NoError(t, err)
+	require.Equal(t, "first", commonName(t, cw))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go cw.Start(ctx)
+
+	writeKeyPair(t, dir, "second")
+	require.Eventually(t, func() bool { return commonName(t, cw) == "second" }, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestNew_MissingFiles(t *testing.T) {
+	_, err := New("/does/not/exist.crt", "/does/not/exist.key")
+	require.Error(t, err)
+}
@@ -0,0 +1,205 @@
+// Package config is kctl's layered configuration: built-in defaults, overridden by a
+// YAML file, overridden by KCTL_* environment variables, overridden by command-line flags.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/silhouetteUA/k8s-controller/pkg/auth"
+	"github.com/silhouetteUA/k8s-controller/pkg/events"
+	"github.com/silhouetteUA/k8s-controller/pkg/policy"
+	"github.com/silhouetteUA/k8s-controller/pkg/scope"
+)
+
+// EnvPrefix starts the environment variable of every setting: server.events.queueSize is
+// KCTL_SERVER_EVENTS_QUEUE_SIZE.
+const EnvPrefix = "KCTL_"
+
+// EnvConfigFile names the config file when --config is not given.
+const EnvConfigFile = "KCTL_CONFIG"
+
+// Config is the complete kctl configuration. Fields tagged flag are also command-line
+// flags of that name on the commands that register them.
+type Config struct {
+	// LogLevel is trace, debug, info, warn or error. Reloaded live by the server.
+	LogLevel string `json:"logLevel" flag:"log-level"`
+	// Kubeconfig is the kubeconfig path; empty uses $KUBECONFIG, ~/.kube/config or the
+	// in-cluster config.
+	Kubeconfig string `json:"kubeconfig" flag:"kubeconfig"`
+	// Context is the kubeconfig context to use; empty is the current context.
+	Context string `json:"context" flag:"context"`
+	// Namespace is the namespace the client commands work in; empty is the context's.
+	Namespace string       `json:"namespace" flag:"namespace"`
+	Server    ServerConfig `json:"server"`
+}
+
+// ServerConfig configures kctl server.
+type ServerConfig struct {
+	Port            int    `json:"port" flag:"port"`
+	InCluster       bool   `json:"inCluster" flag:"in-cluster"`
+	WatchNamespaces string `json:"watchNamespaces" flag:"watch-namespaces"`
+	MetricsPort     int    `json:"metricsPort" flag:"metrics-port"`
+	HealthProbePort int    `json:"healthProbePort" flag:"health-probe-port"`
+	// ResourcesConfig is the dynamic informer file served under /api/resources.
+	ResourcesConfig string               `json:"resourcesConfig" flag:"resources-config"`
+	LeaderElection  LeaderElectionConfig `json:"leaderElection"`
+	HTTP            HTTPConfig           `json:"http"`
+	TLS             TLSConfig            `json:"tls"`
+	Auth            AuthConfig           `json:"auth"`
+	Events          EventsConfig         `json:"events"`
+	Policy          PolicyConfig         `json:"policy"`
+	Orphans         OrphansConfig        `json:"orphans"`
+}
+
+// LeaderElectionConfig configures leader election between server replicas.
+type LeaderElectionConfig struct {
+	Enabled bool `json:"enabled" flag:"enable-leader-election"`
+	// Namespace holds the lease and the policy ConfigMap; empty is the first watched namespace.
+	Namespace string `json:"namespace" flag:"leader-election-namespace"`
+}
+
+// HTTPConfig hardens the REST API server.
+type HTTPConfig struct {
+	ReadTimeout     metav1.Duration `json:"readTimeout" flag:"read-timeout"`
+	WriteTimeout    metav1.Duration `json:"writeTimeout" flag:"write-timeout"`
+	IdleTimeout     metav1.Duration `json:"idleTimeout" flag:"idle-timeout"`
+	ShutdownTimeout metav1.Duration `json:"shutdownTimeout" flag:"shutdown-timeout"`
+	MaxBodySize     int             `json:"maxBodySize" flag:"max-body-size"`
+}
+
+// TLSConfig enables HTTPS and client certificate authentication.
+type TLSConfig struct {
+	CertFile     string `json:"certFile" flag:"tls-cert-file"`
+	KeyFile      string `json:"keyFile" flag:"tls-key-file"`
+	ClientCAFile string `json:"clientCAFile" flag:"client-ca-file"`
+}
+
+// AuthConfig configures REST API authentication and authorization.
+type AuthConfig struct {
+	Enabled   bool            `json:"enabled" flag:"enable-auth"`
+	AuthzMode string          `json:"authzMode" flag:"authz-mode"`
+	CacheTTL  metav1.Duration `json:"cacheTTL" flag:"auth-cache-ttl"`
+}
+
+// EventsConfig configures the change event sinks.
+type EventsConfig struct {
+	Sinks       []string `json:"sinks" flag:"event-sinks"`
+	WebhookURL  string   `json:"webhookURL" flag:"event-webhook-url"`
+	JournalFile string   `json:"journalFile" flag:"event-journal-file"`
+	NATSURL     string   `json:"natsURL" flag:"event-nats-url"`
+	NATSSubject string   `json:"natsSubject" flag:"event-nats-subject"`
+	QueueSize   int      `json:"queueSize" flag:"event-queue-size"`
+	MaxAttempts int      `json:"maxAttempts" flag:"event-max-attempts"`
+}
+
+// PolicyConfig configures the Deployment policy audit.
+type PolicyConfig struct {
+	ConfigMap string `json:"configMap" flag:"policy-configmap"`
+	// Default is the policy used while the ConfigMap does not exist. Reloaded live by the server.
+	Default policy.Policy `json:"default"`
+}
+
+// OrphansConfig configures the orphan scanner.
+type OrphansConfig struct {
+	ScanInterval metav1.Duration `json:"scanInterval" flag:"orphan-scan-interval"`
+	GC           bool            `json:"gc" flag:"orphan-gc"`
+}
+
+// Default returns the built-in defaults; the commands register their flags with them.
+func Default() *Config {
+	return &Config{
+		LogLevel: "info",
+		Server: ServerConfig{
+			Port:            8080,
+			WatchNamespaces: metav1.NamespaceDefault,
+			MetricsPort:     8081,
+			HealthProbePort: 8082,
+			LeaderElection:  LeaderElectionConfig{Enabled: true},
+			HTTP: HTTPConfig{
+				ReadTimeout:     metav1.Duration{Duration: 10 * time.Second},
+				WriteTimeout:    metav1.Duration{Duration: 10 * time.Second},
+				IdleTimeout:     metav1.Duration{Duration: 60 * time.Second},
+				ShutdownTimeout: metav1.Duration{Duration: 30 * time.Second},
+				MaxBodySize:     4 * 1024 * 1024,
+			},
+			Auth: AuthConfig{Enabled: true, AuthzMode: auth.AuthzModeSAR, CacheTTL: metav1.Duration{Duration: 10 * time.Second}},
+			Events: EventsConfig{
+				Sinks:       []string{"log"},
+				NATSURL:     "nats://localhost:4222",
+				NATSSubject: "kctl.events",
+				QueueSize:   events.DefaultQueueSize,
+				MaxAttempts: events.DefaultMaxAttempts,
+			},
+			Policy:  PolicyConfig{ConfigMap: "kctl-deployment-policy", Default: policy.Default()},
+			Orphans: OrphansConfig{ScanInterval: metav1.Duration{Duration: 10 * time.Minute}},
+		},
+	}
+}
+
+// DefaultPath is the config file used when neither --config nor KCTL_CONFIG is set. It
+// is optional: a missing default file is skipped.
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "kctl", "config.yaml")
+}
+
+var (
+	logLevels  = []string{"trace", "debug", "info", "warn", "error"}
+	authzModes = []string{auth.AuthzModeSAR, auth.AuthzModeImpersonate, auth.AuthzModeNone}
+	eventSinks = []string{"log", "webhook", "nats", "file"}
+)
+
+// Validate reports every invalid setting at once.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+	port := func(key string, p int) {
+		check(p > 0 && p < 65536, "%s: %d is not a valid port", key, p)
+	}
+	nonNegative := func(key string, d metav1.Duration) {
+		check(d.Duration >= 0, "%s: must not be negative", key)
+	}
+
+	check(slices.Contains(logLevels, strings.ToLower(c.LogLevel)), "logLevel: %q is not one of %s", c.LogLevel, strings.Join(logLevels, ", "))
+	s := c.Server
+	port("server.port", s.Port)
+	port("server.metricsPort", s.MetricsPort)
+	port("server.healthProbePort", s.HealthProbePort)
+	if _, err := scope.Parse(s.WatchNamespaces); err != nil {
+		errs = append(errs, fmt.Errorf("server.watchNamespaces: %w", err))
+	}
+	nonNegative("server.http.readTimeout", s.HTTP.ReadTimeout)
+	nonNegative("server.http.writeTimeout", s.HTTP.WriteTimeout)
+	nonNegative("server.http.idleTimeout", s.HTTP.IdleTimeout)
+	nonNegative("server.http.shutdownTimeout", s.HTTP.ShutdownTimeout)
+	check(s.HTTP.MaxBodySize > 0, "server.http.maxBodySize: must be positive")
+	check((s.TLS.CertFile == "") == (s.TLS.KeyFile == ""), "server.tls: certFile and keyFile must be set together")
+	check(s.TLS.ClientCAFile == "" || s.TLS.CertFile != "", "server.tls.clientCAFile: requires certFile and keyFile")
+	check(slices.Contains(authzModes, s.Auth.AuthzMode), "server.auth.authzMode: %q is not one of %s", s.Auth.AuthzMode, strings.Join(authzModes, ", "))
+	nonNegative("server.auth.cacheTTL", s.Auth.CacheTTL)
+	for _, sink := range s.Events.Sinks {
+		check(slices.Contains(eventSinks, sink), "server.events.sinks: %q is not one of %s", sink, strings.Join(eventSinks, ", "))
+	}
+	check(!slices.Contains(s.Events.Sinks, "webhook") || s.Events.WebhookURL != "", "server.events.webhookURL: required by the webhook sink")
+	check(!slices.Contains(s.Events.Sinks, "file") || s.Events.JournalFile != "", "server.events.journalFile: required by the file sink")
+	check(s.Events.QueueSize > 0, "server.events.queueSize: must be positive")
+	check(s.Events.MaxAttempts > 0, "server.events.maxAttempts: must be positive")
+	check(s.Policy.Default.MinReplicas >= 0, "server.policy.default.minReplicas: must not be negative")
+	nonNegative("server.orphans.scanInterval", s.Orphans.ScanInterval)
+	return errors.Join(errs...)
+}
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+}
+
+func TestEnvName(t *testing.T) {
+	for key, want := range map[string]string{
+		"logLevel":                          "KCTL_LOG_LEVEL",
+		"server.events.queueSize":           "KCTL_SERVER_EVENTS_QUEUE_SIZE",
+		"server.tls.clientCAFile":           "KCTL_SERVER_TLS_CLIENT_CA_FILE",
+		"server.events.natsURL":             "KCTL_SERVER_EVENTS_NATS_URL",
+		"server.auth.cacheTTL":              "KCTL_SERVER_AUTH_CACHE_TTL",
+		"server.policy.default.minReplicas": "KCTL_SERVER_POLICY_DEFAULT_MIN_REPLICAS",
+	} {
+		require.Equal(t, want, EnvName(key), key)
+	}
+}
+
+func TestLoader_Layers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kctl.yaml")
+	writeFile(t, path, `
+logLevel: debug
+server:
+  port: 9090
+  metricsPort: 9091
+  events:
+    sinks: [log, nats]
+  http:
+    readTimeout: 5s
+  policy:
+    default:
+      minReplicas: 2
+`)
+	env := map[string]string{
+		"KCTL_SERVER_METRICS_PORT":      "9191",
+		"KCTL_SERVER_EVENTS_SINKS":      "log, file",
+		"KCTL_SERVER_ORPHANS_GC":        "true",
+		"KCTL_SERVER_HEALTH_PROBE_PORT": "9292",
+	}
+	var port, probePort int
+	var sinks []string
+	var readTimeout time.Duration
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.IntVar(&port, "port", 8080, "")
+	fs.IntVar(&probePort, "health-probe-port", 8082, "")
+	fs.StringSliceVar(&sinks, "event-sinks", []string{"log"}, "")
+	fs.DurationVar(&readTimeout, "read-timeout", 10*time.Second, "")
+	require.NoError(t, fs.Parse([]string{"--health-probe-port=9393"}))
+
+	l := &Loader{Path: path, Getenv: func(k string) string { return env[k] }, Flags: fs}
+	cfg, err := l.Load()
+	require.NoError(t, err)
+	require.Equal(t, "debug", cfg.LogLevel, "file over default")
+	require.Equal(t, 9090, cfg.Server.Port, "file over default")
+	require.Equal(t, 9191, cfg.Server.MetricsPort, "env over file")
+	require.Equal(t, []string{"log", "file"}, cfg.Server.Events.Sinks, "env over file")
+	require.True(t, cfg.Server.Orphans.GC)
+	require.Equal(t, 9393, cfg.Server.HealthProbePort, "flag over env")
+	require.Equal(t, 5*time.Second, cfg.Server.HTTP.ReadTimeout.Duration)
+	require.EqualValues(t, 2, cfg.Server.Policy.Default.MinReplicas)
+	require.True(t, cfg.Server.Policy.Default.RequireProbes, "unset policy fields keep their defaults")
+	require.Equal(t, "nats://localhost:4222", cfg.Server.Events.NATSURL)
+
+	require.NoError(t, ApplyToFlags(cfg, fs))
+	require.Equal(t, 9090, port)
+	require.Equal(t, 9393, probePort)
+	require.Equal(t, []string{"log", "file"}, sinks)
+	require.Equal(t, 5*time.Second, readTimeout)
+	require.False(t, fs.Changed("port"), "applied values do not count as user-set flags")
+
+	// A reload keeps the user's flags on top.
+	writeFile(t, path, "server:\n  port: 7070\n  healthProbePort: 7071\n")
+	cfg, err = l.Load()
+	require.NoError(t, err)
+	require.Equal(t, 7070, cfg.Server.Port)
+	require.Equal(t, 9393, cfg.Server.HealthProbePort)
+}
+
+func TestLoader_Errors(t *testing.T) {
+	dir := t.TempDir()
+	_, err := (&Loader{Path: filepath.Join(dir, "missing.yaml")}).Load()
+	require.Error(t, err, "an explicit file must exist")
+
+	missing := &Loader{Getenv: func(k string) string {
+		if k == EnvConfigFile {
+			return filepath.Join(dir, "missing.yaml")
+		}
+		return ""
+	}}
+	_, err = missing.Load()
+	require.Error(t, err, "a file named by KCTL_CONFIG must exist")
+
+	path := filepath.Join(dir, "kctl.yaml")
+	writeFile(t, path, "server:\n  prot: 9090\n")
+	_, err = (&Loader{Path: path}).Load()
+	require.ErrorContains(t, err, "prot", "unknown keys are rejected")
+
+	_, err = (&Loader{Getenv: func(k string) string {
+		if k == "KCTL_SERVER_PORT" {
+			return "http"
+		}
+		return ""
+	}}).Load()
+	require.ErrorContains(t, err, "KCTL_SERVER_PORT")
+
+	writeFile(t, path, "server:\n  port: 9090\n")
+	t.Setenv(EnvConfigFile, path)
+	t.Setenv("KCTL_SERVER_PORT", "")
+	cfg, err := (&Loader{}).Load()
+	require.NoError(t, err, "an empty variable is unset, as with an injected Getenv")
+	require.Equal(t, 9090, cfg.Server.Port)
+}
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, Default().Validate())
+
+	cfg := Default()
+	cfg.LogLevel = "loud"
+	cfg.Server.Port = 0
+	cfg.Server.WatchNamespaces = "Not_A_Namespace"
+	cfg.Server.Auth.AuthzMode = "rbac"
+	cfg.Server.TLS.CertFile = "tls.crt"
+	cfg.Server.Events.Sinks = []string{"log", "webhook"}
+	cfg.Server.Events.QueueSize = 0
+	err := cfg.Validate()
+	require.Error(t, err)
+	for _, key := range []string{"logLevel", "server.port", "server.watchNamespaces", "server.auth.authzMode",
+		"server.tls", "server.events.webhookURL", "server.events.queueSize"} {
+		require.ErrorContains(t, err, key+":")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	old, cfg := Default(), Default()
+	require.Empty(t, Diff(old, cfg))
+	cfg.LogLevel = "debug"
+	cfg.Server.Policy.Default.AllowedRegistries = []string{"ghcr.io/acme"}
+	require.Equal(t, []string{"logLevel", "server.policy.default.allowedRegistries"}, Diff(old, cfg))
+}
+
+func TestLoader_Watch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kctl.yaml")
+	writeFile(t, path, "logLevel: info\n")
+	l := &Loader{Path: path}
+	cfg, err := l.Load()
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan *Config, 4)
+	done := make(chan error, 1)
+	go func() {
+		done <- l.Watch(ctx, cfg, func(_, cfg *Config) { changes <- cfg })
+	}()
+
+	// Give the watcher time to register before writing.
+	require.Eventually(t, func() bool {
+		writeFile(t, path, "logLevel: debug\n")
+		select {
+		case cfg := <-changes:
+			return cfg.LogLevel == "debug"
+		case <-time.After(100 * time.Millisecond):
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+
+	writeFile(t, path, "logLevel: loud\n")
+	writeFile(t, path, "logLevel: warn\n")
+	require.Eventually(t, func() bool {
+		select {
+		case cfg := <-changes:
+			require.NotEqual(t, "loud", cfg.LogLevel, "invalid files are ignored")
+			return cfg.LogLevel == "warn"
+		default:
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+}
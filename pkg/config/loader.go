@@ -0,0 +1,282 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Loader builds the effective Config from its layers.
+type Loader struct {
+	// Path is the YAML file to read; it must exist. When empty, KCTL_CONFIG is used, then
+	// DefaultPath, which may be missing.
+	Path string
+	// Getenv reads the environment; nil uses os.Getenv. An empty variable counts as unset.
+	Getenv func(string) string
+	// Flags are the parsed command-line flags. Only flags the user set override the other
+	// layers, so loading again after a file change keeps their precedence.
+	Flags *pflag.FlagSet
+}
+
+// File returns the config file Load reads, and whether it must exist.
+func (l *Loader) File() (path string, required bool) {
+	if l.Path != "" {
+		return l.Path, true
+	}
+	if p := l.getenv(EnvConfigFile); p != "" {
+		return p, true
+	}
+	return DefaultPath(), false
+}
+
+// Load returns the defaults overridden by the file, the environment and the flags. It
+// does not validate the result.
+func (l *Loader) Load() (*Config, error) {
+	cfg := Default()
+	if path, required := l.File(); path != "" {
+		data, err := os.ReadFile(path)
+		switch {
+		case errors.Is(err, os.ErrNotExist) && !required:
+		case err != nil:
+			return nil, fmt.Errorf("reading config file: %w", err)
+		default:
+			if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+				return nil, fmt.Errorf("config file %s: %w", path, err)
+			}
+		}
+	}
+
+	var errs []error
+	walk(reflect.ValueOf(cfg).Elem(), "", func(key, flag string, v reflect.Value) {
+		env := EnvName(key)
+		if value, ok := l.lookupEnv(env); ok {
+			if err := setValue(v, value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", env, err))
+			}
+		}
+		if l.Flags == nil || flag == "" {
+			return
+		}
+		if f := l.Flags.Lookup(flag); f != nil && f.Changed {
+			if err := setValue(v, flagValue(f)); err != nil {
+				errs = append(errs, fmt.Errorf("--%s: %w", flag, err))
+			}
+		}
+	})
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ApplyToFlags copies cfg into the flags the user did not set, so the variables bound to
+// them see values from the file and the environment. The flags stay unchanged as far as
+// pflag is concerned.
+func ApplyToFlags(cfg *Config, fs *pflag.FlagSet) error {
+	var errs []error
+	walk(reflect.ValueOf(cfg).Elem(), "", func(key, flag string, v reflect.Value) {
+		f := fs.Lookup(flag)
+		if flag == "" || f == nil || f.Changed {
+			return
+		}
+		var err error
+		if s, ok := f.Value.(pflag.SliceValue); ok {
+			err = s.Replace(v.Interface().([]string))
+		} else {
+			err = f.Value.Set(formatValue(v))
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("--%s: %w", flag, err))
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// Diff returns the keys whose values differ between old and new.
+func Diff(old, new *Config) []string {
+	values := map[string]reflect.Value{}
+	walk(reflect.ValueOf(old).Elem(), "", func(key, _ string, v reflect.Value) { values[key] = v })
+	var changed []string
+	walk(reflect.ValueOf(new).Elem(), "", func(key, _ string, v reflect.Value) {
+		if !reflect.DeepEqual(values[key].Interface(), v.Interface()) {
+			changed = append(changed, key)
+		}
+	})
+	return changed
+}
+
+// Watch loads the config again whenever the file changes and calls onChange with the
+// previous and the new Config when a valid reload changed anything. An invalid file is
+// logged and ignored. Watch returns when ctx is cancelled, or at once without a file.
+func (l *Loader) Watch(ctx context.Context, current *Config, onChange func(old, new *Config)) error {
+	path, _ := l.File()
+	if path == "" {
+		return nil
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = watcher.Close()
+	}()
+	// Watch the directory: ConfigMap volumes swap a symlink instead of writing the file.
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Has(fsnotify.Chmod) {
+				continue
+			}
+			cfg, err := l.Load()
+			if err == nil {
+				err = cfg.Validate()
+			}
+			if err != nil {
+				// Keep the current config, the file may be mid-update.
+				log.Warn().Err(err).Msgf("Ignoring invalid config file %s", path)
+				continue
+			}
+			if len(Diff(current, cfg)) == 0 {
+				continue
+			}
+			onChange(current, cfg)
+			current = cfg
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Error().Err(err).Msg("Config file watcher error")
+		}
+	}
+}
+
+// EnvName returns the environment variable of a config key: server.http.readTimeout is
+// KCTL_SERVER_HTTP_READ_TIMEOUT.
+func EnvName(key string) string {
+	var b strings.Builder
+	b.WriteString(EnvPrefix)
+	runes := []rune(key)
+	for i, r := range runes {
+		switch {
+		case r == '.':
+			b.WriteByte('_')
+			continue
+		case unicode.IsUpper(r) && i > 0 && runes[i-1] != '.':
+			// A new word starts after a lower-case letter, or at the last capital of an
+			// acronym: clientCAFile is CLIENT_CA_FILE.
+			if unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+func (l *Loader) getenv(name string) string {
+	if l.Getenv != nil {
+		return l.Getenv(name)
+	}
+	return os.Getenv(name)
+}
+
+// lookupEnv returns the variable name and whether it is set to a non-empty value.
+func (l *Loader) lookupEnv(name string) (string, bool) {
+	v := l.getenv(name)
+	return v, v != ""
+}
+
+var durationType = reflect.TypeOf(metav1.Duration{})
+
+// walk calls fn for every leaf setting of v with its dotted JSON key and flag name.
+func walk(v reflect.Value, prefix string, fn func(key, flag string, v reflect.Value)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key := prefix + strings.Split(f.Tag.Get("json"), ",")[0]
+		if f.Type.Kind() == reflect.Struct && f.Type != durationType {
+			walk(v.Field(i), key+".", fn)
+			continue
+		}
+		fn(key, f.Tag.Get("flag"), v.Field(i))
+	}
+}
+
+func setValue(v reflect.Value, s string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(metav1.Duration{Duration: d}))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int32:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported setting type %s", v.Type())
+	}
+	return nil
+}
+
+func formatValue(v reflect.Value) string {
+	if v.Type() == durationType {
+		return v.Interface().(metav1.Duration).Duration.String()
+	}
+	if v.Kind() == reflect.Slice {
+		return strings.Join(v.Interface().([]string), ",")
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+func flagValue(f *pflag.Flag) string {
+	if s, ok := f.Value.(pflag.SliceValue); ok {
+		return strings.Join(s.GetSlice(), ",")
+	}
+	return f.Value.String()
+}
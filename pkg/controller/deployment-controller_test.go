@@ -5,12 +5,17 @@ import (
 	"testing"
 	"time"
 
+	"github.com/silhouetteUA/k8s-controller/pkg/policy"
 	testutil "github.com/silhouetteUA/k8s-controller/pkg/testutil"
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestDeploymentReconciler_BasicFlow(t *testing.T) {
@@ -18,7 +23,7 @@ func TestDeploymentReconciler_BasicFlow(t *testing.T) {
 	defer cleanup()
 
 	// Register the controller before starting the manager
-	err := AddDeploymentController(mgr)
+	err := AddDeploymentController(mgr, DeploymentControllerOptions{})
 	require.NoError(t, err)
 
 	go func() {
@@ -59,3 +64,59 @@ func TestDeploymentReconciler_BasicFlow(t *testing.T) {
 }
 
 func int32Ptr(i int32) *int32 { return &i }
+
+func TestDeploymentReconciler_Audit(t *testing.T) {
+	dep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: int32Ptr(1),
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "web", Image: "nginx:latest"}}}},
+		},
+	}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "kctl-deployment-policy", Namespace: "default"},
+		Data:       map[string]string{policy.ConfigKey: "requireResources: false\nrequireProbes: false\n"},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(dep, cm).Build()
+	recorder := record.NewFakeRecorder(10)
+	reports := policy.NewReports()
+	r := &DeploymentReconciler{
+		Client:   c,
+		Scheme:   scheme.Scheme,
+		Recorder: recorder,
+		Policy:   &policy.Loader{Client: c, Namespace: "default", Name: cm.Name},
+		Reports:  reports,
+	}
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(dep)}
+
+	_, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	var got appsv1.Deployment
+	require.NoError(t, c.Get(ctx, req.NamespacedName, &got))
+	require.Equal(t, "false", got.Annotations[AnnotationPolicyCompliant])
+	require.Equal(t, policy.CheckNoLatestTag, got.Annotations[AnnotationPolicyViolations])
+	require.Contains(t, <-recorder.Events, "Warning PolicyViolation no-latest-tag: container web uses image \"nginx:latest\"")
+	require.Len(t, reports.List(), 1)
+
+	// An unchanged outcome emits no new Event.
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	require.Empty(t, recorder.Events)
+
+	// Relaxing the policy is picked up without a restart.
+	cm.Data[policy.ConfigKey] = "disallowLatestTag: false\nrequireResources: false\nrequireProbes: false\n"
+	require.NoError(t, c.Update(ctx, cm))
+	require.Len(t, r.deploymentsInPolicyScope(ctx, cm), 1)
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	require.NoError(t, c.Get(ctx, req.NamespacedName, &got))
+	require.Equal(t, "true", got.Annotations[AnnotationPolicyCompliant])
+	require.NotContains(t, got.Annotations, AnnotationPolicyViolations)
+	require.Contains(t, <-recorder.Events, "Normal PolicyCompliant")
+
+	require.NoError(t, c.Delete(ctx, &got))
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	require.Empty(t, reports.List())
+}
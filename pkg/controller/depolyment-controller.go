@@ -2,33 +2,172 @@ package controller
 
 import (
 	context "context"
+	"fmt"
+	"strings"
 
 	"github.com/rs/zerolog/log"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/silhouetteUA/k8s-controller/pkg/policy"
+	"github.com/silhouetteUA/k8s-controller/pkg/scope"
+)
+
+const (
+	// AnnotationPolicyCompliant is "true" or "false" on audited Deployments.
+	AnnotationPolicyCompliant = "kctl.silhouetteua.io/policy-compliant"
+	// AnnotationPolicyViolations lists the failed policy checks, comma separated.
+	AnnotationPolicyViolations = "kctl.silhouetteua.io/policy-violations"
+
+	reasonPolicyViolation = "PolicyViolation"
+	reasonPolicyCompliant = "PolicyCompliant"
 )
 
+// DeploymentControllerOptions configures the Deployment policy audit.
+type DeploymentControllerOptions struct {
+	// PolicyNamespace and PolicyConfigMap locate the policy ConfigMap. Deployments are
+	// audited against policy.Default() when the name is empty or the ConfigMap is missing.
+	PolicyNamespace string
+	PolicyConfigMap string
+	// Reports, when set, collects the audit results for the reports API.
+	Reports *policy.Reports
+	// Scope filters the audited Deployments; nil audits every namespace in the manager's cache.
+	Scope *scope.Scope
+	// Policy, when set, is used instead of a loader built from PolicyNamespace and
+	// PolicyConfigMap, so the caller can change its default policy at runtime.
+	Policy *policy.Loader
+	// PolicyChanged re-audits every Deployment in scope on each receive; send on it after
+	// changing the default policy.
+	PolicyChanged <-chan event.GenericEvent
+}
+
 type DeploymentReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	Policy   *policy.Loader
+	Reports  *policy.Reports
+	Scope    *scope.Scope
 }
 
+// Reconcile keeps the secret hash of opted-in Deployments current, so opting in or
+// referencing another Secret records the hash the next rotation is compared against,
+// then audits the Deployment against the policy.
 func (r *DeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log.Info().Msgf("Reconciling Deployment: %s/%s", req.Namespace, req.Name)
-	return ctrl.Result{}, nil
+	var dep appsv1.Deployment
+	if err := r.Get(ctx, req.NamespacedName, &dep); err != nil {
+		if client.IgnoreNotFound(err) == nil && r.Reports != nil {
+			r.Reports.Delete(req.NamespacedName)
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if _, err := syncSecretHash(ctx, r.Client, r.Recorder, &dep, ""); err != nil {
+		return ctrl.Result{}, err
+	}
+	if r.Policy == nil {
+		return ctrl.Result{}, nil
+	}
+	p, err := r.Policy.Current(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	report := policy.Evaluate(p, &dep)
+	if r.Reports != nil {
+		r.Reports.Set(report)
+	}
+	return ctrl.Result{}, r.recordAudit(ctx, &dep, report)
+}
+
+// recordAudit mirrors report into the Deployment's annotations. Events are only emitted
+// when the outcome changes, so resyncs don't flood the Deployment with duplicates.
+func (r *DeploymentReconciler) recordAudit(ctx context.Context, dep *appsv1.Deployment, report policy.Report) error {
+	compliant := fmt.Sprintf("%t", report.Compliant)
+	violations := strings.Join(report.Violations, ",")
+	if dep.Annotations[AnnotationPolicyCompliant] == compliant && dep.Annotations[AnnotationPolicyViolations] == violations {
+		return nil
+	}
+	patch := client.MergeFrom(dep.DeepCopy())
+	if dep.Annotations == nil {
+		dep.Annotations = map[string]string{}
+	}
+	dep.Annotations[AnnotationPolicyCompliant] = compliant
+	if violations == "" {
+		delete(dep.Annotations, AnnotationPolicyViolations)
+	} else {
+		dep.Annotations[AnnotationPolicyViolations] = violations
+	}
+	if err := r.Patch(ctx, dep, patch); err != nil {
+		return err
+	}
+	if report.Compliant {
+		r.Recorder.Event(dep, corev1.EventTypeNormal, reasonPolicyCompliant, "Deployment passes every policy check")
+		return nil
+	}
+	var messages []string
+	for _, c := range report.Checks {
+		if !c.Passed {
+			messages = append(messages, fmt.Sprintf("%s: %s", c.Check, c.Message))
+		}
+	}
+	r.Recorder.Event(dep, corev1.EventTypeWarning, reasonPolicyViolation, strings.Join(messages, "; "))
+	return nil
 }
 
-func AddDeploymentController(mgr manager.Manager) error {
+// deploymentsInPolicyScope re-queues every Deployment when the policy changes.
+func (r *DeploymentReconciler) deploymentsInPolicyScope(ctx context.Context, _ client.Object) []reconcile.Request {
+	var deployments appsv1.DeploymentList
+	if err := r.List(ctx, &deployments); err != nil {
+		log.Error().Err(err).Msg("Failed to list Deployments for policy re-evaluation")
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(deployments.Items))
+	for _, d := range deployments.Items {
+		if r.Scope.Contains(d.Namespace) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&d)})
+		}
+	}
+	return requests
+}
+
+func AddDeploymentController(mgr manager.Manager, opts DeploymentControllerOptions) error {
+	loader := opts.Policy
+	if loader == nil {
+		loader = &policy.Loader{Client: mgr.GetClient(), Namespace: opts.PolicyNamespace, Name: opts.PolicyConfigMap}
+	}
 	r := &DeploymentReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("kctl-deployment-controller"),
+		Policy:   loader,
+		Reports:  opts.Reports,
+		Scope:    opts.Scope,
+	}
+	// The policy ConfigMap may live outside the watched namespaces, so the scope filters
+	// the Deployments rather than every event.
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&appsv1.Deployment{}, builder.WithPredicates(opts.Scope.Predicate())).
+		WithOptions(controller.Options{MaxConcurrentReconciles: 1})
+	if opts.PolicyConfigMap != "" {
+		isPolicy := predicate.NewPredicateFuncs(func(o client.Object) bool {
+			return o.GetNamespace() == opts.PolicyNamespace && o.GetName() == opts.PolicyConfigMap
+		})
+		b = b.Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.deploymentsInPolicyScope), builder.WithPredicates(isPolicy))
+	}
+	if opts.PolicyChanged != nil {
+		b = b.WatchesRawSource(source.Channel(opts.PolicyChanged, handler.EnqueueRequestsFromMapFunc(r.deploymentsInPolicyScope)))
 	}
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&appsv1.Deployment{}).
-		WithOptions(controller.Options{MaxConcurrentReconciles: 1}).
-		Complete(r)
+	return b.Complete(r)
 }
@@ -7,13 +7,18 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
 
 	frontendv1alpha1 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/v1alpha1"
+	"github.com/silhouetteUA/k8s-controller/pkg/render"
 	testutil "github.com/silhouetteUA/k8s-controller/pkg/testutil"
 	"github.com/stretchr/testify/require"
 	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 )
@@ -121,3 +126,77 @@ func TestFrontendPageReconciler_CreateFlow(t *testing.T) {
 	time.Sleep(1 * time.Second)
 	printTableState(ctx, k8sClient, ns, t, "after delete")
 }
+
+func TestFrontendPageReconciler_ReadyCondition(t *testing.T) {
+	ctx := context.Background()
+	page := &frontendv1alpha1.FrontendPage{
+		ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "default", Generation: 1},
+		Spec:       frontendv1alpha1.FrontendPageSpec{Contents: "hello", Image: "nginx:alpine", Replicas: 2},
+	}
+	s := orphanScheme(t)
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(page).WithStatusSubresource(page).Build()
+	r := &FrontendPageReconciler{Client: c, Scheme: s}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "home"}}
+	ready := func() *metav1.Condition {
+		var got frontendv1alpha1.FrontendPage
+		require.NoError(t, c.Get(ctx, req.NamespacedName, &got))
+		require.EqualValues(t, 1, got.Status.ObservedGeneration)
+		return meta.FindStatusCondition(got.Status.Conditions, frontendv1alpha1.ConditionReady)
+	}
+
+	_, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	cond := ready()
+	require.Equal(t, metav1.ConditionFalse, cond.Status)
+	require.Equal(t, frontendv1alpha1.ReasonRolloutInProgress, cond.Reason)
+
+	var dep appsv1.Deployment
+	require.NoError(t, c.Get(ctx, req.NamespacedName, &dep))
+	dep.Status = appsv1.DeploymentStatus{ObservedGeneration: dep.Generation, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2}
+	require.NoError(t, c.Status().Update(ctx, &dep))
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	cond = ready()
+	require.Equal(t, metav1.ConditionTrue, cond.Status)
+	require.Equal(t, frontendv1alpha1.ReasonRolloutComplete, cond.Reason)
+
+	var before frontendv1alpha1.FrontendPage
+	require.NoError(t, c.Get(ctx, req.NamespacedName, &before))
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	var after frontendv1alpha1.FrontendPage
+	require.NoError(t, c.Get(ctx, req.NamespacedName, &after))
+	require.Equal(t, before.ResourceVersion, after.ResourceVersion, "an unchanged status is not written")
+}
+
+func TestFrontendPageReconciler_LabelsExistingChildren(t *testing.T) {
+	ctx := context.Background()
+	page := &frontendv1alpha1.FrontendPage{
+		ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "default", Generation: 1},
+		Spec:       frontendv1alpha1.FrontendPageSpec{Contents: "hello", Image: "nginx:alpine", Replicas: 2},
+	}
+	cm := render.ConfigMap(page)
+	cm.Labels = map[string]string{"team": "web"}
+	dep := render.Deployment(page)
+	dep.Labels = nil
+	s := orphanScheme(t)
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(page, cm, dep).WithStatusSubresource(page).Build()
+	r := &FrontendPageReconciler{Client: c, Scheme: s}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "home"}}
+
+	_, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	var gotCM corev1.ConfigMap
+	require.NoError(t, c.Get(ctx, req.NamespacedName, &gotCM))
+	require.Equal(t, map[string]string{"team": "web", render.LabelManagedBy: render.ManagedByValue, render.LabelFrontendPage: "home"}, gotCM.Labels)
+	var gotDep appsv1.Deployment
+	require.NoError(t, c.Get(ctx, req.NamespacedName, &gotDep))
+	require.Equal(t, render.Labels(page), gotDep.Labels)
+
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	var again appsv1.Deployment
+	require.NoError(t, c.Get(ctx, req.NamespacedName, &again))
+	require.Equal(t, gotDep.ResourceVersion, again.ResourceVersion, "labelled children are not updated again")
+}
@@ -9,6 +9,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 
@@ -17,6 +18,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	frontendv1alpha1 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/v1alpha1"
+	"github.com/silhouetteUA/k8s-controller/pkg/render"
+	"github.com/silhouetteUA/k8s-controller/pkg/rollout"
+	"github.com/silhouetteUA/k8s-controller/pkg/scope"
 )
 
 type FrontendPageReconciler struct {
@@ -24,59 +28,6 @@ type FrontendPageReconciler struct {
 	Scheme *runtime.Scheme
 }
 
-func buildConfigMap(page *frontendv1alpha1.FrontendPage) *corev1.ConfigMap {
-	return &corev1.ConfigMap{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      page.Name,
-			Namespace: page.Namespace,
-		},
-		Data: map[string]string{
-			"contents": page.Spec.Contents,
-		},
-	}
-}
-
-func buildDeployment(page *frontendv1alpha1.FrontendPage) *appsv1.Deployment {
-	replicas := int32(page.Spec.Replicas)
-	return &appsv1.Deployment{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      page.Name,
-			Namespace: page.Namespace,
-		},
-		Spec: appsv1.DeploymentSpec{
-			Replicas: &replicas,
-			Selector: &metav1.LabelSelector{
-				MatchLabels: map[string]string{"app": page.Name},
-			},
-			Template: corev1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{
-					Labels: map[string]string{"app": page.Name},
-				},
-				Spec: corev1.PodSpec{
-					Containers: []corev1.Container{{
-						Name:  "frontend",
-						Image: page.Spec.Image,
-						VolumeMounts: []corev1.VolumeMount{{
-							Name:      "contents",
-							MountPath: "/data",
-						}},
-					}},
-					Volumes: []corev1.Volume{{
-						Name: "contents",
-						VolumeSource: corev1.VolumeSource{
-							ConfigMap: &corev1.ConfigMapVolumeSource{
-								LocalObjectReference: corev1.LocalObjectReference{
-									Name: page.Name,
-								},
-							},
-						},
-					}},
-				},
-			},
-		},
-	}
-}
-
 func (r *FrontendPageReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	var page frontendv1alpha1.FrontendPage
 	err := r.Get(ctx, req.NamespacedName, &page)
@@ -98,7 +49,7 @@ func (r *FrontendPageReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	}
 
 	// 1. Ensure ConfigMap exists and is up to date
-	cm := buildConfigMap(&page)
+	cm := render.ConfigMap(&page)
 	if err := ctrl.SetControllerReference(&page, cm, r.Scheme); err != nil {
 		return ctrl.Result{}, err
 	}
@@ -114,7 +65,7 @@ func (r *FrontendPageReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		if err := r.Create(ctx, cm); err != nil && !errors.IsAlreadyExists(err) {
 			return ctrl.Result{}, err
 		}
-	} else if !reflect.DeepEqual(existingCM.Data, cm.Data) {
+	} else if labelled := addLabels(&existingCM, cm.Labels); labelled || !reflect.DeepEqual(existingCM.Data, cm.Data) {
 		existingCM.Data = cm.Data
 		if err := r.Update(ctx, &existingCM); err != nil {
 			return ctrl.Result{}, err
@@ -123,13 +74,14 @@ func (r *FrontendPageReconciler) Reconcile(ctx context.Context, req ctrl.Request
 
 	// 2. Ensure Deployment exists and is up to date
 	log.Info().Msgf("Reconciling Deployment: %s/%s", req.Namespace, req.Name)
-	dep := buildDeployment(&page)
+	dep := render.Deployment(&page)
 	if err := ctrl.SetControllerReference(&page, dep, r.Scheme); err != nil {
 		return ctrl.Result{}, err
 	}
 
 	log.Info().Msgf("Reconciling Deployment for FrontendPage: %s %s", dep.Name, dep.Namespace)
 	var existingDep appsv1.Deployment
+	current := &existingDep
 
 	if err := r.Get(ctx, req.NamespacedName, &existingDep); err != nil && !errors.IsAlreadyExists(err) {
 		if !errors.IsNotFound(err) {
@@ -139,8 +91,10 @@ func (r *FrontendPageReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		if err := r.Create(ctx, dep); err != nil {
 			return ctrl.Result{}, err
 		}
+		current = dep
 	} else {
-		updated := false
+		// Children created before they were labelled get the labels on their next reconcile.
+		updated := addLabels(&existingDep, dep.Labels)
 
 		if *existingDep.Spec.Replicas != *dep.Spec.Replicas {
 			existingDep.Spec.Replicas = dep.Spec.Replicas
@@ -163,14 +117,76 @@ func (r *FrontendPageReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		}
 	}
 
+	// 3. Report the rollout in the Ready condition; Deployment status changes requeue the page.
+	if err := r.updateStatus(ctx, &page, current); err != nil {
+		if errors.IsConflict(err) {
+			return ctrl.Result{Requeue: true}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
 	return ctrl.Result{}, nil
 }
 
-func AddFrontendController(mgr manager.Manager) error {
+// addLabels sets the labels obj is missing or has a different value for and reports
+// whether it changed obj. Other labels are kept.
+func addLabels(obj metav1.Object, labels map[string]string) bool {
+	current := obj.GetLabels()
+	changed := false
+	for k, v := range labels {
+		if current[k] == v {
+			continue
+		}
+		if current == nil {
+			current = map[string]string{}
+		}
+		current[k] = v
+		changed = true
+	}
+	if changed {
+		obj.SetLabels(current)
+	}
+	return changed
+}
+
+// readyCondition is the Ready condition of page given the rollout of its Deployment.
+func readyCondition(page *frontendv1alpha1.FrontendPage, dep *appsv1.Deployment) metav1.Condition {
+	status := rollout.DeploymentStatus(dep)
+	cond := metav1.Condition{
+		Type:               frontendv1alpha1.ConditionReady,
+		Status:             metav1.ConditionFalse,
+		Reason:             frontendv1alpha1.ReasonRolloutInProgress,
+		Message:            status.Message,
+		ObservedGeneration: page.Generation,
+	}
+	switch status.Phase {
+	case rollout.PhaseComplete:
+		cond.Status, cond.Reason = metav1.ConditionTrue, frontendv1alpha1.ReasonRolloutComplete
+	case rollout.PhaseFailed:
+		cond.Reason = frontendv1alpha1.ReasonRolloutFailed
+	}
+	return cond
+}
+
+// updateStatus writes the page status when the Ready condition or the observed generation
+// changed.
+func (r *FrontendPageReconciler) updateStatus(ctx context.Context, page *frontendv1alpha1.FrontendPage, dep *appsv1.Deployment) error {
+	changed := meta.SetStatusCondition(&page.Status.Conditions, readyCondition(page, dep))
+	if !changed && page.Status.ObservedGeneration == page.Generation {
+		return nil
+	}
+	page.Status.ObservedGeneration = page.Generation
+	return r.Status().Update(ctx, page)
+}
+
+// AddFrontendController registers the FrontendPage controller; namespaces filters its
+// events (nil handles every namespace in the manager's cache).
+func AddFrontendController(mgr manager.Manager, namespaces *scope.Scope) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&frontendv1alpha1.FrontendPage{}).
 		Owns(&appsv1.Deployment{}).
 		Owns(&corev1.ConfigMap{}).
+		WithEventFilter(namespaces.Predicate()).
 		Complete(&FrontendPageReconciler{
 			Client: mgr.GetClient(),
 			Scheme: mgr.GetScheme(),
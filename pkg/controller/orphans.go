@@ -0,0 +1,385 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	frontendv1alpha2 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/frontendBackup"
+	frontendv1alpha1 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/v1alpha1"
+	"github.com/silhouetteUA/k8s-controller/pkg/render"
+	"github.com/silhouetteUA/k8s-controller/pkg/scope"
+)
+
+// Problem classifies a Finding.
+type Problem string
+
+const (
+	// ProblemOrphaned objects belong to a FrontendPage or FrontendPageBackup that no
+	// longer exists, or no longer produces them. They are safe to delete.
+	ProblemOrphaned Problem = "Orphaned"
+	// ProblemDrifted objects have an owner but differ from what the controller builds.
+	ProblemDrifted Problem = "Drifted"
+)
+
+// Finding is an object the orphan scanner flagged.
+type Finding struct {
+	Kind      string    `json:"kind"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	UID       types.UID `json:"uid"`
+	Problem   Problem   `json:"problem"`
+	// Owner is the FrontendPage or FrontendPageBackup the object belongs to, as Kind/name.
+	Owner  string   `json:"owner"`
+	Reason string   `json:"reason"`
+	Drift  []string `json:"drift,omitempty"`
+}
+
+var orphanFindings = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "kctl_orphan_findings",
+	Help: "Objects built for FrontendPages that are orphaned or drifted, by kind and problem, as of the last scan.",
+}, []string{"kind", "problem"})
+
+func init() {
+	metrics.Registry.MustRegister(orphanFindings)
+}
+
+// childOwner resolves which FrontendPage or FrontendPageBackup obj was built for, from
+// its controller reference or, failing that, our labels. ok is false for foreign objects.
+func childOwner(obj metav1.Object) (kind, name string, uid types.UID, ok bool) {
+	if ref := metav1.GetControllerOf(obj); ref != nil {
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err == nil && gv.Group == frontendv1alpha1.SchemeGroupVersion.Group {
+			return ref.Kind, ref.Name, ref.UID, true
+		}
+		return "", "", "", false
+	}
+	labels := obj.GetLabels()
+	if labels[render.LabelManagedBy] == render.ManagedByValue && labels[render.LabelFrontendPage] != "" {
+		return "FrontendPage", labels[render.LabelFrontendPage], "", true
+	}
+	return "", "", "", false
+}
+
+func driftf(field string, want, got any) string {
+	return fmt.Sprintf("%s: want %v, got %v", field, want, got)
+}
+
+func containerNamed(containers []corev1.Container, name string) *corev1.Container {
+	for i := range containers {
+		if containers[i].Name == name {
+			return &containers[i]
+		}
+	}
+	return nil
+}
+
+// deploymentDrift compares the fields render.Deployment sets; fields the API server
+// defaults are ignored.
+func deploymentDrift(want, got *appsv1.Deployment) []string {
+	var drift []string
+	if got.Spec.Replicas == nil || *got.Spec.Replicas != *want.Spec.Replicas {
+		var replicas any = "unset"
+		if got.Spec.Replicas != nil {
+			replicas = *got.Spec.Replicas
+		}
+		drift = append(drift, driftf("spec.replicas", *want.Spec.Replicas, replicas))
+	}
+	if got.Spec.Selector == nil || !reflect.DeepEqual(got.Spec.Selector.MatchLabels, want.Spec.Selector.MatchLabels) {
+		var selector any = "unset"
+		if got.Spec.Selector != nil {
+			selector = got.Spec.Selector.MatchLabels
+		}
+		drift = append(drift, driftf("spec.selector.matchLabels", want.Spec.Selector.MatchLabels, selector))
+	}
+	for _, wc := range want.Spec.Template.Spec.Containers {
+		gc := containerNamed(got.Spec.Template.Spec.Containers, wc.Name)
+		if gc == nil {
+			drift = append(drift, fmt.Sprintf("container %s: missing", wc.Name))
+			continue
+		}
+		if gc.Image != wc.Image {
+			drift = append(drift, driftf("container "+wc.Name+" image", wc.Image, gc.Image))
+		}
+	}
+	for _, wv := range want.Spec.Template.Spec.Volumes {
+		var found *corev1.Volume
+		for i := range got.Spec.Template.Spec.Volumes {
+			if got.Spec.Template.Spec.Volumes[i].Name == wv.Name {
+				found = &got.Spec.Template.Spec.Volumes[i]
+			}
+		}
+		switch {
+		case found == nil:
+			drift = append(drift, fmt.Sprintf("volume %s: missing", wv.Name))
+		case found.ConfigMap == nil || found.ConfigMap.Name != wv.ConfigMap.Name:
+			drift = append(drift, fmt.Sprintf("volume %s: does not mount ConfigMap %s", wv.Name, wv.ConfigMap.Name))
+		}
+	}
+	return drift
+}
+
+func configMapDrift(want, got *corev1.ConfigMap) []string {
+	var drift []string
+	for k, v := range want.Data {
+		if gv, ok := got.Data[k]; !ok {
+			drift = append(drift, fmt.Sprintf("data.%s: missing", k))
+		} else if gv != v {
+			drift = append(drift, fmt.Sprintf("data.%s: differs from the FrontendPage", k))
+		}
+	}
+	return drift
+}
+
+func cronJobDrift(want, got *batchv1.CronJob) []string {
+	var drift []string
+	if got.Spec.Schedule != want.Spec.Schedule {
+		drift = append(drift, driftf("spec.schedule", want.Spec.Schedule, got.Spec.Schedule))
+	}
+	for _, wc := range want.Spec.JobTemplate.Spec.Template.Spec.Containers {
+		gc := containerNamed(got.Spec.JobTemplate.Spec.Template.Spec.Containers, wc.Name)
+		switch {
+		case gc == nil:
+			drift = append(drift, fmt.Sprintf("container %s: missing", wc.Name))
+		case gc.Image != wc.Image:
+			drift = append(drift, driftf("container "+wc.Name+" image", wc.Image, gc.Image))
+		case !reflect.DeepEqual(gc.Args, wc.Args):
+			drift = append(drift, fmt.Sprintf("container %s args: differ from the FrontendPage", wc.Name))
+		}
+	}
+	return drift
+}
+
+// ScanOrphans lists the ConfigMaps, Deployments and CronJobs in namespace (all namespaces
+// when empty) that were built for FrontendPages, and reports those whose owner is gone (or
+// was recreated) and those that drifted from the controller's builders. Findings are
+// sorted by kind, namespace and name.
+func ScanOrphans(ctx context.Context, c client.Reader, namespace string) ([]Finding, error) {
+	var pageList frontendv1alpha1.FrontendPageList
+	if err := c.List(ctx, &pageList, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("listing FrontendPages: %w", err)
+	}
+	pages := map[types.NamespacedName]*frontendv1alpha1.FrontendPage{}
+	for i := range pageList.Items {
+		pages[client.ObjectKeyFromObject(&pageList.Items[i])] = &pageList.Items[i]
+	}
+	var backupList frontendv1alpha2.FrontendPageBackupList
+	if err := c.List(ctx, &backupList, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("listing FrontendPageBackups: %w", err)
+	}
+	backups := map[types.NamespacedName]*frontendv1alpha2.FrontendPageBackup{}
+	for i := range backupList.Items {
+		backups[client.ObjectKeyFromObject(&backupList.Items[i])] = &backupList.Items[i]
+	}
+
+	var findings []Finding
+	// page resolves the live FrontendPage an object claims, or records it as orphaned.
+	page := func(kind string, obj metav1.Object, name string, uid types.UID) *frontendv1alpha1.FrontendPage {
+		f := Finding{Kind: kind, Namespace: obj.GetNamespace(), Name: obj.GetName(), UID: obj.GetUID(), Problem: ProblemOrphaned, Owner: "FrontendPage/" + name}
+		p, ok := pages[types.NamespacedName{Namespace: obj.GetNamespace(), Name: name}]
+		switch {
+		case !ok:
+			f.Reason = fmt.Sprintf("FrontendPage %s no longer exists", name)
+		case uid != "" && p.UID != uid:
+			f.Reason = fmt.Sprintf("owned by a previous FrontendPage %s (uid %s)", name, uid)
+		default:
+			return p
+		}
+		findings = append(findings, f)
+		return nil
+	}
+	drifted := func(kind string, obj metav1.Object, owner string, drift []string) {
+		if len(drift) > 0 {
+			findings = append(findings, Finding{Kind: kind, Namespace: obj.GetNamespace(), Name: obj.GetName(), UID: obj.GetUID(),
+				Problem: ProblemDrifted, Owner: owner, Reason: "differs from the controller's desired state", Drift: drift})
+		}
+	}
+
+	var configMaps corev1.ConfigMapList
+	if err := c.List(ctx, &configMaps, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("listing ConfigMaps: %w", err)
+	}
+	for i := range configMaps.Items {
+		cm := &configMaps.Items[i]
+		if kind, name, uid, ok := childOwner(cm); ok && kind == "FrontendPage" {
+			if p := page("ConfigMap", cm, name, uid); p != nil {
+				drifted("ConfigMap", cm, "FrontendPage/"+name, configMapDrift(render.ConfigMap(p), cm))
+			}
+		}
+	}
+
+	var deployments appsv1.DeploymentList
+	if err := c.List(ctx, &deployments, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("listing Deployments: %w", err)
+	}
+	for i := range deployments.Items {
+		dep := &deployments.Items[i]
+		if kind, name, uid, ok := childOwner(dep); ok && kind == "FrontendPage" {
+			if p := page("Deployment", dep, name, uid); p != nil {
+				drifted("Deployment", dep, "FrontendPage/"+name, deploymentDrift(render.Deployment(p), dep))
+			}
+		}
+	}
+
+	var cronJobs batchv1.CronJobList
+	if err := c.List(ctx, &cronJobs, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("listing CronJobs: %w", err)
+	}
+	for i := range cronJobs.Items {
+		cj := &cronJobs.Items[i]
+		kind, name, uid, ok := childOwner(cj)
+		if !ok {
+			continue
+		}
+		if kind == "FrontendPage" {
+			// Labelled but not owned: all we can check is that the page still exists.
+			page("CronJob", cj, name, "")
+			continue
+		}
+		f := Finding{Kind: "CronJob", Namespace: cj.Namespace, Name: cj.Name, UID: cj.UID, Problem: ProblemOrphaned, Owner: kind + "/" + name}
+		backup, ok := backups[types.NamespacedName{Namespace: cj.Namespace, Name: name}]
+		switch {
+		case !ok:
+			f.Reason = fmt.Sprintf("FrontendPageBackup %s no longer exists", name)
+		case uid != "" && backup.UID != uid:
+			f.Reason = fmt.Sprintf("owned by a previous FrontendPageBackup %s (uid %s)", name, uid)
+		case pages[types.NamespacedName{Namespace: cj.Namespace, Name: backup.Spec.FrontendPageRef}] == nil:
+			f.Reason = fmt.Sprintf("FrontendPageBackup %s references missing FrontendPage %s", name, backup.Spec.FrontendPageRef)
+		default:
+			want := render.CronJob(backup, pages[types.NamespacedName{Namespace: cj.Namespace, Name: backup.Spec.FrontendPageRef}])
+			if want.Name != cj.Name {
+				f.Reason = fmt.Sprintf("superseded by %s after FrontendPageBackup %s changed its frontendPageRef", want.Name, name)
+				break
+			}
+			drifted("CronJob", cj, f.Owner, cronJobDrift(want, cj))
+			continue
+		}
+		findings = append(findings, f)
+	}
+
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].Kind != findings[j].Kind {
+			return findings[i].Kind < findings[j].Kind
+		}
+		if findings[i].Namespace != findings[j].Namespace {
+			return findings[i].Namespace < findings[j].Namespace
+		}
+		return findings[i].Name < findings[j].Name
+	})
+	return findings, nil
+}
+
+// DeleteOrphans deletes the orphaned objects among findings; drifted ones are left for
+// the reconcilers or an operator. The UID precondition makes sure an object recreated
+// since the scan is never deleted. It returns how many objects were deleted.
+func DeleteOrphans(ctx context.Context, c client.Client, findings []Finding) (int, error) {
+	deleted := 0
+	var errs []error
+	for _, f := range findings {
+		if f.Problem != ProblemOrphaned {
+			continue
+		}
+		var obj client.Object
+		switch f.Kind {
+		case "ConfigMap":
+			obj = &corev1.ConfigMap{}
+		case "Deployment":
+			obj = &appsv1.Deployment{}
+		case "CronJob":
+			obj = &batchv1.CronJob{}
+		default:
+			continue
+		}
+		obj.SetNamespace(f.Namespace)
+		obj.SetName(f.Name)
+		err := c.Delete(ctx, obj, client.Preconditions{UID: &f.UID}, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		switch {
+		case err == nil:
+			deleted++
+			log.Info().Msgf("Deleted orphaned %s %s/%s (%s)", f.Kind, f.Namespace, f.Name, f.Reason)
+		case apierrors.IsNotFound(err) || apierrors.IsConflict(err):
+		default:
+			errs = append(errs, fmt.Errorf("deleting %s %s/%s: %w", f.Kind, f.Namespace, f.Name, err))
+		}
+	}
+	return deleted, errors.Join(errs...)
+}
+
+// OrphanScanner runs ScanOrphans periodically on the leader, exports the findings as
+// the kctl_orphan_findings gauge and, with GarbageCollect, deletes orphans.
+type OrphanScanner struct {
+	Client client.Client
+	// Scope selects the namespaces to scan; nil scans all of them.
+	Scope          *scope.Scope
+	Interval       time.Duration
+	GarbageCollect bool
+}
+
+// NeedLeaderElection makes only the leader scan, so replicas don't race on deletes.
+func (s *OrphanScanner) NeedLeaderElection() bool {
+	return true
+}
+
+// Start implements manager.Runnable.
+func (s *OrphanScanner) Start(ctx context.Context) error {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	for {
+		s.scan(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *OrphanScanner) scan(ctx context.Context) {
+	namespaces := s.Scope.Namespaces()
+	if namespaces == nil {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+	var findings []Finding
+	for _, namespace := range namespaces {
+		found, err := ScanOrphans(ctx, s.Client, namespace)
+		if err != nil {
+			log.Error().Err(err).Msg("Orphan scan failed")
+			return
+		}
+		for _, f := range found {
+			if s.Scope.Contains(f.Namespace) {
+				findings = append(findings, f)
+			}
+		}
+	}
+	orphanFindings.Reset()
+	for _, kind := range []string{"ConfigMap", "Deployment", "CronJob"} {
+		for _, problem := range []Problem{ProblemOrphaned, ProblemDrifted} {
+			orphanFindings.WithLabelValues(kind, string(problem)).Set(0)
+		}
+	}
+	for _, f := range findings {
+		orphanFindings.WithLabelValues(f.Kind, string(f.Problem)).Inc()
+		log.Warn().Strs("drift", f.Drift).Msgf("%s %s %s/%s: %s", f.Problem, f.Kind, f.Namespace, f.Name, f.Reason)
+	}
+	if s.GarbageCollect && len(findings) > 0 {
+		if _, err := DeleteOrphans(ctx, s.Client, findings); err != nil {
+			log.Error().Err(err).Msg("Orphan garbage collection failed")
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	frontendv1alpha2 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/frontendBackup"
+	frontendv1alpha1 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/v1alpha1"
+	"github.com/silhouetteUA/k8s-controller/pkg/render"
+)
+
+func orphanScheme(t *testing.T) *runtime.Scheme {
+	s := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(s))
+	require.NoError(t, frontendv1alpha1.AddToScheme(s))
+	require.NoError(t, frontendv1alpha2.AddToScheme(s))
+	return s
+}
+
+func ownedBy[T client.Object](obj T, name, kind, owner string, uid types.UID) T {
+	controller := true
+	obj.SetName(name)
+	obj.SetUID(types.UID(name + "-uid"))
+	obj.SetOwnerReferences([]metav1.OwnerReference{{
+		APIVersion: frontendv1alpha1.SchemeGroupVersion.String(), Kind: kind, Name: owner, UID: uid, Controller: &controller,
+	}})
+	return obj
+}
+
+func TestScanOrphans(t *testing.T) {
+	page := &frontendv1alpha1.FrontendPage{
+		ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "default", UID: "page-uid"},
+		Spec:       frontendv1alpha1.FrontendPageSpec{Contents: "<h1>hi</h1>", Image: "nginx:1.27", Replicas: 2},
+	}
+	backup := &frontendv1alpha2.FrontendPageBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "default", UID: "backup-uid"},
+		Spec:       frontendv1alpha2.FrontendPageBackupSpec{FrontendPageRef: "home", Schedule: "0 0 * * *"},
+	}
+
+	drifted := ownedBy(render.Deployment(page), "home", "FrontendPage", "home", page.UID)
+	replicas := int32(3)
+	drifted.Spec.Replicas = &replicas
+	drifted.Spec.Template.Spec.Containers[0].Image = "nginx:1.25"
+	driftedCron := ownedBy(render.CronJob(backup, page), "backup-home", "FrontendPageBackup", "nightly", backup.UID)
+	driftedCron.Spec.Schedule = "*/5 * * * *"
+
+	labelled := render.Deployment(page)
+	labelled.Name = "labelled"
+	labelled.Labels[render.LabelFrontendPage] = "gone"
+
+	c := fake.NewClientBuilder().WithScheme(orphanScheme(t)).WithObjects(
+		page, backup, drifted, driftedCron, labelled,
+		ownedBy(render.ConfigMap(page), "home", "FrontendPage", "home", page.UID),
+		ownedBy(render.ConfigMap(page), "stale", "FrontendPage", "gone", "gone-uid"),
+		ownedBy(render.Deployment(page), "recreated", "FrontendPage", "home", "old-uid"),
+		ownedBy(render.CronJob(backup, page), "backup-old", "FrontendPageBackup", "nightly", backup.UID),
+		ownedBy(render.CronJob(backup, page), "backup-deleted", "FrontendPageBackup", "deleted", "deleted-uid"),
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: "default"}},
+	).Build()
+
+	ctx := context.Background()
+	findings, err := ScanOrphans(ctx, c, "default")
+	require.NoError(t, err)
+	type row struct {
+		kind, name string
+		problem    Problem
+	}
+	var got []row
+	for _, f := range findings {
+		got = append(got, row{f.Kind, f.Name, f.Problem})
+	}
+	require.Equal(t, []row{
+		{"ConfigMap", "stale", ProblemOrphaned},
+		{"CronJob", "backup-deleted", ProblemOrphaned},
+		{"CronJob", "backup-home", ProblemDrifted},
+		{"CronJob", "backup-old", ProblemOrphaned},
+		{"Deployment", "home", ProblemDrifted},
+		{"Deployment", "labelled", ProblemOrphaned},
+		{"Deployment", "recreated", ProblemOrphaned},
+	}, got)
+	require.Equal(t, []string{"spec.schedule: want 0 0 * * *, got */5 * * * *"}, findings[2].Drift)
+	require.Contains(t, findings[3].Reason, "superseded by backup-home")
+	require.Equal(t, []string{
+		"spec.replicas: want 2, got 3",
+		"container frontend image: want nginx:1.27, got nginx:1.25",
+	}, findings[4].Drift)
+	require.Contains(t, findings[6].Reason, "previous FrontendPage home")
+
+	deleted, err := DeleteOrphans(ctx, c, findings)
+	require.NoError(t, err)
+	require.Equal(t, 5, deleted)
+
+	findings, err = ScanOrphans(ctx, c, "default")
+	require.NoError(t, err)
+	require.Len(t, findings, 2, "drifted objects are reported but never deleted")
+	var cron batchv1.CronJob
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "backup-home"}, &cron))
+}
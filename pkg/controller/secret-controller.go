@@ -2,33 +2,58 @@ package controller
 
 import (
 	context "context"
+	"errors"
+
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 
 	"github.com/rs/zerolog/log"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/silhouetteUA/k8s-controller/pkg/scope"
 )
 
 type SecretReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
 }
 
+// Reconcile rolls the opted-in Deployments that consume the Secret when its data changes.
 func (r *SecretReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log.Info().Msgf("Reconciling Secret: %s/%s", req.Namespace, req.Name)
-	return ctrl.Result{}, nil
+	var deployments appsv1.DeploymentList
+	if err := r.List(ctx, &deployments, client.InNamespace(req.Namespace), client.MatchingFields{secretRefIndex: req.Name}); err != nil {
+		return ctrl.Result{}, err
+	}
+	var errs []error
+	for i := range deployments.Items {
+		if _, err := syncSecretHash(ctx, r.Client, r.Recorder, &deployments.Items[i], req.Name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return ctrl.Result{}, errors.Join(errs...)
 }
 
-func AddSecretController(mgr manager.Manager) error {
+// AddSecretController registers the Secret controller; namespaces filters its events
+// (nil handles every namespace in the manager's cache).
+func AddSecretController(mgr manager.Manager, namespaces *scope.Scope) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &appsv1.Deployment{}, secretRefIndex, secretRefIndexFunc); err != nil {
+		return err
+	}
 	r := &SecretReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("kctl-secret-controller"),
 	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1.Secret{}).
+		WithEventFilter(namespaces.Predicate()).
 		WithOptions(controller.Options{MaxConcurrentReconciles: 1}).
 		Complete(r)
 }
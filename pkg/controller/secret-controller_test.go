@@ -17,7 +17,7 @@ func TestSecretReconciler_BasicFlow(t *testing.T) {
 	defer cleanup()
 
 	// Register the Secret controller before starting the manager
-	err := AddSecretController(mgr)
+	err := AddSecretController(mgr, nil)
 	require.NoError(t, err)
 
 	go func() {
@@ -0,0 +1,151 @@
+package controller
+
+import (
+	context "context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/rs/zerolog/log"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// AnnotationRestartOnSecretChange opts a Deployment in to rolling restarts when a
+	// Secret it consumes changes. Set it to "true" on the Deployment's metadata.
+	AnnotationRestartOnSecretChange = "kctl.silhouetteua.io/restart-on-secret-change"
+	// AnnotationSecretHash is set on the pod template of opted-in Deployments. It is a
+	// hash over the data of every Secret the pods consume, so changing it rolls the pods.
+	AnnotationSecretHash = "kctl.silhouetteua.io/secret-hash"
+
+	// secretRefIndex indexes Deployments by the names of the Secrets their pods consume.
+	secretRefIndex = ".spec.template.secretRefs"
+
+	// Event reasons emitted on Deployments.
+	reasonSecretRotated       = "SecretRotated"
+	reasonSecretRolloutFailed = "SecretRolloutFailed"
+)
+
+// restartsOnSecretChange reports whether dep opted in to secret-driven rollouts.
+func restartsOnSecretChange(dep *appsv1.Deployment) bool {
+	return dep.Annotations[AnnotationRestartOnSecretChange] == "true"
+}
+
+// SecretsReferencedBy returns the names of the Secrets a pod spec consumes through env,
+// envFrom, secret volumes and projected volumes, sorted.
+func SecretsReferencedBy(spec *corev1.PodSpec) []string {
+	names := sets.New[string]()
+	for _, containers := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+		for _, c := range containers {
+			for _, env := range c.Env {
+				if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+					names.Insert(env.ValueFrom.SecretKeyRef.Name)
+				}
+			}
+			for _, from := range c.EnvFrom {
+				if from.SecretRef != nil {
+					names.Insert(from.SecretRef.Name)
+				}
+			}
+		}
+	}
+	for _, v := range spec.Volumes {
+		if v.Secret != nil {
+			names.Insert(v.Secret.SecretName)
+		}
+		if v.Projected != nil {
+			for _, src := range v.Projected.Sources {
+				if src.Secret != nil {
+					names.Insert(src.Secret.Name)
+				}
+			}
+		}
+	}
+	names.Delete("")
+	return sets.List(names)
+}
+
+// secretRefIndexFunc feeds secretRefIndex.
+func secretRefIndexFunc(obj client.Object) []string {
+	dep, ok := obj.(*appsv1.Deployment)
+	if !ok {
+		return nil
+	}
+	return SecretsReferencedBy(&dep.Spec.Template.Spec)
+}
+
+// secretDataHash hashes the named Secrets' data in a stable order. Missing Secrets are
+// part of the hash, so re-creating one also triggers a rollout.
+func secretDataHash(ctx context.Context, c client.Client, namespace string, names []string) (string, error) {
+	h := sha256.New()
+	for _, name := range names {
+		var secret corev1.Secret
+		err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &secret)
+		switch {
+		case apierrors.IsNotFound(err):
+			fmt.Fprintf(h, "%s:missing\n", name)
+			continue
+		case err != nil:
+			return "", err
+		}
+		keys := make([]string, 0, len(secret.Data))
+		for k := range secret.Data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		fmt.Fprintf(h, "%s:%d\n", name, len(keys))
+		for _, k := range keys {
+			fmt.Fprintf(h, "%s=%d:", k, len(secret.Data[k]))
+			h.Write(secret.Data[k])
+			h.Write([]byte{'\n'})
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16], nil
+}
+
+// syncSecretHash sets AnnotationSecretHash on the pod template of an opted-in Deployment
+// and returns whether it changed, i.e. whether a rollout was triggered. trigger names
+// the Secret that caused the check, for the Event; it may be empty.
+func syncSecretHash(ctx context.Context, c client.Client, recorder record.EventRecorder, dep *appsv1.Deployment, trigger string) (bool, error) {
+	if !restartsOnSecretChange(dep) {
+		return false, nil
+	}
+	names := SecretsReferencedBy(&dep.Spec.Template.Spec)
+	if len(names) == 0 {
+		return false, nil
+	}
+	hash, err := secretDataHash(ctx, c, dep.Namespace, names)
+	if err != nil {
+		return false, err
+	}
+	previous, recorded := dep.Spec.Template.Annotations[AnnotationSecretHash]
+	if previous == hash {
+		return false, nil
+	}
+	patch := client.MergeFrom(dep.DeepCopy())
+	if dep.Spec.Template.Annotations == nil {
+		dep.Spec.Template.Annotations = map[string]string{}
+	}
+	dep.Spec.Template.Annotations[AnnotationSecretHash] = hash
+	if err := c.Patch(ctx, dep, patch); err != nil {
+		recorder.Eventf(dep, corev1.EventTypeWarning, reasonSecretRolloutFailed, "Failed to roll out secret hash %s: %v", hash, err)
+		return false, err
+	}
+	switch {
+	case trigger != "":
+		recorder.Eventf(dep, corev1.EventTypeNormal, reasonSecretRotated, "Secret %s changed, restarting pods (secret hash %s)", trigger, hash)
+	case recorded:
+		recorder.Eventf(dep, corev1.EventTypeNormal, reasonSecretRotated, "Consumed Secrets changed, restarting pods (secret hash %s)", hash)
+	default:
+		recorder.Eventf(dep, corev1.EventTypeNormal, reasonSecretRotated, "Recorded secret hash %s for %v", hash, names)
+	}
+	log.Info().Msgf("Rolled out secret hash %s to Deployment %s/%s", hash, dep.Namespace, dep.Name)
+	return true, nil
+}
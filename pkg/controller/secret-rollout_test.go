@@ -0,0 +1,125 @@
+package controller
+
+import (
+	context "context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func secretConsumer(name string, optIn bool, spec corev1.PodSpec) *appsv1.Deployment {
+	dep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: spec}},
+	}
+	if optIn {
+		dep.Annotations = map[string]string{AnnotationRestartOnSecretChange: "true"}
+	}
+	return dep
+}
+
+func TestSecretsReferencedBy(t *testing.T) {
+	spec := corev1.PodSpec{
+		InitContainers: []corev1.Container{{EnvFrom: []corev1.EnvFromSource{{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "init"}}}}}},
+		Containers: []corev1.Container{{Env: []corev1.EnvVar{
+			{Name: "PLAIN", Value: "x"},
+			{Name: "PASSWORD", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "db"}, Key: "password"}}},
+		}}},
+		Volumes: []corev1.Volume{
+			{Name: "tls", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "tls"}}},
+			{Name: "bundle", VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{Sources: []corev1.VolumeProjection{
+				{Secret: &corev1.SecretProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "ca"}}},
+				{ConfigMap: &corev1.ConfigMapProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "not-a-secret"}}},
+			}}}},
+		},
+	}
+	require.Equal(t, []string{"ca", "db", "init", "tls"}, SecretsReferencedBy(&spec))
+}
+
+func TestSecretReconciler_RollsOptedInConsumers(t *testing.T) {
+	envSpec := corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Env: []corev1.EnvVar{{Name: "PASSWORD",
+		ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "db"}, Key: "password"}}}}}}}
+	volumeSpec := corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}, Volumes: []corev1.Volume{{Name: "creds",
+		VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "db"}}}}}
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"}, Data: map[string][]byte{"password": []byte("hunter2")}}
+
+	c := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithIndex(&appsv1.Deployment{}, secretRefIndex, secretRefIndexFunc).
+		WithObjects(secret,
+			secretConsumer("api", true, envSpec),
+			secretConsumer("worker", true, volumeSpec),
+			secretConsumer("legacy", false, envSpec),
+			secretConsumer("unrelated", true, corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}}),
+		).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &SecretReconciler{Client: c, Scheme: scheme.Scheme, Recorder: recorder}
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "db"}}
+
+	templateHash := func(name string) string {
+		var dep appsv1.Deployment
+		require.NoError(t, c.Get(ctx, types.NamespacedName{Namespace: "default", Name: name}, &dep))
+		return dep.Spec.Template.Annotations[AnnotationSecretHash]
+	}
+
+	_, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	first := templateHash("api")
+	require.NotEmpty(t, first)
+	require.Equal(t, first, templateHash("worker"))
+	require.Empty(t, templateHash("legacy"), "Deployments must opt in")
+	require.Empty(t, templateHash("unrelated"))
+	require.Len(t, recorder.Events, 2)
+	<-recorder.Events
+	<-recorder.Events
+
+	// Reconciling an unchanged Secret is a no-op.
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, first, templateHash("api"))
+	require.Empty(t, recorder.Events)
+
+	// Rotating the data rolls both consumers and says why.
+	secret.Data["password"] = []byte("correct-horse")
+	require.NoError(t, c.Update(ctx, secret))
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	require.NotEqual(t, first, templateHash("api"))
+	require.Equal(t, templateHash("api"), templateHash("worker"))
+	require.Contains(t, <-recorder.Events, "Normal SecretRotated Secret db changed")
+
+	// Label-only changes don't.
+	rotated := templateHash("api")
+	secret.Labels = map[string]string{"team": "web"}
+	require.NoError(t, c.Update(ctx, secret))
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, rotated, templateHash("api"))
+}
+
+func TestDeploymentReconciler_RecordsSecretHash(t *testing.T) {
+	spec := corev1.PodSpec{Containers: []corev1.Container{{Name: "app", EnvFrom: []corev1.EnvFromSource{
+		{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "missing"}}}}}}}
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(secretConsumer("api", true, spec)).Build()
+	r := &DeploymentReconciler{Client: c, Scheme: scheme.Scheme, Recorder: record.NewFakeRecorder(10)}
+	ctx := context.Background()
+
+	_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "api"}})
+	require.NoError(t, err)
+	var dep appsv1.Deployment
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "api"}, &dep))
+	require.NotEmpty(t, dep.Spec.Template.Annotations[AnnotationSecretHash], "missing Secrets are hashed too")
+
+	_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "gone"}})
+	require.NoError(t, err)
+}
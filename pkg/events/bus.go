@@ -0,0 +1,233 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Drop reasons reported in SinkStats and the kctl_events_dropped_total metric.
+const (
+	DropQueueFull = "queue_full"
+	DropRetries   = "retries_exhausted"
+	DropPermanent = "permanent_error"
+	DropShutdown  = "shutdown"
+)
+
+// Sink delivers events to one destination. Send is retried with backoff unless it
+// returns an error wrapped with Permanent.
+type Sink interface {
+	Name() string
+	Send(ctx context.Context, e Event) error
+}
+
+type permanentError struct{ err error }
+
+func (p permanentError) Error() string { return p.err.Error() }
+func (p permanentError) Unwrap() error { return p.err }
+
+// Permanent marks err as not worth retrying, e.g. a webhook answering 400.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err: err}
+}
+
+// Options configures the per-sink queues of a Bus.
+type Options struct {
+	// QueueSize bounds each sink's queue; events published to a full queue are dropped.
+	QueueSize int
+	// MaxAttempts is how many times Send is tried per event.
+	MaxAttempts int
+	// InitialBackoff doubles after each failed attempt, up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// Defaults used for zero Options fields.
+const (
+	DefaultQueueSize      = 1024
+	DefaultMaxAttempts    = 5
+	DefaultInitialBackoff = 100 * time.Millisecond
+	DefaultMaxBackoff     = 10 * time.Second
+)
+
+func (o Options) withDefaults() Options {
+	if o.QueueSize <= 0 {
+		o.QueueSize = DefaultQueueSize
+	}
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = DefaultMaxAttempts
+	}
+	if o.InitialBackoff <= 0 {
+		o.InitialBackoff = DefaultInitialBackoff
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = DefaultMaxBackoff
+	}
+	return o
+}
+
+// SinkStats are the delivery counters of one sink.
+type SinkStats struct {
+	Sent    uint64            `json:"sent"`
+	Retries uint64            `json:"retries"`
+	Queued  int               `json:"queued"`
+	Dropped map[string]uint64 `json:"dropped"`
+}
+
+type sinkWorker struct {
+	sink  Sink
+	queue chan Event
+
+	sent    atomic.Uint64
+	retries atomic.Uint64
+	dropped sync.Map // reason -> *atomic.Uint64
+}
+
+func (w *sinkWorker) drop(reason string) {
+	v, _ := w.dropped.LoadOrStore(reason, new(atomic.Uint64))
+	v.(*atomic.Uint64).Add(1)
+	droppedTotal.WithLabelValues(w.sink.Name(), reason).Inc()
+}
+
+// Bus fans events out to sinks. Every sink has its own bounded queue and worker, so a
+// slow or failing sink never blocks the informers or the other sinks.
+type Bus struct {
+	opts    Options
+	workers []*sinkWorker
+
+	mu      sync.RWMutex
+	started bool
+	closed  bool
+	wg      sync.WaitGroup
+}
+
+// NewBus creates a bus delivering to sinks. Events are queued but not sent until Start.
+func NewBus(opts Options, sinks ...Sink) *Bus {
+	b := &Bus{opts: opts.withDefaults()}
+	for _, s := range sinks {
+		b.workers = append(b.workers, &sinkWorker{sink: s, queue: make(chan Event, b.opts.QueueSize)})
+	}
+	return b
+}
+
+// Publish queues e on every sink without blocking.
+func (b *Bus) Publish(e Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return
+	}
+	for _, w := range b.workers {
+		select {
+		case w.queue <- e:
+			queueLength.WithLabelValues(w.sink.Name()).Set(float64(len(w.queue)))
+		default:
+			w.drop(DropQueueFull)
+		}
+	}
+}
+
+// Start runs one delivery worker per sink. Retries stop when ctx is cancelled.
+func (b *Bus) Start(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.started {
+		return errors.New("event bus already started")
+	}
+	b.started = true
+	for _, w := range b.workers {
+		b.wg.Add(1)
+		go func(w *sinkWorker) {
+			defer b.wg.Done()
+			b.run(ctx, w)
+		}(w)
+	}
+	return nil
+}
+
+// Close stops accepting events, waits for the workers to drain their queues and closes
+// sinks implementing io.Closer. Cancel the Start context first to skip remaining retries.
+func (b *Bus) Close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	for _, w := range b.workers {
+		close(w.queue)
+	}
+	b.mu.Unlock()
+	b.wg.Wait()
+
+	var errs []error
+	for _, w := range b.workers {
+		if c, ok := w.sink.(io.Closer); ok {
+			errs = append(errs, c.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Stats returns the delivery counters of every sink, keyed by sink name.
+func (b *Bus) Stats() map[string]SinkStats {
+	out := make(map[string]SinkStats, len(b.workers))
+	for _, w := range b.workers {
+		st := SinkStats{Sent: w.sent.Load(), Retries: w.retries.Load(), Queued: len(w.queue), Dropped: map[string]uint64{}}
+		w.dropped.Range(func(k, v any) bool {
+			st.Dropped[k.(string)] = v.(*atomic.Uint64).Load()
+			return true
+		})
+		out[w.sink.Name()] = st
+	}
+	return out
+}
+
+func (b *Bus) run(ctx context.Context, w *sinkWorker) {
+	for e := range w.queue {
+		queueLength.WithLabelValues(w.sink.Name()).Set(float64(len(w.queue)))
+		b.deliver(ctx, w, e)
+	}
+}
+
+func (b *Bus) deliver(ctx context.Context, w *sinkWorker, e Event) {
+	backoff := b.opts.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		if ctx.Err() != nil {
+			w.drop(DropShutdown)
+			return
+		}
+		err := w.sink.Send(ctx, e)
+		if err == nil {
+			w.sent.Add(1)
+			sentTotal.WithLabelValues(w.sink.Name()).Inc()
+			return
+		}
+		var perm permanentError
+		if errors.As(err, &perm) {
+			log.Warn().Err(err).Str("sink", w.sink.Name()).Msgf("Dropping event %s", e)
+			w.drop(DropPermanent)
+			return
+		}
+		if attempt >= b.opts.MaxAttempts {
+			log.Warn().Err(err).Str("sink", w.sink.Name()).Msgf("Dropping event %s after %d attempts", e, attempt)
+			w.drop(DropRetries)
+			return
+		}
+		w.retries.Add(1)
+		retriesTotal.WithLabelValues(w.sink.Name()).Inc()
+		select {
+		case <-ctx.Done():
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, b.opts.MaxBackoff)
+	}
+}
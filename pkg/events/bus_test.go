@@ -0,0 +1,112 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSink fails the first failures sends, then records events.
+type recordingSink struct {
+	name     string
+	mu       sync.Mutex
+	failures int
+	err      error
+	got      []Event
+	block    chan struct{}
+}
+
+func (s *recordingSink) Name() string { return s.name }
+
+func (s *recordingSink) Send(ctx context.Context, e Event) error {
+	if s.block != nil {
+		select {
+		case <-s.block:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures > 0 {
+		s.failures--
+		return s.err
+	}
+	s.got = append(s.got, e)
+	return nil
+}
+
+func (s *recordingSink) events() []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Event(nil), s.got...)
+}
+
+var fastRetries = Options{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+
+func TestBus_RetriesWithBackoff(t *testing.T) {
+	flaky := &recordingSink{name: "flaky", failures: 2, err: errors.New("unavailable")}
+	ok := &recordingSink{name: "ok"}
+	bus := NewBus(fastRetries, flaky, ok)
+	require.NoError(t, bus.Start(context.Background()))
+
+	bus.Publish(Event{Type: Added, Kind: "Deployment", Name: "web"})
+	require.NoError(t, bus.Close())
+
+	require.Len(t, flaky.events(), 1)
+	require.Len(t, ok.events(), 1)
+	stats := bus.Stats()
+	require.Equal(t, uint64(1), stats["flaky"].Sent)
+	require.Equal(t, uint64(2), stats["flaky"].Retries)
+	require.Empty(t, stats["flaky"].Dropped)
+}
+
+func TestBus_DropsAfterMaxAttemptsAndPermanentErrors(t *testing.T) {
+	down := &recordingSink{name: "down", failures: 100, err: errors.New("unavailable")}
+	rejecting := &recordingSink{name: "rejecting", failures: 100, err: Permanent(errors.New("bad request"))}
+	bus := NewBus(fastRetries, down, rejecting)
+	require.NoError(t, bus.Start(context.Background()))
+
+	bus.Publish(Event{Type: Added, Kind: "Deployment", Name: "web"})
+	require.NoError(t, bus.Close())
+
+	stats := bus.Stats()
+	require.Equal(t, uint64(1), stats["down"].Dropped[DropRetries])
+	require.Equal(t, uint64(2), stats["down"].Retries)
+	require.Equal(t, uint64(1), stats["rejecting"].Dropped[DropPermanent])
+	require.Zero(t, stats["rejecting"].Retries)
+}
+
+func TestBus_BoundedQueueDoesNotBlockPublishers(t *testing.T) {
+	stuck := &recordingSink{name: "stuck", block: make(chan struct{})}
+	ok := &recordingSink{name: "ok"}
+	bus := NewBus(Options{QueueSize: 2}, stuck, ok)
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, bus.Start(ctx))
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 10; i++ {
+			bus.Publish(Event{Type: Added, Kind: "Secret", Name: "s"})
+			time.Sleep(time.Millisecond)
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Publish blocked on a stuck sink")
+	}
+
+	require.NotZero(t, bus.Stats()["stuck"].Dropped[DropQueueFull])
+	cancel()
+	require.NoError(t, bus.Close())
+	require.Len(t, ok.events(), 10, "a stuck sink must not affect the others")
+	require.NotZero(t, bus.Stats()["stuck"].Dropped[DropShutdown])
+
+	bus.Publish(Event{Type: Added, Kind: "Secret", Name: "late"}) // no panic after Close
+}
@@ -0,0 +1,174 @@
+package events
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Type is the kind of change an Event records.
+type Type string
+
+// Change types produced by the informer handlers.
+const (
+	Added   Type = "Added"
+	Updated Type = "Updated"
+	Deleted Type = "Deleted"
+)
+
+// redacted replaces values that must never leave the process, such as Secret data.
+const redacted = "<redacted>"
+
+// FieldChange is one field that differs between the old and new object of an update.
+// Path is dot separated; list values are compared and reported as a whole.
+type FieldChange struct {
+	Path string `json:"path"`
+	Old  any    `json:"old,omitempty"`
+	New  any    `json:"new,omitempty"`
+}
+
+// Event is a structured change to a watched object.
+type Event struct {
+	Type            Type          `json:"type"`
+	Kind            string        `json:"kind"`
+	Namespace       string        `json:"namespace,omitempty"`
+	Name            string        `json:"name"`
+	ResourceVersion string        `json:"resourceVersion,omitempty"`
+	Time            time.Time     `json:"time"`
+	Diff            []FieldChange `json:"diff,omitempty"`
+}
+
+// String renders the event as "Kind type: namespace/name", the format of the old log lines.
+func (e Event) String() string {
+	name := e.Name
+	if e.Namespace != "" {
+		name = e.Namespace + "/" + e.Name
+	}
+	return fmt.Sprintf("%s %s: %s", e.Kind, strings.ToLower(string(e.Type)), name)
+}
+
+// Publisher accepts events. Publish must not block the informer handler calling it.
+type Publisher interface {
+	Publish(e Event)
+}
+
+// New builds an Added or Deleted event for obj. Tombstones from delete handlers are
+// unwrapped. kind is used when the object carries no TypeMeta, as typed informer
+// objects don't.
+func New(t Type, kind string, obj any) Event {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	e := Event{Type: t, Kind: kind, Time: time.Now().UTC()}
+	if ro, ok := obj.(runtime.Object); ok {
+		if k := ro.GetObjectKind().GroupVersionKind().Kind; k != "" {
+			e.Kind = k
+		}
+	}
+	if m, err := meta.Accessor(obj); err == nil {
+		e.Namespace = m.GetNamespace()
+		e.Name = m.GetName()
+		e.ResourceVersion = m.GetResourceVersion()
+	}
+	return e
+}
+
+// NewUpdate builds an Updated event for newObj carrying the field-level diff from oldObj.
+func NewUpdate(kind string, oldObj, newObj any) Event {
+	e := New(Updated, kind, newObj)
+	e.Diff = Diff(oldObj, newObj)
+	if e.Kind == "Secret" {
+		redactSecretChanges(e.Diff)
+	}
+	return e
+}
+
+// ignoredPaths change on every write and carry no information for consumers.
+var ignoredPaths = map[string]bool{
+	"metadata.resourceVersion": true,
+	"metadata.managedFields":   true,
+}
+
+// Diff returns the fields that differ between oldObj and newObj, sorted by path.
+// Objects that cannot be converted to unstructured content produce no diff.
+func Diff(oldObj, newObj any) []FieldChange {
+	oldMap, err := toUnstructured(oldObj)
+	if err != nil {
+		return nil
+	}
+	newMap, err := toUnstructured(newObj)
+	if err != nil {
+		return nil
+	}
+	var changes []FieldChange
+	diffMaps("", oldMap, newMap, &changes)
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+func toUnstructured(obj any) (map[string]any, error) {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		return u.Object, nil
+	}
+	return runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+}
+
+func diffMaps(prefix string, oldMap, newMap map[string]any, changes *[]FieldChange) {
+	for k, oldVal := range oldMap {
+		path := joinPath(prefix, k)
+		if ignoredPaths[path] {
+			continue
+		}
+		newVal, ok := newMap[k]
+		if !ok {
+			*changes = append(*changes, FieldChange{Path: path, Old: oldVal})
+			continue
+		}
+		oldChild, oldIsMap := oldVal.(map[string]any)
+		newChild, newIsMap := newVal.(map[string]any)
+		if oldIsMap && newIsMap {
+			diffMaps(path, oldChild, newChild, changes)
+			continue
+		}
+		if !reflect.DeepEqual(oldVal, newVal) {
+			*changes = append(*changes, FieldChange{Path: path, Old: oldVal, New: newVal})
+		}
+	}
+	for k, newVal := range newMap {
+		path := joinPath(prefix, k)
+		if _, ok := oldMap[k]; ok || ignoredPaths[path] {
+			continue
+		}
+		*changes = append(*changes, FieldChange{Path: path, New: newVal})
+	}
+}
+
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// redactSecretChanges keeps the paths of changed Secret keys but drops their values,
+// including the copy kubectl keeps in the last-applied annotation.
+func redactSecretChanges(changes []FieldChange) {
+	for i, c := range changes {
+		if c.Path == "data" || c.Path == "stringData" || strings.HasPrefix(c.Path, "data.") ||
+			strings.HasPrefix(c.Path, "stringData.") || strings.HasPrefix(c.Path, "metadata.annotations") {
+			if c.Old != nil {
+				changes[i].Old = redacted
+			}
+			if c.New != nil {
+				changes[i].New = redacted
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func deployment(rv string, replicas int32, image string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", ResourceVersion: rv},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "web", Image: image}}}},
+		},
+	}
+}
+
+func TestNew(t *testing.T) {
+	e := New(Added, "Deployment", deployment("7", 1, "nginx"))
+	require.Equal(t, Added, e.Type)
+	require.Equal(t, "Deployment", e.Kind)
+	require.Equal(t, "default", e.Namespace)
+	require.Equal(t, "web", e.Name)
+	require.Equal(t, "7", e.ResourceVersion)
+	require.Equal(t, "Deployment added: default/web", e.String())
+
+	e = New(Deleted, "Deployment", cache.DeletedFinalStateUnknown{Key: "default/web", Obj: deployment("8", 1, "nginx")})
+	require.Equal(t, "web", e.Name)
+	require.Equal(t, "8", e.ResourceVersion)
+}
+
+func TestNewUpdate_Diff(t *testing.T) {
+	e := NewUpdate("Deployment", deployment("7", 1, "nginx:1.26"), deployment("8", 3, "nginx:1.27"))
+	require.Equal(t, Updated, e.Type)
+	require.Equal(t, "8", e.ResourceVersion)
+	require.Len(t, e.Diff, 2, "resourceVersion changes are not reported: %+v", e.Diff)
+	require.Equal(t, "spec.replicas", e.Diff[0].Path)
+	require.EqualValues(t, 1, e.Diff[0].Old)
+	require.EqualValues(t, 3, e.Diff[0].New)
+	require.Equal(t, "spec.template.spec.containers", e.Diff[1].Path)
+}
+
+func TestNewUpdate_RedactsSecrets(t *testing.T) {
+	oldSecret := &corev1.Secret{
+		TypeMeta:   metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+		Data:       map[string][]byte{"password": []byte("hunter2")},
+	}
+	newSecret := oldSecret.DeepCopy()
+	newSecret.Data["password"] = []byte("correct-horse")
+	newSecret.Data["user"] = []byte("admin")
+	newSecret.Annotations = map[string]string{"kubectl.kubernetes.io/last-applied-configuration": `{"data":{"password":"Y29ycmVjdC1ob3JzZQ=="}}`}
+
+	e := NewUpdate("Secret", oldSecret, newSecret)
+	var paths []string
+	for _, c := range e.Diff {
+		paths = append(paths, c.Path)
+		require.Equal(t, redacted, c.New)
+		if c.Old != nil {
+			require.Equal(t, redacted, c.Old)
+		}
+	}
+	require.Equal(t, []string{"data.password", "data.user", "metadata.annotations"}, paths)
+}
@@ -0,0 +1,31 @@
+package events
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// The counters are registered with the controller-runtime registry, so they are served
+// on the manager's metrics endpoint (--metrics-port).
+var (
+	sentTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kctl_events_sent_total",
+		Help: "Change events delivered, by sink.",
+	}, []string{"sink"})
+	retriesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kctl_events_retries_total",
+		Help: "Failed change event deliveries that were retried, by sink.",
+	}, []string{"sink"})
+	droppedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kctl_events_dropped_total",
+		Help: "Change events dropped, by sink and reason.",
+	}, []string{"sink", "reason"})
+	queueLength = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kctl_events_queue_length",
+		Help: "Change events waiting for delivery, by sink.",
+	}, []string{"sink"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(sentTotal, retriesTotal, droppedTotal, queueLength)
+}
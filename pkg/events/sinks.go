@@ -0,0 +1,130 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// LogSink writes every event as a structured log line.
+type LogSink struct{}
+
+// Name implements Sink.
+func (LogSink) Name() string { return "log" }
+
+// Send implements Sink.
+func (LogSink) Send(_ context.Context, e Event) error {
+	ev := log.Info().
+		Str("type", string(e.Type)).
+		Str("kind", e.Kind).
+		Str("namespace", e.Namespace).
+		Str("name", e.Name).
+		Str("resourceVersion", e.ResourceVersion)
+	if len(e.Diff) > 0 {
+		paths := make([]string, 0, len(e.Diff))
+		for _, c := range e.Diff {
+			paths = append(paths, c.Path)
+		}
+		ev = ev.Strs("changed", paths)
+	}
+	ev.Msg(e.String())
+	return nil
+}
+
+// WebhookSink POSTs every event as JSON to URL. 5xx, 429 and transport errors are
+// retried; other non-2xx answers drop the event.
+type WebhookSink struct {
+	URL     string
+	Headers map[string]string
+	Client  *http.Client
+}
+
+// NewWebhookSink returns a WebhookSink with a 5s request timeout.
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{URL: url, Client: &http.Client{Timeout: 5 * time.Second}}
+}
+
+// Name implements Sink.
+func (s *WebhookSink) Name() string { return "webhook" }
+
+// Send implements Sink.
+func (s *WebhookSink) Send(ctx context.Context, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return Permanent(err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return Permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range s.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("webhook %s answered %s", s.URL, resp.Status)
+	default:
+		return Permanent(fmt.Errorf("webhook %s answered %s", s.URL, resp.Status))
+	}
+}
+
+// FileJournal appends every event as one JSON line to a file.
+type FileJournal struct {
+	mu   sync.Mutex
+	file *os.File
+	sync bool
+}
+
+// NewFileJournal opens (or creates) path for appending. With fsync set, every event is
+// flushed to disk before Send returns.
+func NewFileJournal(path string, fsync bool) (*FileJournal, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileJournal{file: f, sync: fsync}, nil
+}
+
+// Name implements Sink.
+func (j *FileJournal) Name() string { return "file" }
+
+// Send implements Sink.
+func (j *FileJournal) Send(_ context.Context, e Event) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return Permanent(err)
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if j.sync {
+		return j.file.Sync()
+	}
+	return nil
+}
+
+// Close closes the journal file.
+func (j *FileJournal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.file.Close()
+}
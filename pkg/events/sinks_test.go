@@ -0,0 +1,157 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testEvent = Event{Type: Updated, Kind: "Deployment", Namespace: "default", Name: "web", ResourceVersion: "8",
+	Diff: []FieldChange{{Path: "spec.replicas", Old: 1, New: 3}}}
+
+func TestWebhookSink(t *testing.T) {
+	var calls atomic.Int32
+	var status atomic.Int32
+	status.Store(http.StatusOK)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		var e Event
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&e))
+		require.Equal(t, "web", e.Name)
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer srv.Close()
+
+	sink := NewWebhookSink(srv.URL)
+	require.NoError(t, sink.Send(context.Background(), testEvent))
+
+	status.Store(http.StatusServiceUnavailable)
+	err := sink.Send(context.Background(), testEvent)
+	require.Error(t, err)
+	require.NotErrorAs(t, err, &permanentError{})
+
+	status.Store(http.StatusBadRequest)
+	err = sink.Send(context.Background(), testEvent)
+	require.ErrorAs(t, err, &permanentError{})
+	require.Equal(t, int32(3), calls.Load())
+}
+
+func TestFileJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	journal, err := NewFileJournal(path, true)
+	require.NoError(t, err)
+	require.NoError(t, journal.Send(context.Background(), testEvent))
+	require.NoError(t, journal.Send(context.Background(), Event{Type: Deleted, Kind: "Secret", Name: "db"}))
+	require.NoError(t, journal.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	var e Event
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &e))
+	require.Equal(t, "spec.replicas", e.Diff[0].Path)
+}
+
+func TestStreamSink_MemoryStream(t *testing.T) {
+	stream := NewMemoryStream()
+	bus := NewBus(Options{}, &StreamSink{Client: stream, Subject: "kctl.events", PerKind: true})
+	require.NoError(t, bus.Start(context.Background()))
+	bus.Publish(testEvent)
+	require.NoError(t, bus.Close())
+
+	msgs := stream.Messages()
+	require.Len(t, msgs, 1)
+	require.Equal(t, "kctl.events.Deployment.Updated", msgs[0].Subject)
+	require.Contains(t, string(msgs[0].Data), `"name":"web"`)
+	require.Contains(t, bus.Stats(), "nats", "stats and metrics use the --event-sinks name")
+}
+
+// fakeNATS is a tiny stand-in for a NATS server: it answers the handshake and PINGs and
+// records PUB payloads. failAfter, when positive, drops the connection after that many
+// publishes to exercise reconnects.
+func fakeNATS(t *testing.T, failAfter int) (addr string, published <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+	out := make(chan string, 16)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				rd := bufio.NewReader(conn)
+				fmt.Fprint(conn, "INFO {\"server_id\":\"fake\"}\r\n")
+				count := 0
+				for {
+					line, err := rd.ReadString('\n')
+					if err != nil {
+						return
+					}
+					fields := strings.Fields(line)
+					if len(fields) == 0 {
+						continue
+					}
+					switch fields[0] {
+					case "PING":
+						fmt.Fprint(conn, "PONG\r\n")
+					case "PUB":
+						n, _ := strconv.Atoi(fields[len(fields)-1])
+						payload := make([]byte, n+2)
+						if _, err := io.ReadFull(rd, payload); err != nil {
+							return
+						}
+						if failAfter > 0 && count == failAfter {
+							return
+						}
+						count++
+						out <- fields[1] + " " + string(payload[:n])
+					}
+				}
+			}(conn)
+		}
+	}()
+	return ln.Addr().String(), out
+}
+
+func TestNATSClient(t *testing.T) {
+	addr, published := fakeNATS(t, 1)
+	client, err := NewNATSClient("nats://" + addr)
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, client.Publish(ctx, "kctl.events", []byte(`{"n":1}`)))
+	require.Equal(t, `kctl.events {"n":1}`, <-published)
+
+	// The fake server drops the connection on the second publish; the client reports
+	// the failure and reconnects on the next attempt.
+	require.Error(t, client.Publish(ctx, "kctl.events", []byte(`{"n":2}`)))
+	require.NoError(t, client.Publish(ctx, "kctl.events", []byte(`{"n":3}`)))
+	require.Equal(t, `kctl.events {"n":3}`, <-published)
+
+	require.ErrorAs(t, client.Publish(ctx, "bad subject", nil), &permanentError{})
+
+	_, err = NewNATSClient("http://localhost:4222")
+	require.Error(t, err)
+}
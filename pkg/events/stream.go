@@ -0,0 +1,226 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StreamClient publishes raw messages to a subject (NATS) or topic (Kafka). Any broker
+// client can back a StreamSink by implementing it.
+type StreamClient interface {
+	Publish(ctx context.Context, subject string, data []byte) error
+}
+
+// StreamSink publishes every event as JSON to Subject. When PerKind is set the subject
+// is suffixed with ".<Kind>.<Type>", so consumers can subscribe to e.g. "kctl.events.Secret.>".
+type StreamSink struct {
+	Client  StreamClient
+	Subject string
+	PerKind bool
+}
+
+// Name implements Sink. The sink is named after NATS, its only broker so far, which is
+// also how --event-sinks selects it.
+func (s *StreamSink) Name() string { return "nats" }
+
+// Send implements Sink.
+func (s *StreamSink) Send(ctx context.Context, e Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return Permanent(err)
+	}
+	subject := s.Subject
+	if s.PerKind {
+		subject = fmt.Sprintf("%s.%s.%s", subject, e.Kind, e.Type)
+	}
+	return s.Client.Publish(ctx, subject, data)
+}
+
+// Close closes the client when it holds a connection.
+func (s *StreamSink) Close() error {
+	if c, ok := s.Client.(interface{ Close() error }); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// NATSClient is a minimal publisher speaking the NATS client protocol. Every Publish is
+// followed by a PING, so it returns only once the server has processed the message.
+// The connection is re-established lazily after any error.
+type NATSClient struct {
+	// Addr is host:port of the NATS server.
+	Addr string
+	// Name is reported to the server in CONNECT.
+	Name string
+	// DialTimeout bounds connecting; it also bounds a publish without a ctx deadline.
+	DialTimeout time.Duration
+
+	mu   sync.Mutex
+	conn net.Conn
+	rd   *bufio.Reader
+}
+
+// NewNATSClient parses a nats://host:port URL. No connection is made until Publish.
+func NewNATSClient(rawURL string) (*NATSClient, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "nats" || u.Host == "" {
+		return nil, fmt.Errorf("invalid NATS URL %q, want nats://host:port", rawURL)
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "4222")
+	}
+	return &NATSClient{Addr: addr, Name: "kctl", DialTimeout: 5 * time.Second}, nil
+}
+
+// Publish implements StreamClient.
+func (c *NATSClient) Publish(ctx context.Context, subject string, data []byte) error {
+	if subject == "" || strings.ContainsAny(subject, " \t\r\n") {
+		return Permanent(fmt.Errorf("invalid NATS subject %q", subject))
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		if err := c.connect(ctx); err != nil {
+			return err
+		}
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(c.DialTimeout)
+	}
+	_ = c.conn.SetDeadline(deadline)
+	msg := fmt.Sprintf("PUB %s %d\r\n%s\r\nPING\r\n", subject, len(data), data)
+	if _, err := c.conn.Write([]byte(msg)); err != nil {
+		c.reset()
+		return err
+	}
+	if err := c.awaitPong(); err != nil {
+		c.reset()
+		return err
+	}
+	return nil
+}
+
+// Close closes the connection, if any.
+func (c *NATSClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn, c.rd = nil, nil
+	return err
+}
+
+func (c *NATSClient) connect(ctx context.Context) error {
+	d := net.Dialer{Timeout: c.DialTimeout}
+	conn, err := d.DialContext(ctx, "tcp", c.Addr)
+	if err != nil {
+		return err
+	}
+	_ = conn.SetDeadline(time.Now().Add(c.DialTimeout))
+	c.conn, c.rd = conn, bufio.NewReader(conn)
+	line, err := c.readLine()
+	if err != nil || !strings.HasPrefix(line, "INFO ") {
+		c.reset()
+		return fmt.Errorf("NATS handshake with %s failed: %q %v", c.Addr, line, err)
+	}
+	connect, _ := json.Marshal(map[string]any{"verbose": false, "pedantic": false, "name": c.Name, "lang": "go"})
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\nPING\r\n", connect); err != nil {
+		c.reset()
+		return err
+	}
+	if err := c.awaitPong(); err != nil {
+		c.reset()
+		return err
+	}
+	return nil
+}
+
+// awaitPong reads until PONG, answering server PINGs and failing on -ERR.
+func (c *NATSClient) awaitPong() error {
+	for {
+		line, err := c.readLine()
+		if err != nil {
+			return err
+		}
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := c.conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return errors.New("NATS server error: " + strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+}
+
+func (c *NATSClient) readLine() (string, error) {
+	line, err := c.rd.ReadString('\n')
+	return strings.TrimRight(line, "\r\n"), err
+}
+
+func (c *NATSClient) reset() {
+	if c.conn != nil {
+		_ = c.conn.Close()
+	}
+	c.conn, c.rd = nil, nil
+}
+
+// StreamMessage is a message recorded by MemoryStream.
+type StreamMessage struct {
+	Subject string
+	Data    []byte
+}
+
+// MemoryStream is an in-process StreamClient standing in for a broker in tests and
+// local runs.
+type MemoryStream struct {
+	mu       sync.Mutex
+	messages []StreamMessage
+	notify   chan struct{}
+}
+
+// NewMemoryStream returns an empty MemoryStream.
+func NewMemoryStream() *MemoryStream {
+	return &MemoryStream{notify: make(chan struct{}, 1)}
+}
+
+// Publish implements StreamClient.
+func (m *MemoryStream) Publish(_ context.Context, subject string, data []byte) error {
+	m.mu.Lock()
+	m.messages = append(m.messages, StreamMessage{Subject: subject, Data: append([]byte(nil), data...)})
+	m.mu.Unlock()
+	select {
+	case m.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Messages returns a copy of every message published so far.
+func (m *MemoryStream) Messages() []StreamMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]StreamMessage(nil), m.messages...)
+}
+
+// Notify is signalled after each Publish.
+func (m *MemoryStream) Notify() <-chan struct{} {
+	return m.notify
+}
@@ -0,0 +1,158 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/valyala/fasthttp"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+// checkTimeout bounds each individual check so a slow dependency cannot hang a probe.
+const checkTimeout = 2 * time.Second
+
+// Check is a named health check. Fn returns nil when healthy.
+type Check struct {
+	Name string
+	Fn   func(ctx context.Context) error
+}
+
+// Handler serves a probe endpoint in the style of the Kubernetes API server:
+// "ok" when every check passes, per-check [+]/[-] lines on failure or with ?verbose,
+// and ?exclude=<name> to skip a check.
+func Handler(endpoint string, checks ...Check) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		args := ctx.QueryArgs()
+		verbose := args.Has("verbose")
+		excluded := map[string]bool{}
+		for _, name := range args.PeekMulti("exclude") {
+			excluded[string(name)] = true
+		}
+
+		var out strings.Builder
+		failed := false
+		for _, c := range checks {
+			if excluded[c.Name] {
+				fmt.Fprintf(&out, "[+]%s excluded: ok\n", c.Name)
+				continue
+			}
+			checkCtx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+			err := c.Fn(checkCtx)
+			cancel()
+			if err != nil {
+				failed = true
+				log.Debug().Err(err).Msgf("%s check %s failed", endpoint, c.Name)
+				fmt.Fprintf(&out, "[-]%s failed: %v\n", c.Name, err)
+				continue
+			}
+			fmt.Fprintf(&out, "[+]%s ok\n", c.Name)
+		}
+
+		ctx.SetContentType("text/plain; charset=utf-8")
+		ctx.Response.Header.Set("X-Content-Type-Options", "nosniff")
+		if failed {
+			ctx.SetStatusCode(fasthttp.StatusServiceUnavailable)
+			ctx.SetBodyString(out.String() + endpoint + " check failed\n")
+			return
+		}
+		if !verbose {
+			ctx.SetBodyString("ok")
+			return
+		}
+		ctx.SetBodyString(out.String() + endpoint + " check passed\n")
+	}
+}
+
+// CheckResult is one check of a verbose probe response.
+type CheckResult struct {
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+	// Message is the failure reason; "excluded" for a check skipped with ?exclude.
+	Message string `json:"message,omitempty"`
+}
+
+// ParseVerbose reads the [+]/[-] lines of a ?verbose probe response, as served by Handler
+// and by the Kubernetes API server. Other lines are ignored.
+func ParseVerbose(body string) []CheckResult {
+	var results []CheckResult
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) < 4 || line[0] != '[' || line[2] != ']' || line[1] != '+' && line[1] != '-' {
+			continue
+		}
+		name, rest, _ := strings.Cut(line[3:], " ")
+		result := CheckResult{Name: name, Healthy: line[1] == '+'}
+		switch {
+		case strings.HasPrefix(rest, "excluded"):
+			result.Message = "excluded"
+		case !result.Healthy:
+			result.Message = strings.TrimPrefix(strings.TrimPrefix(rest, "failed"), ": ")
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// Ping always succeeds; it shows the process is serving requests.
+func Ping() Check {
+	return Check{Name: "ping", Fn: func(context.Context) error { return nil }}
+}
+
+// InformerSynced passes once the shared informer caches have synced.
+func InformerSynced(hasSynced func() bool) Check {
+	return Check{Name: "informer-sync", Fn: func(context.Context) error {
+		if !hasSynced() {
+			return errors.New("informer caches not synced")
+		}
+		return nil
+	}}
+}
+
+// ManagerCacheSynced passes once the controller-runtime cache has synced.
+func ManagerCacheSynced(c cache.Cache) Check {
+	return Check{Name: "manager-cache-sync", Fn: func(ctx context.Context) error {
+		if !c.WaitForCacheSync(ctx) {
+			return errors.New("manager cache not synced")
+		}
+		return nil
+	}}
+}
+
+// LeaderElected passes when leader election is disabled or this replica is the leader.
+func LeaderElected(enabled bool, elected <-chan struct{}) Check {
+	return Check{Name: "leader-election", Fn: func(context.Context) error {
+		if !enabled {
+			return nil
+		}
+		select {
+		case <-elected:
+			return nil
+		default:
+			return errors.New("not the leader")
+		}
+	}}
+}
+
+// APIServerReachable passes when the API server answers its own /readyz.
+func APIServerReachable(client rest.Interface) Check {
+	return Check{Name: "apiserver", Fn: func(ctx context.Context) error {
+		_, err := client.Get().AbsPath("/readyz").DoRaw(ctx)
+		return err
+	}}
+}
+
+// Checker adapts a Check for the controller-runtime manager probe server.
+func Checker(c Check) healthz.Checker {
+	return func(req *http.Request) error {
+		ctx, cancel := context.WithTimeout(req.Context(), checkTimeout)
+		defer cancel()
+		return c.Fn(ctx)
+	}
+}
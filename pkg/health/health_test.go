@@ -0,0 +1,77 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func probe(h fasthttp.RequestHandler, uri string) *fasthttp.RequestCtx {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI(uri)
+	h(ctx)
+	return ctx
+}
+
+func TestHandler(t *testing.T) {
+	synced := false
+	h := Handler("readyz", Ping(), InformerSynced(func() bool { return synced }))
+
+	ctx := probe(h, "/readyz")
+	require.Equal(t, fasthttp.StatusServiceUnavailable, ctx.Response.StatusCode())
+	require.Contains(t, string(ctx.Response.Body()), "[+]ping ok")
+	require.Contains(t, string(ctx.Response.Body()), "[-]informer-sync failed: informer caches not synced")
+
+	ctx = probe(h, "/readyz?exclude=informer-sync")
+	require.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+	require.Equal(t, "ok", string(ctx.Response.Body()))
+
+	synced = true
+	ctx = probe(h, "/readyz")
+	require.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+	require.Equal(t, "ok", string(ctx.Response.Body()))
+
+	ctx = probe(h, "/readyz?verbose")
+	require.Equal(t, "[+]ping ok\n[+]informer-sync ok\nreadyz check passed\n", string(ctx.Response.Body()))
+}
+
+func TestLeaderElected(t *testing.T) {
+	elected := make(chan struct{})
+	require.NoError(t, LeaderElected(false, elected).Fn(context.Background()))
+	require.Error(t, LeaderElected(true, elected).Fn(context.Background()))
+	close(elected)
+	require.NoError(t, LeaderElected(true, elected).Fn(context.Background()))
+}
+
+func TestChecker(t *testing.T) {
+	check := Check{Name: "broken", Fn: func(context.Context) error { return errors.New("boom") }}
+	req, err := http.NewRequest(http.MethodGet, "/readyz", nil)
+	require.NoError(t, err)
+	require.EqualError(t, Checker(check)(req), "boom")
+}
+
+func TestParseVerbose(t *testing.T) {
+	body := `[+]ping ok
+[+]etcd excluded: ok
+[-]poststarthook/rbac/bootstrap-roles failed: not finished
+[-]informer-sync failed
+readyz check failed
+`
+	require.Equal(t, []CheckResult{
+		{Name: "ping", Healthy: true},
+		{Name: "etcd", Healthy: true, Message: "excluded"},
+		{Name: "poststarthook/rbac/bootstrap-roles", Message: "not finished"},
+		{Name: "informer-sync"},
+	}, ParseVerbose(body))
+	require.Empty(t, ParseVerbose("ok"))
+
+	h := Handler("livez", Ping(), InformerSynced(func() bool { return false }))
+	require.Equal(t, []CheckResult{
+		{Name: "ping", Healthy: true},
+		{Name: "informer-sync", Message: "informer caches not synced"},
+	}, ParseVerbose(string(probe(h, "/livez?verbose").Response.Body())))
+}
@@ -0,0 +1,235 @@
+package informer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync/atomic"
+
+	"github.com/rs/zerolog/log"
+	"github.com/silhouetteUA/k8s-controller/pkg/events"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/yaml"
+)
+
+// ErrNotWatched is returned for resources that are not in the dynamic configuration.
+var ErrNotWatched = errors.New("resource is not watched")
+
+// ResourceConfig selects one resource to watch through the dynamic informers.
+type ResourceConfig struct {
+	Group    string `json:"group,omitempty"`
+	Version  string `json:"version"`
+	Resource string `json:"resource"`
+	// Namespaces to watch; empty watches all namespaces.
+	Namespaces    []string        `json:"namespaces,omitempty"`
+	ResyncPeriod  metav1.Duration `json:"resyncPeriod,omitempty"`
+	LabelSelector string          `json:"labelSelector,omitempty"`
+	FieldSelector string          `json:"fieldSelector,omitempty"`
+}
+
+// GroupVersionResource returns the GVR the config refers to.
+func (c ResourceConfig) GroupVersionResource() schema.GroupVersionResource {
+	return schema.GroupVersionResource{Group: c.Group, Version: c.Version, Resource: c.Resource}
+}
+
+// DynamicConfig is the file format of --resources-config.
+type DynamicConfig struct {
+	Resources []ResourceConfig `json:"resources"`
+	// Events receives a structured event for every change. It is set by the caller,
+	// not read from the file.
+	Events events.Publisher `json:"-"`
+}
+
+// LoadDynamicConfig reads a YAML or JSON DynamicConfig from path.
+func LoadDynamicConfig(path string) (*DynamicConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &DynamicConfig{}
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// dynamicWatch is the set of informers, one per namespace, backing a single resource.
+type dynamicWatch struct {
+	namespaced map[string]cache.SharedIndexInformer
+	factories  []dynamicinformer.DynamicSharedInformerFactory
+}
+
+// DynamicInformerManager watches arbitrary resources, including CRDs, configured at runtime.
+type DynamicInformerManager struct {
+	watches map[schema.GroupVersionResource]*dynamicWatch
+
+	started atomic.Bool
+	synced  atomic.Bool
+}
+
+// NewDynamicInformerManager validates cfg and creates an informer per resource and namespace.
+func NewDynamicInformerManager(client dynamic.Interface, cfg DynamicConfig) (*DynamicInformerManager, error) {
+	m := &DynamicInformerManager{watches: map[schema.GroupVersionResource]*dynamicWatch{}}
+	for _, rc := range cfg.Resources {
+		if rc.Version == "" || rc.Resource == "" {
+			return nil, fmt.Errorf("resource %q: version and resource are required", rc.GroupVersionResource().String())
+		}
+		gvr := rc.GroupVersionResource()
+		if _, dup := m.watches[gvr]; dup {
+			return nil, fmt.Errorf("resource %s is configured more than once", gvr.String())
+		}
+		resync := rc.ResyncPeriod.Duration
+		if resync == 0 {
+			resync = DefaultResyncPeriod
+		}
+		namespaces := rc.Namespaces
+		if len(namespaces) == 0 {
+			namespaces = []string{metav1.NamespaceAll}
+		}
+		w := &dynamicWatch{namespaced: map[string]cache.SharedIndexInformer{}}
+		for _, ns := range namespaces {
+			labelSelector, fieldSelector := rc.LabelSelector, rc.FieldSelector
+			factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(client, resync, ns, func(options *metav1.ListOptions) {
+				options.LabelSelector = labelSelector
+				options.FieldSelector = fieldSelector
+			})
+			informer := factory.ForResource(gvr).Informer()
+			addResourceHandlers(informer, gvr.Resource, cfg.Events)
+			w.namespaced[ns] = informer
+			w.factories = append(w.factories, factory)
+		}
+		m.watches[gvr] = w
+	}
+	return m, nil
+}
+
+// Resources returns the watched resources, sorted.
+func (m *DynamicInformerManager) Resources() []schema.GroupVersionResource {
+	out := make([]schema.GroupVersionResource, 0, len(m.watches))
+	for gvr := range m.watches {
+		out = append(out, gvr)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].String() < out[j].String() })
+	return out
+}
+
+// Start begins watching until ctx is cancelled. It does not wait for the caches to sync.
+func (m *DynamicInformerManager) Start(ctx context.Context) error {
+	if !m.started.CompareAndSwap(false, true) {
+		return errors.New("dynamic informer manager already started")
+	}
+	log.Info().Msgf("Starting dynamic informers for %d resources...", len(m.watches))
+	for _, w := range m.watches {
+		for _, f := range w.factories {
+			f.Start(ctx.Done())
+		}
+	}
+	return nil
+}
+
+// WaitForSync blocks until every informer cache has synced or ctx is done.
+func (m *DynamicInformerManager) WaitForSync(ctx context.Context) error {
+	if !m.started.Load() {
+		return errors.New("dynamic informer manager not started")
+	}
+	var unsynced []string
+	for _, w := range m.watches {
+		for _, f := range w.factories {
+			for gvr, synced := range f.WaitForCacheSync(ctx.Done()) {
+				if !synced {
+					unsynced = append(unsynced, gvr.String())
+				}
+			}
+		}
+	}
+	if len(unsynced) > 0 {
+		return fmt.Errorf("failed to sync dynamic informers for %v", unsynced)
+	}
+	m.synced.Store(true)
+	log.Info().Msg("Dynamic informers cache synced")
+	return nil
+}
+
+// HasSynced reports whether every dynamic informer cache has completed its initial sync.
+func (m *DynamicInformerManager) HasSynced() bool {
+	return len(m.watches) == 0 || m.synced.Load()
+}
+
+// Shutdown waits for the informer goroutines to exit once the Start context is cancelled.
+func (m *DynamicInformerManager) Shutdown() {
+	for _, w := range m.watches {
+		for _, f := range w.factories {
+			f.Shutdown()
+		}
+	}
+	m.synced.Store(false)
+}
+
+// List returns cached objects of gvr in namespace (all watched namespaces when empty)
+// matching selector, sorted by namespace and name.
+func (m *DynamicInformerManager) List(gvr schema.GroupVersionResource, namespace string, selector labels.Selector) ([]*unstructured.Unstructured, error) {
+	w, ok := m.watches[gvr]
+	if !ok {
+		return nil, ErrNotWatched
+	}
+	var out []*unstructured.Unstructured
+	for _, informer := range w.namespaced {
+		lister := cache.NewGenericLister(informer.GetIndexer(), gvr.GroupResource())
+		var objs []runtime.Object
+		var err error
+		if namespace == "" {
+			objs, err = lister.List(selector)
+		} else {
+			objs, err = lister.ByNamespace(namespace).List(selector)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objs {
+			if u, ok := obj.(*unstructured.Unstructured); ok {
+				out = append(out, u)
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].GetNamespace() != out[j].GetNamespace() {
+			return out[i].GetNamespace() < out[j].GetNamespace()
+		}
+		return out[i].GetName() < out[j].GetName()
+	})
+	return out, nil
+}
+
+// Get returns one cached object of gvr. Cluster-scoped objects use an empty namespace.
+func (m *DynamicInformerManager) Get(gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, error) {
+	w, ok := m.watches[gvr]
+	if !ok {
+		return nil, ErrNotWatched
+	}
+	key := name
+	if namespace != "" {
+		key = namespace + "/" + name
+	}
+	for _, informer := range w.namespaced {
+		obj, exists, err := informer.GetIndexer().GetByKey(key)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			if u, ok := obj.(*unstructured.Unstructured); ok {
+				return u, nil
+			}
+		}
+	}
+	return nil, apierrors.NewNotFound(gvr.GroupResource(), name)
+}
@@ -0,0 +1,109 @@
+package informer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+var frontendPagesGVR = schema.GroupVersionResource{Group: "frontendpage.silhouetteua.io", Version: "v1alpha1", Resource: "frontendpages"}
+
+func frontendPage(namespace, name string, lbls map[string]string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("frontendpage.silhouetteua.io/v1alpha1")
+	u.SetKind("FrontendPage")
+	u.SetNamespace(namespace)
+	u.SetName(name)
+	u.SetLabels(lbls)
+	return u
+}
+
+func TestLoadDynamicConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "resources.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+resources:
+  - group: frontendpage.silhouetteua.io
+    version: v1alpha1
+    resource: frontendpages
+    namespaces: [default]
+    resyncPeriod: 1m
+`), 0o600))
+	cfg, err := LoadDynamicConfig(path)
+	require.NoError(t, err)
+	require.Len(t, cfg.Resources, 1)
+	require.Equal(t, frontendPagesGVR, cfg.Resources[0].GroupVersionResource())
+	require.Equal(t, "1m0s", cfg.Resources[0].ResyncPeriod.Duration.String())
+
+	require.NoError(t, os.WriteFile(path, []byte("resources:\n  - resource: x\n    bogus: true\n"), 0o600))
+	_, err = LoadDynamicConfig(path)
+	require.Error(t, err, "unknown fields must be rejected")
+}
+
+func TestNewDynamicInformerManager_Validation(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+	_, err := NewDynamicInformerManager(client, DynamicConfig{Resources: []ResourceConfig{{Resource: "frontendpages"}}})
+	require.Error(t, err)
+
+	rc := ResourceConfig{Group: frontendPagesGVR.Group, Version: "v1alpha1", Resource: "frontendpages"}
+	_, err = NewDynamicInformerManager(client, DynamicConfig{Resources: []ResourceConfig{rc, rc}})
+	require.Error(t, err)
+}
+
+func TestDynamicInformerManager(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{frontendPagesGVR: "FrontendPageList"},
+		frontendPage("default", "home", map[string]string{"team": "web"}),
+		frontendPage("default", "about", nil),
+		frontendPage("team-a", "landing", map[string]string{"team": "web"}),
+		frontendPage("ignored", "other", nil),
+	)
+	m, err := NewDynamicInformerManager(client, DynamicConfig{Resources: []ResourceConfig{{
+		Group: frontendPagesGVR.Group, Version: "v1alpha1", Resource: "frontendpages",
+		Namespaces: []string{"default", "team-a"},
+	}}})
+	require.NoError(t, err)
+	require.Equal(t, []schema.GroupVersionResource{frontendPagesGVR}, m.Resources())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, m.Start(ctx))
+	require.NoError(t, m.WaitForSync(ctx))
+	require.True(t, m.HasSynced())
+
+	all, err := m.List(frontendPagesGVR, "", labels.Everything())
+	require.NoError(t, err)
+	var names []string
+	for _, o := range all {
+		names = append(names, o.GetNamespace()+"/"+o.GetName())
+	}
+	require.Equal(t, []string{"default/about", "default/home", "team-a/landing"}, names)
+
+	selector, err := labels.Parse("team=web")
+	require.NoError(t, err)
+	web, err := m.List(frontendPagesGVR, "default", selector)
+	require.NoError(t, err)
+	require.Len(t, web, 1)
+	require.Equal(t, "home", web[0].GetName())
+
+	got, err := m.Get(frontendPagesGVR, "team-a", "landing")
+	require.NoError(t, err)
+	require.Equal(t, "landing", got.GetName())
+
+	_, err = m.Get(frontendPagesGVR, "ignored", "other")
+	require.True(t, apierrors.IsNotFound(err))
+
+	_, err = m.List(schema.GroupVersionResource{Version: "v1", Resource: "pods"}, "", labels.Everything())
+	require.ErrorIs(t, err, ErrNotWatched)
+
+	cancel()
+	m.Shutdown()
+}
@@ -0,0 +1,229 @@
+package informer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Names of the indexers registered on the shared informers.
+const (
+	// IndexByLabel maps every label to "key" and "key=value".
+	IndexByLabel = "label"
+	// IndexByOwner maps every owner reference to "Kind/name".
+	IndexByOwner = "owner"
+	// IndexByImage maps Deployments to the images of their containers, with and without tag.
+	IndexByImage = "image"
+	// IndexBySecretType maps Secrets to their type.
+	IndexBySecretType = "type"
+)
+
+// DeploymentIndexers are the indexers registered on the Deployment informer.
+func DeploymentIndexers() cache.Indexers {
+	return cache.Indexers{
+		cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+		IndexByLabel:         labelIndexFunc,
+		IndexByOwner:         ownerIndexFunc,
+		IndexByImage:         imageIndexFunc,
+	}
+}
+
+// SecretIndexers are the indexers registered on the Secret informer.
+func SecretIndexers() cache.Indexers {
+	return cache.Indexers{
+		cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+		IndexByLabel:         labelIndexFunc,
+		IndexByOwner:         ownerIndexFunc,
+		IndexBySecretType:    secretTypeIndexFunc,
+	}
+}
+
+func labelIndexFunc(obj interface{}) ([]string, error) {
+	m, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, 2*len(m.GetLabels()))
+	for k, v := range m.GetLabels() {
+		keys = append(keys, k, k+"="+v)
+	}
+	return keys, nil
+}
+
+func ownerIndexFunc(obj interface{}) ([]string, error) {
+	m, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(m.GetOwnerReferences()))
+	for _, ref := range m.GetOwnerReferences() {
+		keys = append(keys, ref.Kind+"/"+ref.Name)
+	}
+	return keys, nil
+}
+
+func imageIndexFunc(obj interface{}) ([]string, error) {
+	d, ok := obj.(*appsv1.Deployment)
+	if !ok {
+		return nil, fmt.Errorf("expected *appsv1.Deployment, got %T", obj)
+	}
+	keys := sets.New[string]()
+	spec := d.Spec.Template.Spec
+	for _, containers := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+		for _, c := range containers {
+			keys.Insert(ImageKeys(c.Image)...)
+		}
+	}
+	return sets.List(keys), nil
+}
+
+func secretTypeIndexFunc(obj interface{}) ([]string, error) {
+	s, ok := obj.(*corev1.Secret)
+	if !ok {
+		return nil, fmt.Errorf("expected *corev1.Secret, got %T", obj)
+	}
+	return []string{string(s.Type)}, nil
+}
+
+// ImageKeys returns the image index values for image: the reference as written, the
+// repository without tag or digest, and both again without an implicit Docker Hub prefix.
+// So "docker.io/library/nginx:1.27" is found by "nginx", "nginx:1.27" and the full reference.
+func ImageKeys(image string) []string {
+	keys := sets.New(image)
+	repo := image
+	if i := strings.Index(repo, "@"); i >= 0 {
+		repo = repo[:i]
+	}
+	if i := strings.LastIndex(repo, ":"); i > strings.LastIndex(repo, "/") {
+		repo = repo[:i]
+	}
+	keys.Insert(repo)
+	for _, prefix := range []string{"docker.io/library/", "docker.io/"} {
+		if strings.HasPrefix(image, prefix) {
+			keys.Insert(strings.TrimPrefix(image, prefix), strings.TrimPrefix(repo, prefix))
+			break
+		}
+	}
+	return sets.List(keys)
+}
+
+// Query selects objects through the indexers. Empty fields don't filter; set fields are
+// ANDed. Fields that don't apply to a resource (Image for Secrets, Type for Deployments)
+// must be left empty.
+type Query struct {
+	Namespace string
+	// Label is a label key, or key=value.
+	Label string
+	// Owner is an owner reference as Kind/name, e.g. FrontendPage/home.
+	Owner string
+	Image string
+	Type  string
+}
+
+func (q Query) terms() map[string]string {
+	terms := map[string]string{}
+	for index, value := range map[string]string{IndexByLabel: q.Label, IndexByOwner: q.Owner, IndexByImage: q.Image, IndexBySecretType: q.Type} {
+		if value != "" {
+			terms[index] = value
+		}
+	}
+	return terms
+}
+
+// QueryDeployments returns the Deployments in indexer matching q, sorted by namespace and name.
+func QueryDeployments(indexer cache.Indexer, q Query) ([]*appsv1.Deployment, error) {
+	if q.Type != "" {
+		return nil, fmt.Errorf("deployments cannot be queried by type")
+	}
+	objs, err := queryIndexer(indexer, q)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*appsv1.Deployment, 0, len(objs))
+	for _, obj := range objs {
+		if d, ok := obj.(*appsv1.Deployment); ok {
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
+
+// QuerySecrets returns the Secrets in indexer matching q, sorted by namespace and name.
+func QuerySecrets(indexer cache.Indexer, q Query) ([]*corev1.Secret, error) {
+	if q.Image != "" {
+		return nil, fmt.Errorf("secrets cannot be queried by image")
+	}
+	objs, err := queryIndexer(indexer, q)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*corev1.Secret, 0, len(objs))
+	for _, obj := range objs {
+		if s, ok := obj.(*corev1.Secret); ok {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+// queryIndexer intersects the store keys of every index term, so no object is scanned
+// that doesn't match them all. A nil indexer (an unwatched namespace) matches nothing.
+func queryIndexer(indexer cache.Indexer, q Query) ([]interface{}, error) {
+	if indexer == nil {
+		return nil, nil
+	}
+	terms := q.terms()
+	if q.Namespace != "" {
+		terms[cache.NamespaceIndex] = q.Namespace
+	}
+	var keys sets.Set[string]
+	if len(terms) == 0 {
+		keys = sets.New(indexer.ListKeys()...)
+	}
+	for index, value := range terms {
+		matched, err := indexer.IndexKeys(index, value)
+		if err != nil {
+			return nil, err
+		}
+		if keys == nil {
+			keys = sets.New(matched...)
+		} else {
+			keys = keys.Intersection(sets.New(matched...))
+		}
+		if keys.Len() == 0 {
+			break
+		}
+	}
+	out := make([]interface{}, 0, keys.Len())
+	for _, key := range sets.List(keys) {
+		obj, exists, err := indexer.GetByKey(key)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			out = append(out, obj)
+		}
+	}
+	return out, nil
+}
+
+// addIndexers registers indexers on informer, skipping names it already has (the
+// factory always adds the namespace index).
+func addIndexers(informer cache.SharedIndexInformer, indexers cache.Indexers) {
+	existing := informer.GetIndexer().GetIndexers()
+	missing := cache.Indexers{}
+	for name, fn := range indexers {
+		if _, ok := existing[name]; !ok {
+			missing[name] = fn
+		}
+	}
+	if err := informer.AddIndexers(missing); err != nil {
+		log.Error().Err(err).Msg("Failed to add informer indexers")
+	}
+}
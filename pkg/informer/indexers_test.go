@@ -0,0 +1,71 @@
+package informer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestImageKeys(t *testing.T) {
+	require.Equal(t, []string{"nginx", "nginx:1.27"}, ImageKeys("nginx:1.27"))
+	require.Equal(t, []string{"docker.io/library/nginx", "docker.io/library/nginx:1.27", "nginx", "nginx:1.27"}, ImageKeys("docker.io/library/nginx:1.27"))
+	require.Equal(t, []string{"localhost:5000/app", "localhost:5000/app@sha256:abc"}, ImageKeys("localhost:5000/app@sha256:abc"))
+	require.Equal(t, []string{"localhost:5000/app"}, ImageKeys("localhost:5000/app"))
+}
+
+func TestQueryDeployments(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, DeploymentIndexers())
+	deploy := func(ns, name, image string, owner string, lbls map[string]string) *appsv1.Deployment {
+		d := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name, Labels: lbls}}
+		d.Spec.Template.Spec.Containers = []corev1.Container{{Name: "app", Image: image}}
+		if owner != "" {
+			d.OwnerReferences = []metav1.OwnerReference{{Kind: "FrontendPage", Name: owner}}
+		}
+		return d
+	}
+	for _, d := range []*appsv1.Deployment{
+		deploy("default", "home", "nginx:1.25", "home", map[string]string{"team": "web"}),
+		deploy("default", "api", "ghcr.io/acme/api:2", "", map[string]string{"team": "backend"}),
+		deploy("team-a", "landing", "nginx:1.27", "landing", map[string]string{"team": "web"}),
+	} {
+		require.NoError(t, indexer.Add(d))
+	}
+	names := func(q Query) []string {
+		ds, err := QueryDeployments(indexer, q)
+		require.NoError(t, err)
+		out := []string{}
+		for _, d := range ds {
+			out = append(out, d.Namespace+"/"+d.Name)
+		}
+		return out
+	}
+
+	require.Equal(t, []string{"default/api", "default/home", "team-a/landing"}, names(Query{}))
+	require.Equal(t, []string{"default/home", "team-a/landing"}, names(Query{Image: "nginx"}))
+	require.Equal(t, []string{"default/home"}, names(Query{Image: "nginx", Namespace: "default"}))
+	require.Equal(t, []string{"team-a/landing"}, names(Query{Owner: "FrontendPage/landing"}))
+	require.Equal(t, []string{"default/api"}, names(Query{Label: "team=backend"}))
+	require.Equal(t, []string{"default/home", "team-a/landing"}, names(Query{Label: "team", Image: "nginx"}))
+	require.Empty(t, names(Query{Image: "redis"}))
+
+	_, err := QueryDeployments(indexer, Query{Type: "Opaque"})
+	require.Error(t, err)
+}
+
+func TestQuerySecrets(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, SecretIndexers())
+	require.NoError(t, indexer.Add(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "tls"}, Type: corev1.SecretTypeTLS}))
+	require.NoError(t, indexer.Add(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "db"}, Type: corev1.SecretTypeOpaque}))
+
+	secrets, err := QuerySecrets(indexer, Query{Type: string(corev1.SecretTypeTLS)})
+	require.NoError(t, err)
+	require.Len(t, secrets, 1)
+	require.Equal(t, "tls", secrets[0].Name)
+
+	_, err = QuerySecrets(indexer, Query{Image: "nginx"})
+	require.Error(t, err)
+}
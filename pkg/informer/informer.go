@@ -2,9 +2,10 @@ package informer
 
 import (
 	"context"
-	appsv1 "k8s.io/api/apps/v1"
-	v1 "k8s.io/api/core/v1"
-	"os"
+	"errors"
+	"fmt"
+	"path"
+	"sync/atomic"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -13,51 +14,207 @@ import (
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
+
+	"github.com/silhouetteUA/k8s-controller/pkg/events"
+	"github.com/silhouetteUA/k8s-controller/pkg/scope"
 )
 
-var deploymentsInformer cache.SharedIndexInformer
-var secretsInformer cache.SharedIndexInformer
+// DefaultResyncPeriod is used when Options.ResyncPeriod is zero.
+const DefaultResyncPeriod = 30 * time.Second
 
-func StartInformerFactory(ctx context.Context, clientset *kubernetes.Clientset, namespace string) {
-	factory := informers.NewSharedInformerFactoryWithOptions(
-		clientset,
-		30*time.Second,
-		informers.WithNamespace(namespace),
-		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
-			options.FieldSelector = fields.Everything().String()
-		}),
-	)
+// Options configures an InformerManager.
+type Options struct {
+	// Scope selects the watched namespaces. Listed namespaces get informers of their own,
+	// so the manager only needs namespaced RBAC; nil and cluster-wide scopes use one
+	// informer per resource across all namespaces.
+	Scope *scope.Scope
+	// ResyncPeriod is how often the informers replay their caches to handlers.
+	ResyncPeriod time.Duration
+	// LabelSelector and FieldSelector filter the watched objects.
+	LabelSelector string
+	FieldSelector string
+	// Events receives a structured event for every change; when nil changes are only logged.
+	Events events.Publisher
+}
 
-	deploymentsInformer = factory.Apps().V1().Deployments().Informer()
-	secretsInformer = factory.Core().V1().Secrets().Informer()
+// namespaceInformers are the informers of one namespace, or of all of them ("").
+type namespaceInformers struct {
+	factory     informers.SharedInformerFactory
+	deployments cache.SharedIndexInformer
+	secrets     cache.SharedIndexInformer
+}
 
-	addResourceHandlers(deploymentsInformer, "Deployment")
-	addResourceHandlers(secretsInformer, "Secret")
+// InformerManager owns the shared informers for Deployments and Secrets and
+// exposes their caches as indexers.
+type InformerManager struct {
+	scope       *scope.Scope
+	byNamespace map[string]*namespaceInformers
 
-	log.Info().Msg("Starting informers...")
-	factory.Start(ctx.Done())
+	started atomic.Bool
+	synced  atomic.Bool
+}
 
-	for resource, synced := range factory.WaitForCacheSync(ctx.Done()) {
-		if !synced {
-			log.Error().Msgf("Failed to sync informer for %v", resource)
-			os.Exit(1)
+// NewInformerManager creates the informers and registers their event handlers.
+// Nothing talks to the API server until Start is called.
+func NewInformerManager(clientset kubernetes.Interface, opts Options) *InformerManager {
+	resync := opts.ResyncPeriod
+	if resync == 0 {
+		resync = DefaultResyncPeriod
+	}
+	fieldSelector := opts.FieldSelector
+	if fieldSelector == "" {
+		fieldSelector = fields.Everything().String()
+	}
+	namespaces := opts.Scope.Namespaces()
+	if namespaces == nil {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+	// Cluster-wide informers also see namespaces a selector excludes; drop their events.
+	publisher := opts.Events
+	if opts.Scope.Selector() != nil {
+		publisher = scopedPublisher{scope: opts.Scope, next: publisher}
+	}
+
+	m := &InformerManager{scope: opts.Scope, byNamespace: map[string]*namespaceInformers{}}
+	for _, namespace := range namespaces {
+		factory := informers.NewSharedInformerFactoryWithOptions(
+			clientset,
+			resync,
+			informers.WithNamespace(namespace),
+			informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+				options.FieldSelector = fieldSelector
+				options.LabelSelector = opts.LabelSelector
+			}),
+		)
+		ni := &namespaceInformers{
+			factory:     factory,
+			deployments: factory.Apps().V1().Deployments().Informer(),
+			secrets:     factory.Core().V1().Secrets().Informer(),
 		}
+		addIndexers(ni.deployments, DeploymentIndexers())
+		addIndexers(ni.secrets, SecretIndexers())
+		addResourceHandlers(ni.deployments, "Deployment", publisher)
+		addResourceHandlers(ni.secrets, "Secret", publisher)
+		m.byNamespace[namespace] = ni
+	}
+	return m
+}
+
+// Start begins watching until ctx is cancelled. It does not wait for the caches to sync.
+func (m *InformerManager) Start(ctx context.Context) error {
+	if !m.started.CompareAndSwap(false, true) {
+		return errors.New("informer manager already started")
+	}
+	log.Info().Msgf("Starting informers for namespaces %s...", m.scope)
+	for _, ni := range m.byNamespace {
+		ni.factory.Start(ctx.Done())
 	}
+	return nil
+}
 
+// WaitForSync blocks until every informer cache has synced or ctx is done.
+func (m *InformerManager) WaitForSync(ctx context.Context) error {
+	if !m.started.Load() {
+		return errors.New("informer manager not started")
+	}
+	var unsynced []string
+	for namespace, ni := range m.byNamespace {
+		for resource, synced := range ni.factory.WaitForCacheSync(ctx.Done()) {
+			if !synced {
+				unsynced = append(unsynced, path.Join(namespace, resource.String()))
+			}
+		}
+	}
+	if len(unsynced) > 0 {
+		return fmt.Errorf("failed to sync informers for %v", unsynced)
+	}
+	m.synced.Store(true)
 	log.Info().Msg("Informers cache synced. Watching for events...")
-	<-ctx.Done()
+	return nil
+}
+
+// HasSynced reports whether the informer caches have completed their initial sync.
+func (m *InformerManager) HasSynced() bool {
+	return m.synced.Load()
 }
 
-func addResourceHandlers(informer cache.SharedIndexInformer, resourceType string) {
+// Shutdown waits for the informer goroutines to exit once the Start context is cancelled.
+func (m *InformerManager) Shutdown() {
+	for _, ni := range m.byNamespace {
+		ni.factory.Shutdown()
+	}
+	m.synced.Store(false)
+}
+
+// informersFor returns the informers holding namespace's objects, or nil when it isn't watched.
+func (m *InformerManager) informersFor(namespace string) *namespaceInformers {
+	if !m.scope.Contains(namespace) {
+		return nil
+	}
+	if ni, ok := m.byNamespace[metav1.NamespaceAll]; ok {
+		return ni
+	}
+	return m.byNamespace[namespace]
+}
+
+// DeploymentIndexer is the Deployment cache with DeploymentIndexers holding namespace,
+// for QueryDeployments. Cluster-wide caches hold other namespaces too, so always query
+// with Query.Namespace set. It returns nil when namespace isn't watched.
+func (m *InformerManager) DeploymentIndexer(namespace string) cache.Indexer {
+	if ni := m.informersFor(namespace); ni != nil {
+		return ni.deployments.GetIndexer()
+	}
+	return nil
+}
+
+// SecretIndexer is the Secret cache with SecretIndexers holding namespace, for
+// QuerySecrets. It returns nil when namespace isn't watched.
+func (m *InformerManager) SecretIndexer(namespace string) cache.Indexer {
+	if ni := m.informersFor(namespace); ni != nil {
+		return ni.secrets.GetIndexer()
+	}
+	return nil
+}
+
+// scopedPublisher forwards only the events of namespaces in scope.
+type scopedPublisher struct {
+	scope *scope.Scope
+	next  events.Publisher
+}
+
+func (p scopedPublisher) Publish(e events.Event) {
+	if !p.scope.Contains(e.Namespace) {
+		return
+	}
+	if p.next == nil {
+		log.Info().Msg(e.String())
+		return
+	}
+	p.next.Publish(e)
+}
+
+// addResourceHandlers publishes Add/Update/Delete callbacks of informer as events of
+// kind. Resyncs, which replay unchanged objects, are skipped.
+func addResourceHandlers(informer cache.SharedIndexInformer, kind string, publisher events.Publisher) {
+	publish := func(e events.Event) {
+		if publisher == nil {
+			log.Info().Msg(e.String())
+			return
+		}
+		publisher.Publish(e)
+	}
 	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
-			log.Info().Msgf("%s added: %s", resourceType, getObjectName(obj))
+			publish(events.New(events.Added, kind, obj))
 		},
 		UpdateFunc: func(oldObj, newObj interface{}) {
-			log.Info().Msgf("%s updated: %s", resourceType, getObjectName(newObj))
+			if getResourceVersion(oldObj) == getResourceVersion(newObj) {
+				return
+			}
+			publish(events.NewUpdate(kind, oldObj, newObj))
 		},
 		DeleteFunc: func(obj interface{}) {
-			log.Info().Msgf("%s deleted: %s", resourceType, getObjectName(obj))
+			publish(events.New(events.Deleted, kind, obj))
 		},
 	})
 	if err != nil {
@@ -72,28 +229,9 @@ func getObjectName(obj interface{}) string {
 	return "unknown"
 }
 
-func GetDeploymentNames() []string {
-	var names []string
-	if deploymentsInformer == nil {
-		return names
-	}
-	for _, obj := range deploymentsInformer.GetStore().List() {
-		if d, ok := obj.(*appsv1.Deployment); ok {
-			names = append(names, d.Name)
-		}
-	}
-	return names
-}
-
-func GetSecretNames() []string {
-	var names []string
-	if secretsInformer == nil {
-		return names
-	}
-	for _, obj := range secretsInformer.GetStore().List() {
-		if s, ok := obj.(*v1.Secret); ok {
-			names = append(names, s.Name)
-		}
+func getResourceVersion(obj interface{}) string {
+	if o, ok := obj.(metav1.Object); ok {
+		return o.GetResourceVersion()
 	}
-	return names
+	return ""
 }
@@ -7,67 +7,183 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/informers"
-	"k8s.io/client-go/tools/cache"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes/fake"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
 
+	"github.com/silhouetteUA/k8s-controller/pkg/events"
+	"github.com/silhouetteUA/k8s-controller/pkg/scope"
 	testutil "github.com/silhouetteUA/k8s-controller/pkg/testutil"
 )
 
-func TestStartDeploymentInformer(t *testing.T) {
+func defaultScope(t *testing.T) *scope.Scope {
+	s, err := scope.Parse("default")
+	require.NoError(t, err)
+	return s
+}
+
+func TestInformerManager_EnvTest(t *testing.T) {
 	_, clientset, cleanup := testutil.SetupEnv(t)
 	defer cleanup()
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	var wg sync.WaitGroup
-	wg.Add(1)
+	m := NewInformerManager(clientset, Options{Scope: defaultScope(t)})
+	require.NoError(t, m.Start(ctx))
+
+	syncCtx, syncCancel := context.WithTimeout(ctx, 10*time.Second)
+	defer syncCancel()
+	require.NoError(t, m.WaitForSync(syncCtx))
+	require.True(t, m.HasSynced())
 
-	// Patch log to write to a buffer or just rely on test output
-	added := make(chan string, 2)
+	lister := appslisters.NewDeploymentLister(m.DeploymentIndexer("default")).Deployments("default")
+	_, err := lister.Get("sample-deployment-1")
+	require.NoError(t, err)
+	_, err = lister.Get("sample-deployment-2")
+	require.NoError(t, err)
+
+	cancel()
+	m.Shutdown()
+	require.False(t, m.HasSynced())
+}
 
-	// Patch event handler for test
-	factory := informers.NewSharedInformerFactoryWithOptions(
-		clientset,
-		30*time.Second,
-		informers.WithNamespace("default"),
+func TestInformerManager_Fake(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Labels: map[string]string{"app": "web"}}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "other-ns", Namespace: "kube-system"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "creds", Namespace: "default"}},
 	)
-	informer := factory.Apps().V1().Deployments().Informer()
-	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
-		AddFunc: func(obj interface{}) {
-			if d, ok := obj.(metav1.Object); ok {
-				added <- d.GetName()
-			}
-		},
-	})
-	if err != nil {
-		return
-	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-	go func() {
-		defer wg.Done()
-		factory.Start(ctx.Done())
-		factory.WaitForCacheSync(ctx.Done())
-		<-ctx.Done()
-	}()
-
-	// Wait for events
-	found := map[string]bool{}
-	for range 2 {
-		select {
-		case name := <-added:
-			found[name] = true
-		case <-time.After(5 * time.Second):
-			t.Fatal("timed out waiting for deployment add events")
+	m := NewInformerManager(clientset, Options{Scope: defaultScope(t)})
+	require.False(t, m.HasSynced())
+	require.Error(t, m.WaitForSync(ctx), "WaitForSync must fail before Start")
+
+	require.NoError(t, m.Start(ctx))
+	require.Error(t, m.Start(ctx), "Start must only run once")
+	require.NoError(t, m.WaitForSync(ctx))
+	require.True(t, m.HasSynced())
+
+	deployments, err := appslisters.NewDeploymentLister(m.DeploymentIndexer("default")).List(labels.Everything())
+	require.NoError(t, err)
+	require.Len(t, deployments, 1)
+	require.Equal(t, "web", deployments[0].Name)
+
+	secret, err := corelisters.NewSecretLister(m.SecretIndexer("default")).Secrets("default").Get("creds")
+	require.NoError(t, err)
+	require.Equal(t, "creds", secret.Name)
+
+	// New objects show up through the watch.
+	_, err = clientset.AppsV1().Deployments("default").Create(ctx,
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"}}, metav1.CreateOptions{})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		_, err := appslisters.NewDeploymentLister(m.DeploymentIndexer("default")).Deployments("default").Get("api")
+		return err == nil
+	}, 5*time.Second, 50*time.Millisecond)
+
+	cancel()
+	m.Shutdown()
+}
+
+func TestInformerManager_Namespaces(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "staging"}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "dns", Namespace: "kube-system"}},
+	)
+	names := func(m *InformerManager, namespace string) []string {
+		deployments, err := QueryDeployments(m.DeploymentIndexer(namespace), Query{Namespace: namespace})
+		require.NoError(t, err)
+		out := []string{}
+		for _, d := range deployments {
+			out = append(out, d.Name)
 		}
+		return out
 	}
 
-	require.True(t, found["sample-deployment-1"])
-	require.True(t, found["sample-deployment-2"])
+	list, err := scope.Parse("default,staging")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	m := NewInformerManager(clientset, Options{Scope: list})
+	require.NoError(t, m.Start(ctx))
+	require.NoError(t, m.WaitForSync(ctx))
+	require.Equal(t, []string{"web"}, names(m, "default"))
+	require.Equal(t, []string{"api"}, names(m, "staging"))
+	require.Nil(t, m.DeploymentIndexer("kube-system"), "unlisted namespaces are not watched")
+	require.Empty(t, names(m, "kube-system"))
+	cancel()
+	m.Shutdown()
 
+	all, err := scope.Parse(scope.All)
+	require.NoError(t, err)
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	m = NewInformerManager(clientset, Options{Scope: all})
+	require.NoError(t, m.Start(ctx))
+	require.NoError(t, m.WaitForSync(ctx))
+	require.Equal(t, []string{"dns"}, names(m, "kube-system"))
+	require.Same(t, m.DeploymentIndexer("default"), m.DeploymentIndexer("staging"), "cluster-wide scopes share one cache")
 	cancel()
-	wg.Wait()
+	m.Shutdown()
+}
+
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []events.Event
+}
+
+func (r *eventRecorder) Publish(e events.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+}
+
+func (r *eventRecorder) get() []events.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]events.Event(nil), r.events...)
+}
+
+func TestInformerManager_PublishesEvents(t *testing.T) {
+	replicas := int32(1)
+	dep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", ResourceVersion: "1"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+	}
+	clientset := fake.NewSimpleClientset(dep)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	recorder := &eventRecorder{}
+	m := NewInformerManager(clientset, Options{Scope: defaultScope(t), Events: recorder})
+	require.NoError(t, m.Start(ctx))
+	require.NoError(t, m.WaitForSync(ctx))
+
+	updated := dep.DeepCopy()
+	updated.ResourceVersion = "2"
+	*updated.Spec.Replicas = 3
+	_, err := clientset.AppsV1().Deployments("default").Update(ctx, updated, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, clientset.AppsV1().Deployments("default").Delete(ctx, "web", metav1.DeleteOptions{}))
+
+	require.Eventually(t, func() bool { return len(recorder.get()) == 3 }, 5*time.Second, 50*time.Millisecond)
+	got := recorder.get()
+	require.Equal(t, events.Added, got[0].Type)
+	require.Equal(t, "Deployment", got[0].Kind)
+	require.Equal(t, events.Updated, got[1].Type)
+	require.Equal(t, "2", got[1].ResourceVersion)
+	require.Equal(t, []events.FieldChange{{Path: "spec.replicas", Old: int64(1), New: int64(3)}}, got[1].Diff)
+	require.Equal(t, events.Deleted, got[2].Type)
+
+	cancel()
+	m.Shutdown()
 }
 
 func TestGetDeploymentName(t *testing.T) {
@@ -82,20 +198,3 @@ func TestGetDeploymentName(t *testing.T) {
 		t.Errorf("expected 'unknown', got %q", name)
 	}
 }
-
-func TestStartDeploymentInformer_CoversFunction(t *testing.T) {
-	_, clientset, cleanup := testutil.SetupEnv(t)
-	defer cleanup()
-
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	// Run StartDeploymentInformer in a goroutine
-	go func() {
-		StartInformerFactory(ctx, clientset, "default")
-	}()
-
-	// Give the informer some time to start and process events
-	time.Sleep(1 * time.Second)
-	cancel()
-}
@@ -0,0 +1,206 @@
+// Package install renders the manifests that run the controller in a cluster: the CRDs,
+// its namespace, RBAC, ServiceAccount, Deployment and Services. The manifests are Go
+// templates embedded in the binary, so kctl install needs no chart or checkout.
+package install
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/silhouetteUA/k8s-controller/config/crd"
+	"github.com/silhouetteUA/k8s-controller/pkg/manifest"
+	"github.com/silhouetteUA/k8s-controller/pkg/scope"
+)
+
+// Defaults, matching the Helm chart.
+const (
+	DefaultName            = "k8s-controller"
+	DefaultNamespace       = "custom-controller"
+	DefaultImageRepository = "ghcr.io/silhouetteua/k8s-controller"
+)
+
+//go:embed templates/*
+var templates embed.FS
+
+// systemNamespaces exist in every cluster; installing into one never creates or deletes it.
+var systemNamespaces = []string{"default", "kube-system", "kube-public", "kube-node-lease"}
+
+// Options are the settings of an installation.
+type Options struct {
+	// Name of the Deployment, Services, ServiceAccount and RBAC objects.
+	Name string
+	// Namespace the controller runs in; also its leader election namespace.
+	Namespace       string
+	Image           string
+	ImagePullPolicy string
+	Replicas        int
+	// WatchNamespaces is passed to --watch-namespaces. A list gets a Role in each namespace,
+	// * and selectors a ClusterRole.
+	WatchNamespaces string
+	Port            int
+	MetricsPort     int
+	// EventWebhookURL, when set, enables the webhook event sink with this URL.
+	EventWebhookURL string
+}
+
+// Labels are set on every object of the installation named name; uninstall finds them by
+// these labels, and the app label selects the controller pods.
+func Labels(name string) map[string]string {
+	return map[string]string{
+		"app":                       name,
+		"app.kubernetes.io/name":    name,
+		"app.kubernetes.io/part-of": "kctl",
+	}
+}
+
+// templateData is what the templates see: the options plus what is derived from them.
+type templateData struct {
+	Options
+	Labels            map[string]string
+	CreateNamespace   bool
+	ClusterWide       bool
+	WatchedNamespaces []string
+}
+
+// Validate reports the first invalid option.
+func (o Options) Validate() error {
+	if errs := validation.IsDNS1123Label(o.Name); len(errs) > 0 {
+		return fmt.Errorf("invalid name %q: %s", o.Name, strings.Join(errs, ", "))
+	}
+	if errs := validation.IsDNS1123Label(o.Namespace); len(errs) > 0 {
+		return fmt.Errorf("invalid namespace %q: %s", o.Namespace, strings.Join(errs, ", "))
+	}
+	switch {
+	case o.Image == "":
+		return fmt.Errorf("no image given")
+	case o.Replicas < 0:
+		return fmt.Errorf("replicas must not be negative, got %d", o.Replicas)
+	case o.Port <= 0 || o.Port > 65535:
+		return fmt.Errorf("%d is not a valid port", o.Port)
+	case o.MetricsPort <= 0 || o.MetricsPort > 65535 || o.MetricsPort == o.Port:
+		return fmt.Errorf("%d is not a valid metrics port", o.MetricsPort)
+	}
+	switch o.ImagePullPolicy {
+	case "Always", "IfNotPresent", "Never":
+	default:
+		return fmt.Errorf("image pull policy must be Always, IfNotPresent or Never, got %q", o.ImagePullPolicy)
+	}
+	_, err := scope.Parse(o.WatchNamespaces)
+	return err
+}
+
+// Render returns every object of the installation in the order it is applied: the CRDs
+// first, then the namespace, ServiceAccount, RBAC, Deployment and Services.
+func Render(o Options) ([]*unstructured.Unstructured, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	watched, _ := scope.Parse(o.WatchNamespaces)
+	data := templateData{
+		Options:           o,
+		Labels:            Labels(o.Name),
+		CreateNamespace:   !slices.Contains(systemNamespaces, o.Namespace),
+		ClusterWide:       watched.ClusterWide(),
+		WatchedNamespaces: watched.Namespaces(),
+	}
+
+	objs, err := CRDs()
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := parseTemplates()
+	if err != nil {
+		return nil, err
+	}
+	names, err := fs.Glob(templates, "templates/*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		var buf bytes.Buffer
+		if err := tmpl.ExecuteTemplate(&buf, path.Base(name), data); err != nil {
+			return nil, err
+		}
+		rendered, err := manifest.Decode(&buf, name)
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, rendered...)
+	}
+	return objs, nil
+}
+
+// CRDs returns the FrontendPage and FrontendPageBackup CustomResourceDefinitions.
+func CRDs() ([]*unstructured.Unstructured, error) {
+	names, err := fs.Glob(crd.Files, "*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	var objs []*unstructured.Unstructured
+	for _, name := range names {
+		f, err := crd.Files.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		decoded, err := manifest.Decode(f, name)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, decoded...)
+	}
+	return objs, nil
+}
+
+// deleteRank orders uninstall: the controller stops first so it cannot recreate anything,
+// then its Services and permissions go, then the CRDs (and with them every FrontendPage),
+// and the namespace last.
+var deleteRank = map[string]int{
+	"Deployment":               0,
+	"Service":                  1,
+	"RoleBinding":              2,
+	"ClusterRoleBinding":       2,
+	"Role":                     3,
+	"ClusterRole":              3,
+	"ServiceAccount":           4,
+	"CustomResourceDefinition": 5,
+	"Namespace":                6,
+}
+
+// DeleteOrder returns objs sorted into the order uninstall deletes them.
+func DeleteOrder(objs []*unstructured.Unstructured) []*unstructured.Unstructured {
+	sorted := slices.Clone(objs)
+	slices.SortStableFunc(sorted, func(a, b *unstructured.Unstructured) int {
+		return deleteRank[a.GetKind()] - deleteRank[b.GetKind()]
+	})
+	return sorted
+}
+
+// parseTemplates parses the embedded templates with Helm-like include, indent and quote.
+func parseTemplates() (*template.Template, error) {
+	tmpl := template.New("install")
+	tmpl.Funcs(template.FuncMap{
+		"include": func(name string, data any) (string, error) {
+			var buf bytes.Buffer
+			err := tmpl.ExecuteTemplate(&buf, name, data)
+			return buf.String(), err
+		},
+		"indent": func(n int, s string) string {
+			pad := strings.Repeat(" ", n)
+			return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+		},
+		// quote makes a YAML double-quoted scalar; Go escapes are valid YAML escapes.
+		"quote": strconv.Quote,
+	})
+	return tmpl.ParseFS(templates, "templates/*")
+}
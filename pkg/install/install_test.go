@@ -0,0 +1,112 @@
+package install
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func testOptions() Options {
+	return Options{
+		Name:            DefaultName,
+		Namespace:       DefaultNamespace,
+		Image:           DefaultImageRepository + ":v1.0.0-abc1234",
+		ImagePullPolicy: "IfNotPresent",
+		Replicas:        2,
+		WatchNamespaces: "default, web",
+		Port:            8080,
+		MetricsPort:     8081,
+	}
+}
+
+func refs(objs []*unstructured.Unstructured) []string {
+	var out []string
+	for _, obj := range objs {
+		out = append(out, obj.GetKind()+" "+obj.GetNamespace()+"/"+obj.GetName())
+	}
+	return out
+}
+
+func TestRender(t *testing.T) {
+	objs, err := Render(testOptions())
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"CustomResourceDefinition /frontendpagebackups.frontendpage.silhouetteua.io",
+		"CustomResourceDefinition /frontendpages.frontendpage.silhouetteua.io",
+		"Namespace /custom-controller",
+		"ServiceAccount custom-controller/k8s-controller",
+		"Role custom-controller/k8s-controller-leader-election",
+		"RoleBinding custom-controller/k8s-controller-leader-election",
+		"ClusterRole /k8s-controller-auth",
+		"ClusterRoleBinding /k8s-controller-auth",
+		"Role default/k8s-controller",
+		"RoleBinding default/k8s-controller",
+		"Role web/k8s-controller",
+		"RoleBinding web/k8s-controller",
+		"Deployment custom-controller/k8s-controller",
+		"Service custom-controller/k8s-controller",
+		"Service custom-controller/k8s-controller-metrics",
+	}, refs(objs))
+	for _, obj := range objs[2:] {
+		require.Equal(t, Labels(DefaultName), obj.GetLabels(), obj.GetKind())
+	}
+
+	deployment := objs[12]
+	replicas, _, _ := unstructured.NestedFieldNoCopy(deployment.Object, "spec", "replicas")
+	require.EqualValues(t, 2, replicas)
+	containers, _, _ := unstructured.NestedSlice(deployment.Object, "spec", "template", "spec", "containers")
+	container := containers[0].(map[string]any)
+	require.Equal(t, "ghcr.io/silhouetteua/k8s-controller:v1.0.0-abc1234", container["image"])
+	require.Equal(t, []any{"server", "--watch-namespaces=default, web", "--leader-election-namespace=custom-controller", "--port=8080", "--metrics-port=8081"}, container["args"])
+
+	// Selectors and * get a ClusterRole; a system namespace is used but never created.
+	opts := testOptions()
+	opts.Namespace = "kube-system"
+	opts.WatchNamespaces = "team=web"
+	opts.EventWebhookURL = "https://hooks.example.com/kctl?token=a b"
+	objs, err = Render(opts)
+	require.NoError(t, err)
+	require.NotContains(t, refs(objs), "Namespace /kube-system")
+	require.Contains(t, refs(objs), "ClusterRole /k8s-controller")
+	require.Contains(t, refs(objs), "ClusterRoleBinding /k8s-controller")
+	require.NotContains(t, refs(objs), "Role default/k8s-controller")
+	for _, obj := range objs {
+		if obj.GetKind() != "Deployment" {
+			continue
+		}
+		containers, _, _ := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+		args := containers[0].(map[string]any)["args"].([]any)
+		require.Equal(t, []any{"--event-sinks=log,webhook", "--event-webhook-url=https://hooks.example.com/kctl?token=a b"}, args[len(args)-2:])
+	}
+}
+
+func TestRenderInvalid(t *testing.T) {
+	for name, change := range map[string]func(*Options){
+		"name":        func(o *Options) { o.Name = "Controller" },
+		"namespace":   func(o *Options) { o.Namespace = "" },
+		"image":       func(o *Options) { o.Image = "" },
+		"replicas":    func(o *Options) { o.Replicas = -1 },
+		"port":        func(o *Options) { o.Port = 70000 },
+		"metricsPort": func(o *Options) { o.MetricsPort = o.Port },
+		"pullPolicy":  func(o *Options) { o.ImagePullPolicy = "Sometimes" },
+		"watch":       func(o *Options) { o.WatchNamespaces = "team in (web" },
+	} {
+		opts := testOptions()
+		change(&opts)
+		_, err := Render(opts)
+		require.Error(t, err, name)
+	}
+}
+
+func TestDeleteOrder(t *testing.T) {
+	objs, err := Render(testOptions())
+	require.NoError(t, err)
+	var kinds []string
+	for _, obj := range DeleteOrder(objs) {
+		if len(kinds) == 0 || kinds[len(kinds)-1] != obj.GetKind() {
+			kinds = append(kinds, obj.GetKind())
+		}
+	}
+	require.Equal(t, []string{"Deployment", "Service", "RoleBinding", "ClusterRoleBinding", "RoleBinding", "Role", "ClusterRole", "Role", "ServiceAccount", "CustomResourceDefinition", "Namespace"}, kinds)
+}
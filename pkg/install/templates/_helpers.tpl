@@ -0,0 +1,38 @@
+{{- /* Labels of every installed object, from install.Labels */ -}}
+{{- define "labels" -}}
+{{- range $key, $value := .Labels }}
+{{ $key }}: {{ quote $value }}
+{{- end }}
+{{- end -}}
+
+{{- define "subjects" -}}
+subjects:
+  - kind: ServiceAccount
+    name: {{ .Name }}
+    namespace: {{ .Namespace }}
+{{- end -}}
+
+{{- /* Rules the controller needs in every watched namespace */ -}}
+{{- define "watchRules" -}}
+- apiGroups: ["frontendpage.silhouetteua.io"]
+  resources: ["frontendpages", "frontendpagebackups"]
+  verbs: ["get", "list", "watch", "create", "update", "patch", "delete"]
+- apiGroups: ["frontendpage.silhouetteua.io"]
+  resources: ["frontendpages/status", "frontendpagebackups/status"]
+  verbs: ["get", "update", "patch"]
+- apiGroups: ["apps"]
+  resources: ["deployments"]
+  verbs: ["get", "list", "watch", "create", "update", "patch", "delete"]
+- apiGroups: ["batch"]
+  resources: ["cronjobs"]
+  verbs: ["get", "list", "watch", "create", "update", "patch", "delete"]
+- apiGroups: [""]
+  resources: ["configmaps"]
+  verbs: ["get", "list", "watch", "create", "update", "patch", "delete"]
+- apiGroups: [""]
+  resources: ["secrets"]
+  verbs: ["get", "list", "watch"]
+- apiGroups: [""]
+  resources: ["events"]
+  verbs: ["create", "patch"]
+{{- end -}}
@@ -0,0 +1,290 @@
+package manifest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultFieldManager owns the fields kctl applies.
+const DefaultFieldManager = "kctl"
+
+// Action is what Apply or Prune did to an object.
+type Action string
+
+const (
+	Created    Action = "created"
+	Configured Action = "configured"
+	Unchanged  Action = "unchanged"
+	Pruned     Action = "pruned"
+)
+
+// Result is the outcome for one object.
+type Result struct {
+	// Ref is the object as kind.group/name.
+	Ref       string
+	Namespace string
+	Action    Action
+}
+
+func (r Result) String() string {
+	return fmt.Sprintf("%s %s", r.Ref, r.Action)
+}
+
+// Applier reconciles manifests with the cluster through server-side apply.
+type Applier struct {
+	Client client.Client
+	// Scheme lists the kinds that may be applied; others are rejected.
+	Scheme *runtime.Scheme
+	// FieldManager owns the applied fields; empty means DefaultFieldManager.
+	FieldManager string
+	// Namespace is set on namespaced objects without one. With EnforceNamespace, objects
+	// in another namespace are rejected.
+	Namespace        string
+	EnforceNamespace bool
+	// ForceConflicts takes over fields owned by other managers.
+	ForceConflicts bool
+	// DryRun sends every change with dryRun=All; nothing is persisted.
+	DryRun bool
+}
+
+// Prepare checks that every object is of a known kind and sets the namespace of
+// namespaced objects. It must run before Apply, Diff and PruneCandidates.
+func (a *Applier) Prepare(objs []*unstructured.Unstructured) error {
+	seen := map[string]bool{}
+	for _, obj := range objs {
+		gvk := obj.GroupVersionKind()
+		if !a.Scheme.Recognizes(gvk) {
+			return fmt.Errorf("%s %q: unknown kind %s", gvk.Kind, obj.GetName(), gvk)
+		}
+		namespaced, err := a.Client.IsObjectNamespaced(obj)
+		if err != nil {
+			return fmt.Errorf("%s %q: %w", gvk.Kind, obj.GetName(), err)
+		}
+		switch {
+		case !namespaced:
+			obj.SetNamespace("")
+		case obj.GetNamespace() == "":
+			obj.SetNamespace(a.Namespace)
+		case a.EnforceNamespace && obj.GetNamespace() != a.Namespace:
+			return fmt.Errorf("%s %q is in namespace %q, not %q", gvk.Kind, obj.GetName(), obj.GetNamespace(), a.Namespace)
+		}
+		key := objectKey(obj)
+		if seen[key] {
+			return fmt.Errorf("%s is defined more than once", key)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// Apply server-side applies every object in order.
+func (a *Applier) Apply(ctx context.Context, objs []*unstructured.Unstructured) ([]Result, error) {
+	results := make([]Result, 0, len(objs))
+	for _, obj := range objs {
+		live, err := a.get(ctx, obj)
+		if err != nil {
+			return results, err
+		}
+		applied, err := a.apply(ctx, obj)
+		if err != nil {
+			return results, fmt.Errorf("applying %s: %w", Ref(obj), err)
+		}
+		action := Created
+		if live != nil {
+			action = Configured
+			if a.DryRun && normalized(live) == normalized(applied) || !a.DryRun && live.GetResourceVersion() == applied.GetResourceVersion() {
+				action = Unchanged
+			}
+		}
+		results = append(results, Result{Ref: Ref(obj), Namespace: obj.GetNamespace(), Action: action})
+	}
+	return results, nil
+}
+
+// Diff returns the unified diff between the live state of every object and the state a
+// server-side apply would produce, and of every prune candidate against nothing. Objects
+// without changes are left out.
+func (a *Applier) Diff(ctx context.Context, objs, prune []*unstructured.Unstructured) (string, error) {
+	var out strings.Builder
+	for _, obj := range objs {
+		live, err := a.get(ctx, obj)
+		if err != nil {
+			return "", err
+		}
+		dryRun := *a
+		dryRun.DryRun = true
+		merged, err := dryRun.apply(ctx, obj)
+		if err != nil {
+			return "", fmt.Errorf("dry-run apply of %s: %w", Ref(obj), err)
+		}
+		from := ""
+		if live != nil {
+			from = normalized(live)
+		}
+		out.WriteString(Unified(diffName("live", obj), diffName("merged", obj), from, normalized(merged), 3))
+	}
+	for _, obj := range prune {
+		out.WriteString(Unified(diffName("live", obj), diffName("pruned", obj), normalized(obj), "", 3))
+	}
+	return out.String(), nil
+}
+
+// PruneCandidates returns the objects matching selector that are not in objs, of the
+// kinds in objs plus kinds, in the namespaces of objs plus a.Namespace. Objects owned by
+// a controller are never candidates: their owner manages them.
+func (a *Applier) PruneCandidates(ctx context.Context, objs []*unstructured.Unstructured, selector labels.Selector, kinds []schema.GroupVersionKind) ([]*unstructured.Unstructured, error) {
+	wanted := map[string]bool{}
+	kindSet := map[schema.GroupVersionKind]bool{}
+	namespaces := map[string]bool{a.Namespace: true}
+	for _, obj := range objs {
+		wanted[objectKey(obj)] = true
+		kindSet[obj.GroupVersionKind()] = true
+		if obj.GetNamespace() != "" {
+			namespaces[obj.GetNamespace()] = true
+		}
+	}
+	for _, gvk := range kinds {
+		kindSet[gvk] = true
+	}
+
+	var candidates []*unstructured.Unstructured
+	for gvk := range kindSet {
+		probe := &unstructured.Unstructured{}
+		probe.SetGroupVersionKind(gvk)
+		namespaced, err := a.Client.IsObjectNamespaced(probe)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", gvk.Kind, err)
+		}
+		scopes := []string{""}
+		if namespaced {
+			scopes = scopes[:0]
+			for ns := range namespaces {
+				scopes = append(scopes, ns)
+			}
+		}
+		for _, ns := range scopes {
+			list := &unstructured.UnstructuredList{}
+			list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+			if err := a.Client.List(ctx, list, client.InNamespace(ns), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+				return nil, fmt.Errorf("listing %s: %w", gvk.Kind, err)
+			}
+			for i := range list.Items {
+				obj := &list.Items[i]
+				obj.SetGroupVersionKind(gvk)
+				if wanted[objectKey(obj)] || obj.GetDeletionTimestamp() != nil || metav1.GetControllerOf(obj) != nil {
+					continue
+				}
+				candidates = append(candidates, obj)
+			}
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool { return objectKey(candidates[i]) < objectKey(candidates[j]) })
+	return candidates, nil
+}
+
+// Prune deletes the candidates, skipping any that are already gone.
+func (a *Applier) Prune(ctx context.Context, candidates []*unstructured.Unstructured) ([]Result, error) {
+	var opts []client.DeleteOption
+	if a.DryRun {
+		opts = append(opts, client.DryRunAll)
+	}
+	results := make([]Result, 0, len(candidates))
+	for _, obj := range candidates {
+		del := &unstructured.Unstructured{}
+		del.SetGroupVersionKind(obj.GroupVersionKind())
+		del.SetNamespace(obj.GetNamespace())
+		del.SetName(obj.GetName())
+		uid := obj.GetUID()
+		err := a.Client.Delete(ctx, del, append(opts, client.Preconditions{UID: &uid})...)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return results, fmt.Errorf("pruning %s: %w", Ref(obj), err)
+		}
+		results = append(results, Result{Ref: Ref(obj), Namespace: obj.GetNamespace(), Action: Pruned})
+	}
+	return results, nil
+}
+
+func (a *Applier) get(ctx context.Context, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	live := &unstructured.Unstructured{}
+	live.SetGroupVersionKind(obj.GroupVersionKind())
+	err := a.Client.Get(ctx, client.ObjectKeyFromObject(obj), live)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", Ref(obj), err)
+	}
+	return live, nil
+}
+
+// apply server-side applies a copy of obj and returns the resulting object.
+func (a *Applier) apply(ctx context.Context, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	manager := a.FieldManager
+	if manager == "" {
+		manager = DefaultFieldManager
+	}
+	opts := []client.PatchOption{client.FieldOwner(manager)}
+	if a.ForceConflicts {
+		opts = append(opts, client.ForceOwnership)
+	}
+	if a.DryRun {
+		opts = append(opts, client.DryRunAll)
+	}
+	applied := obj.DeepCopy()
+	applied.SetManagedFields(nil)
+	applied.SetResourceVersion("")
+	if err := a.Client.Patch(ctx, applied, client.Apply, opts...); err != nil {
+		return nil, err
+	}
+	return applied, nil
+}
+
+// Ref returns obj as kind.group/name, e.g. frontendpage.frontendpage.silhouetteua.io/home.
+func Ref(obj *unstructured.Unstructured) string {
+	gvk := obj.GroupVersionKind()
+	resource := strings.ToLower(gvk.Kind)
+	if gvk.Group != "" {
+		resource += "." + gvk.Group
+	}
+	return resource + "/" + obj.GetName()
+}
+
+func objectKey(obj *unstructured.Unstructured) string {
+	gk := obj.GroupVersionKind().GroupKind()
+	return gk.String() + "/" + obj.GetNamespace() + "/" + obj.GetName()
+}
+
+func diffName(state string, obj *unstructured.Unstructured) string {
+	if obj.GetNamespace() == "" {
+		return fmt.Sprintf("%s %s", state, Ref(obj))
+	}
+	return fmt.Sprintf("%s %s -n %s", state, Ref(obj), obj.GetNamespace())
+}
+
+// normalized renders obj as YAML without the metadata the server maintains, so diffs
+// show only meaningful changes.
+func normalized(obj *unstructured.Unstructured) string {
+	c := obj.DeepCopy()
+	for _, field := range []string{"managedFields", "resourceVersion", "generation", "uid", "creationTimestamp"} {
+		unstructured.RemoveNestedField(c.Object, "metadata", field)
+	}
+	data, err := yaml.Marshal(c.Object)
+	if err != nil {
+		return fmt.Sprintf("# %v\n", err)
+	}
+	return string(data)
+}
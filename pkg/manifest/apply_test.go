@@ -0,0 +1,101 @@
+package manifest
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	frontendv1alpha1 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/v1alpha1"
+	"github.com/silhouetteUA/k8s-controller/pkg/testutil"
+)
+
+func page(name, image string) string {
+	return `apiVersion: frontendpage.silhouetteua.io/v1alpha1
+kind: FrontendPage
+metadata:
+  name: ` + name + `
+  labels:
+    site: docs
+spec:
+  contents: <h1>` + name + `</h1>
+  image: ` + image + `
+  replicas: 1
+---
+`
+}
+
+func TestApplier(t *testing.T) {
+	mgr, _, restCfg, cleanup := testutil.StartTestManager(t)
+	defer cleanup()
+	c, err := client.New(restCfg, client.Options{Scheme: mgr.GetScheme()})
+	require.NoError(t, err)
+	ctx := context.Background()
+	a := &Applier{Client: c, Scheme: c.Scheme(), Namespace: "default"}
+	selector := labels.SelectorFromSet(labels.Set{"site": "docs"})
+
+	load := func(manifests string) []*unstructured.Unstructured {
+		objs, err := Decode(strings.NewReader(manifests), "test")
+		require.NoError(t, err)
+		require.NoError(t, a.Prepare(objs))
+		return objs
+	}
+	objs := load(page("home", "nginx:1.25") + page("about", "nginx:1.25"))
+	require.Equal(t, "default", objs[0].GetNamespace())
+
+	diff, err := a.Diff(ctx, objs, nil)
+	require.NoError(t, err)
+	require.Contains(t, diff, "+++ merged frontendpage.frontendpage.silhouetteua.io/home -n default")
+	require.Contains(t, diff, "+  image: nginx:1.25")
+
+	results, err := a.Apply(ctx, objs)
+	require.NoError(t, err)
+	require.Equal(t, "frontendpage.frontendpage.silhouetteua.io/home created", results[0].String())
+	results, err = a.Apply(ctx, objs)
+	require.NoError(t, err)
+	require.Equal(t, Unchanged, results[1].Action)
+	diff, err = a.Diff(ctx, objs, nil)
+	require.NoError(t, err)
+	require.Empty(t, diff)
+
+	// "about" was removed from the manifests and "home" changed.
+	objs = load(page("home", "nginx:1.27"))
+	prune, err := a.PruneCandidates(ctx, objs, selector, nil)
+	require.NoError(t, err)
+	require.Len(t, prune, 1)
+	require.Equal(t, "about", prune[0].GetName())
+	diff, err = a.Diff(ctx, objs, prune)
+	require.NoError(t, err)
+	require.Contains(t, diff, "-  image: nginx:1.25\n+  image: nginx:1.27")
+	require.Contains(t, diff, "+++ pruned frontendpage.frontendpage.silhouetteua.io/about -n default")
+
+	a.DryRun = true
+	results, err = a.Prune(ctx, prune)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	var pages frontendv1alpha1.FrontendPageList
+	require.NoError(t, c.List(ctx, &pages, client.InNamespace("default")))
+	require.Len(t, pages.Items, 2, "a dry run deletes nothing")
+
+	a.DryRun = false
+	results, err = a.Apply(ctx, objs)
+	require.NoError(t, err)
+	require.Equal(t, Configured, results[0].Action)
+	_, err = a.Prune(ctx, prune)
+	require.NoError(t, err)
+	require.NoError(t, c.List(ctx, &pages, client.InNamespace("default")))
+	require.Len(t, pages.Items, 1)
+	require.Equal(t, "nginx:1.27", pages.Items[0].Spec.Image)
+
+	a.EnforceNamespace = true
+	wrongNS, err := Decode(strings.NewReader(strings.Replace(page("x", "nginx"), "  name: x", "  name: x\n  namespace: other", 1)), "test")
+	require.NoError(t, err)
+	require.ErrorContains(t, a.Prepare(wrongNS), `is in namespace "other"`)
+	unknown, err := Decode(strings.NewReader("apiVersion: example.com/v1\nkind: Widget\nmetadata:\n  name: w\n"), "test")
+	require.NoError(t, err)
+	require.ErrorContains(t, a.Prepare(unknown), "unknown kind")
+}
@@ -0,0 +1,62 @@
+package manifest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnified(t *testing.T) {
+	require.Empty(t, Unified("a", "b", "same\n", "same\n", 3))
+
+	lines := func(n int, change map[int]string) string {
+		var b strings.Builder
+		for i := 1; i <= n; i++ {
+			if s, ok := change[i]; ok {
+				b.WriteString(s)
+			} else {
+				fmt.Fprintf(&b, "line %d\n", i)
+			}
+		}
+		return b.String()
+	}
+	from := lines(20, nil)
+	to := lines(20, map[int]string{2: "line two\n", 18: "", 19: "line 19\nextra\n"})
+	require.Equal(t, `--- live
++++ merged
+@@ -1,5 +1,5 @@
+ line 1
+-line 2
++line two
+ line 3
+ line 4
+ line 5
+@@ -15,6 +15,6 @@
+ line 15
+ line 16
+ line 17
+-line 18
+ line 19
++extra
+ line 20
+`, Unified("live", "merged", from, to, 3))
+
+	// Changes closer than twice the context share a hunk.
+	to = lines(20, map[int]string{5: "five\n", 10: "ten\n"})
+	require.Equal(t, 1, strings.Count(Unified("a", "b", from, to, 3), "@@ -"))
+
+	require.Equal(t, "--- live\n+++ pruned\n@@ -1,2 +0,0 @@\n-a\n-b\n", Unified("live", "pruned", "a\nb\n", "", 3))
+	require.Equal(t, "--- live\n+++ merged\n@@ -0,0 +1 @@\n+a\n", Unified("live", "merged", "", "a\n", 3))
+}
+
+func TestColorize(t *testing.T) {
+	diff := "--- a\n+++ b\n@@ -1 +1 @@\n context\n-old\n+new\n"
+	require.Equal(t, colorBold+"--- a"+colorReset+"\n"+
+		colorBold+"+++ b"+colorReset+"\n"+
+		colorCyan+"@@ -1 +1 @@"+colorReset+"\n"+
+		" context\n"+
+		colorRed+"-old"+colorReset+"\n"+
+		colorGreen+"+new"+colorReset+"\n", Colorize(diff))
+}
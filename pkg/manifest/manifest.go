@@ -0,0 +1,132 @@
+// Package manifest reads Kubernetes manifests from files and reconciles them with the
+// cluster through server-side apply.
+package manifest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// Extensions are the file extensions read from directories.
+var Extensions = []string{".yaml", ".yml", ".json"}
+
+// Load reads every manifest in paths. A path is a file, a directory (its manifest files,
+// and those of subdirectories when recursive is set) or "-" for stdin.
+func Load(paths []string, recursive bool, stdin io.Reader) ([]*unstructured.Unstructured, error) {
+	var objs []*unstructured.Unstructured
+	for _, path := range paths {
+		if path == "-" {
+			decoded, err := Decode(stdin, "<stdin>")
+			if err != nil {
+				return nil, err
+			}
+			objs = append(objs, decoded...)
+			continue
+		}
+		files, err := manifestFiles(path, recursive)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			decoded, err := Decode(bytes.NewReader(data), file)
+			if err != nil {
+				return nil, err
+			}
+			objs = append(objs, decoded...)
+		}
+	}
+	return objs, nil
+}
+
+func manifestFiles(path string, recursive bool) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	var files []string
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != path && !recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if slices.Contains(Extensions, strings.ToLower(filepath.Ext(p))) {
+			files = append(files, p)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// Decode reads the YAML or JSON documents of r. Empty documents are skipped and List
+// objects are flattened into their items. source names r in errors.
+func Decode(r io.Reader, source string) ([]*unstructured.Unstructured, error) {
+	decoder := utilyaml.NewYAMLOrJSONDecoder(r, 4096)
+	var objs []*unstructured.Unstructured
+	for doc := 1; ; doc++ {
+		var raw map[string]any
+		err := decoder.Decode(&raw)
+		if errors.Is(err, io.EOF) {
+			return objs, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: document %d: %w", source, doc, err)
+		}
+		if len(raw) == 0 {
+			continue
+		}
+		obj := &unstructured.Unstructured{Object: raw}
+		if obj.IsList() {
+			err := obj.EachListItem(func(item runtime.Object) error {
+				u := item.(*unstructured.Unstructured)
+				if err := validate(u); err != nil {
+					return err
+				}
+				objs = append(objs, u)
+				return nil
+			})
+			if err != nil {
+				return nil, fmt.Errorf("%s: document %d: %w", source, doc, err)
+			}
+			continue
+		}
+		if err := validate(obj); err != nil {
+			return nil, fmt.Errorf("%s: document %d: %w", source, doc, err)
+		}
+		objs = append(objs, obj)
+	}
+}
+
+func validate(obj *unstructured.Unstructured) error {
+	switch {
+	case obj.GetAPIVersion() == "":
+		return fmt.Errorf("apiVersion is not set")
+	case obj.GetKind() == "":
+		return fmt.Errorf("kind is not set")
+	case obj.GetName() == "":
+		return fmt.Errorf("%s has no metadata.name", obj.GetKind())
+	}
+	return nil
+}
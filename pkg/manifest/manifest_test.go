@@ -0,0 +1,87 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const pages = `apiVersion: frontendpage.silhouetteua.io/v1alpha1
+kind: FrontendPage
+metadata:
+  name: home
+spec:
+  image: nginx:1.25
+---
+# only a comment
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: web
+    namespace: site
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: settings
+`
+
+func TestDecode(t *testing.T) {
+	objs, err := Decode(strings.NewReader(pages), "pages.yaml")
+	require.NoError(t, err)
+	require.Len(t, objs, 3)
+	require.Equal(t, "frontendpage.frontendpage.silhouetteua.io/home", Ref(objs[0]))
+	require.Equal(t, "deployment.apps/web", Ref(objs[1]))
+	require.Equal(t, "site", objs[1].GetNamespace())
+	require.Equal(t, "configmap/settings", Ref(objs[2]))
+
+	objs, err = Decode(strings.NewReader(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "a"}}
+{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "b"}}`), "maps.json")
+	require.NoError(t, err)
+	require.Len(t, objs, 2)
+	require.Equal(t, "b", objs[1].GetName())
+
+	for doc, want := range map[string]string{
+		"kind: ConfigMap\nmetadata:\n  name: a\n":                                   "pages.yaml: document 1: apiVersion is not set",
+		"---\napiVersion: v1\nmetadata:\n  name: a\n":                               "pages.yaml: document 1: kind is not set",
+		"apiVersion: v1\nkind: List\nitems:\n- apiVersion: v1\n  kind: ConfigMap\n": "ConfigMap has no metadata.name",
+		"apiVersion: v1\nkind: ConfigMap\nmetadata: [\n":                            "pages.yaml: document 1",
+	} {
+		_, err := Decode(strings.NewReader(doc), "pages.yaml")
+		require.ErrorContains(t, err, want, doc)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	cm := func(name string) string {
+		return "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: " + name + "\n"
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "nested"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.yaml"), []byte(cm("a")), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.YML"), []byte(cm("b")), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("not a manifest"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "nested", "c.json"), []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"c"}}`), 0o600))
+
+	names := func(recursive bool, paths ...string) []string {
+		objs, err := Load(paths, recursive, strings.NewReader(cm("stdin")))
+		require.NoError(t, err)
+		var names []string
+		for _, obj := range objs {
+			names = append(names, obj.GetName())
+		}
+		return names
+	}
+	require.Equal(t, []string{"a", "b"}, names(false, dir))
+	require.Equal(t, []string{"a", "b", "c"}, names(true, dir))
+	require.Equal(t, []string{"c", "stdin"}, names(false, filepath.Join(dir, "nested", "c.json"), "-"))
+
+	_, err := Load([]string{filepath.Join(dir, "missing")}, false, nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,43 @@
+package policy
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// The gauges are registered with the controller-runtime registry, so they are served
+// on the manager's metrics endpoint (--metrics-port).
+var (
+	compliantGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kctl_deployment_policy_compliant",
+		Help: "1 if the Deployment passes every enabled policy check, 0 otherwise.",
+	}, []string{"namespace", "deployment"})
+	checkGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kctl_deployment_policy_check_failed",
+		Help: "1 if the Deployment fails the policy check, 0 if it passes.",
+	}, []string{"namespace", "deployment", "check"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(compliantGauge, checkGauge)
+}
+
+func setGauges(r Report) {
+	compliant := 0.0
+	if r.Compliant {
+		compliant = 1
+	}
+	compliantGauge.WithLabelValues(r.Namespace, r.Name).Set(compliant)
+	for _, c := range r.Checks {
+		failed := 0.0
+		if !c.Passed {
+			failed = 1
+		}
+		checkGauge.WithLabelValues(r.Namespace, r.Name, c.Check).Set(failed)
+	}
+}
+
+func deleteGauges(r Report) {
+	compliantGauge.DeleteLabelValues(r.Namespace, r.Name)
+	checkGauge.DeletePartialMatch(prometheus.Labels{"namespace": r.Namespace, "deployment": r.Name})
+}
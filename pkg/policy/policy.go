@@ -0,0 +1,205 @@
+package policy
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Names of the checks a Policy can enable.
+const (
+	CheckNoLatestTag       = "no-latest-tag"
+	CheckResources         = "resources"
+	CheckProbes            = "probes"
+	CheckMinReplicas       = "min-replicas"
+	CheckAllowedRegistries = "allowed-registries"
+)
+
+// Policy is the set of checks Deployments are audited against. It is read from the
+// ConfigKey entry of the policy ConfigMap.
+type Policy struct {
+	// DisallowLatestTag fails images tagged "latest" or without tag or digest.
+	DisallowLatestTag bool `json:"disallowLatestTag"`
+	// RequireResources fails containers without both CPU and memory requests and limits.
+	RequireResources bool `json:"requireResources"`
+	// RequireProbes fails containers without liveness and readiness probes.
+	RequireProbes bool `json:"requireProbes"`
+	// MinReplicas fails Deployments with fewer desired replicas; 0 disables the check.
+	MinReplicas int32 `json:"minReplicas,omitempty"`
+	// AllowedRegistries are registry or repository prefixes, e.g. "ghcr.io/acme".
+	// Images without a registry are on docker.io. Empty allows every registry.
+	AllowedRegistries []string `json:"allowedRegistries,omitempty"`
+}
+
+// ConfigKey is the ConfigMap key holding the YAML policy.
+const ConfigKey = "policy.yaml"
+
+// Default is used when no policy ConfigMap exists.
+func Default() Policy {
+	return Policy{DisallowLatestTag: true, RequireResources: true, RequireProbes: true, MinReplicas: 1}
+}
+
+// Parse reads a YAML policy. Fields that are not set keep their Default values.
+func Parse(data []byte) (Policy, error) {
+	p := Default()
+	if err := yaml.UnmarshalStrict(data, &p); err != nil {
+		return Policy{}, err
+	}
+	if p.MinReplicas < 0 {
+		return Policy{}, fmt.Errorf("minReplicas must not be negative")
+	}
+	return p, nil
+}
+
+// CheckResult is the outcome of one check.
+type CheckResult struct {
+	Check   string `json:"check" example:"probes"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message,omitempty" example:"container web has no readinessProbe"`
+}
+
+// Report is the audit result of one Deployment.
+type Report struct {
+	Namespace   string        `json:"namespace" example:"default"`
+	Name        string        `json:"name" example:"web"`
+	Compliant   bool          `json:"compliant"`
+	Violations  []string      `json:"violations,omitempty"`
+	Checks      []CheckResult `json:"checks"`
+	EvaluatedAt time.Time     `json:"evaluatedAt"`
+}
+
+// Evaluate audits dep against p. Init containers are held to the image rules only.
+func Evaluate(p Policy, dep *appsv1.Deployment) Report {
+	spec := dep.Spec.Template.Spec
+	var checks []CheckResult
+	if p.DisallowLatestTag {
+		checks = append(checks, checkContainers(CheckNoLatestTag, allContainers(spec), func(c corev1.Container) string {
+			if usesLatestTag(c.Image) {
+				return fmt.Sprintf("container %s uses image %q without a pinned tag", c.Name, c.Image)
+			}
+			return ""
+		}))
+	}
+	if p.RequireResources {
+		checks = append(checks, checkContainers(CheckResources, spec.Containers, func(c corev1.Container) string {
+			var missing []string
+			for _, kind := range []struct {
+				name string
+				list corev1.ResourceList
+			}{{"requests", c.Resources.Requests}, {"limits", c.Resources.Limits}} {
+				for _, r := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+					if _, ok := kind.list[r]; !ok {
+						missing = append(missing, kind.name+"."+string(r))
+					}
+				}
+			}
+			if len(missing) > 0 {
+				return fmt.Sprintf("container %s has no %s", c.Name, strings.Join(missing, ", "))
+			}
+			return ""
+		}))
+	}
+	if p.RequireProbes {
+		checks = append(checks, checkContainers(CheckProbes, spec.Containers, func(c corev1.Container) string {
+			switch {
+			case c.LivenessProbe == nil && c.ReadinessProbe == nil:
+				return fmt.Sprintf("container %s has no livenessProbe and readinessProbe", c.Name)
+			case c.LivenessProbe == nil:
+				return fmt.Sprintf("container %s has no livenessProbe", c.Name)
+			case c.ReadinessProbe == nil:
+				return fmt.Sprintf("container %s has no readinessProbe", c.Name)
+			}
+			return ""
+		}))
+	}
+	if p.MinReplicas > 0 {
+		replicas := int32(1)
+		if dep.Spec.Replicas != nil {
+			replicas = *dep.Spec.Replicas
+		}
+		result := CheckResult{Check: CheckMinReplicas, Passed: replicas >= p.MinReplicas}
+		if !result.Passed {
+			result.Message = fmt.Sprintf("%d replicas, policy requires at least %d", replicas, p.MinReplicas)
+		}
+		checks = append(checks, result)
+	}
+	if len(p.AllowedRegistries) > 0 {
+		checks = append(checks, checkContainers(CheckAllowedRegistries, allContainers(spec), func(c corev1.Container) string {
+			if !registryAllowed(c.Image, p.AllowedRegistries) {
+				return fmt.Sprintf("container %s image %q is not from an allowed registry", c.Name, c.Image)
+			}
+			return ""
+		}))
+	}
+
+	report := Report{Namespace: dep.Namespace, Name: dep.Name, Compliant: true, Checks: checks, EvaluatedAt: time.Now().UTC()}
+	for _, c := range checks {
+		if !c.Passed {
+			report.Compliant = false
+			report.Violations = append(report.Violations, c.Check)
+		}
+	}
+	return report
+}
+
+func allContainers(spec corev1.PodSpec) []corev1.Container {
+	return append(slices.Clone(spec.InitContainers), spec.Containers...)
+}
+
+// checkContainers runs violation over containers; the check fails with the joined
+// messages of every offending container.
+func checkContainers(name string, containers []corev1.Container, violation func(corev1.Container) string) CheckResult {
+	var messages []string
+	for _, c := range containers {
+		if msg := violation(c); msg != "" {
+			messages = append(messages, msg)
+		}
+	}
+	return CheckResult{Check: name, Passed: len(messages) == 0, Message: strings.Join(messages, "; ")}
+}
+
+// usesLatestTag reports whether image floats: tagged "latest" or neither tagged nor pinned by digest.
+func usesLatestTag(image string) bool {
+	if strings.Contains(image, "@") {
+		return false
+	}
+	i := strings.LastIndex(image, ":")
+	if i <= strings.LastIndex(image, "/") {
+		return true
+	}
+	return image[i+1:] == "latest"
+}
+
+// normalizeImage returns image as registry/repository, filling in Docker Hub defaults.
+func normalizeImage(image string) string {
+	first, rest, found := strings.Cut(image, "/")
+	if !found {
+		return "docker.io/library/" + image
+	}
+	if !strings.ContainsAny(first, ".:") && first != "localhost" {
+		return "docker.io/" + image
+	}
+	return first + "/" + rest
+}
+
+func registryAllowed(image string, allowed []string) bool {
+	ref := normalizeImage(image)
+	if i := strings.Index(ref, "@"); i >= 0 {
+		ref = ref[:i]
+	}
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		ref = ref[:i]
+	}
+	for _, prefix := range allowed {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if ref == prefix || strings.HasPrefix(ref, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,171 @@
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func compliantDeployment() *appsv1.Deployment {
+	replicas := int32(2)
+	resources := func() corev1.ResourceList {
+		return corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("64Mi")}
+	}
+	probe := &corev1.Probe{ProbeHandler: corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{Path: "/healthz"}}}
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name:           "web",
+				Image:          "ghcr.io/acme/web:1.4.2",
+				Resources:      corev1.ResourceRequirements{Requests: resources(), Limits: resources()},
+				LivenessProbe:  probe,
+				ReadinessProbe: probe,
+			}}}},
+		},
+	}
+}
+
+func failedChecks(r Report) map[string]string {
+	out := map[string]string{}
+	for _, c := range r.Checks {
+		if !c.Passed {
+			out[c.Check] = c.Message
+		}
+	}
+	return out
+}
+
+func TestEvaluate(t *testing.T) {
+	p := Policy{DisallowLatestTag: true, RequireResources: true, RequireProbes: true, MinReplicas: 2, AllowedRegistries: []string{"ghcr.io/acme"}}
+
+	report := Evaluate(p, compliantDeployment())
+	require.True(t, report.Compliant, failedChecks(report))
+	require.Len(t, report.Checks, 5)
+
+	dep := compliantDeployment()
+	*dep.Spec.Replicas = 1
+	c := &dep.Spec.Template.Spec.Containers[0]
+	c.Image = "nginx"
+	c.ReadinessProbe = nil
+	delete(c.Resources.Limits, corev1.ResourceMemory)
+	report = Evaluate(p, dep)
+	require.False(t, report.Compliant)
+	require.Equal(t, []string{CheckNoLatestTag, CheckResources, CheckProbes, CheckMinReplicas, CheckAllowedRegistries}, report.Violations)
+	failed := failedChecks(report)
+	require.Equal(t, "container web has no limits.memory", failed[CheckResources])
+	require.Equal(t, "container web has no readinessProbe", failed[CheckProbes])
+	require.Equal(t, "1 replicas, policy requires at least 2", failed[CheckMinReplicas])
+
+	report = Evaluate(Policy{}, dep)
+	require.True(t, report.Compliant)
+	require.Empty(t, report.Checks)
+}
+
+func TestImageRules(t *testing.T) {
+	for image, latest := range map[string]bool{
+		"nginx":                       true,
+		"nginx:latest":                true,
+		"localhost:5000/app":          true,
+		"nginx:1.27":                  false,
+		"localhost:5000/app:v1":       false,
+		"ghcr.io/acme/app@sha256:abc": false,
+	} {
+		require.Equal(t, latest, usesLatestTag(image), image)
+	}
+
+	allowed := []string{"docker.io/library", "ghcr.io/acme/", "registry.local:5000"}
+	for image, ok := range map[string]bool{
+		"nginx:1.27":                     true,
+		"docker.io/library/redis":        true,
+		"bitnami/redis:7":                false,
+		"ghcr.io/acme/api:2":             true,
+		"ghcr.io/acmeevil/api:2":         false,
+		"registry.local:5000/team/app:1": true,
+		"quay.io/acme/api:2":             false,
+	} {
+		require.Equal(t, ok, registryAllowed(image, allowed), image)
+	}
+	require.True(t, registryAllowed("ghcr.io/acme/api:2", []string{"ghcr.io/acme/api"}), "a repository is a valid prefix")
+}
+
+func TestParse(t *testing.T) {
+	p, err := Parse([]byte("minReplicas: 3\nrequireProbes: false\nallowedRegistries: [ghcr.io]\n"))
+	require.NoError(t, err)
+	require.Equal(t, Policy{DisallowLatestTag: true, RequireResources: true, MinReplicas: 3, AllowedRegistries: []string{"ghcr.io"}}, p)
+
+	_, err = Parse([]byte("minReplica: 3\n"))
+	require.Error(t, err, "unknown fields are rejected")
+	_, err = Parse([]byte("minReplicas: -1\n"))
+	require.Error(t, err)
+}
+
+func TestLoader_HotReload(t *testing.T) {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "kctl-deployment-policy", Namespace: "default"},
+		Data:       map[string]string{ConfigKey: "minReplicas: 3\n"},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cm).Build()
+	l := &Loader{Client: c, Namespace: "default", Name: cm.Name}
+	ctx := context.Background()
+
+	p, err := l.Current(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int32(3), p.MinReplicas)
+
+	cm.Data[ConfigKey] = "minReplicas: 5\n"
+	require.NoError(t, c.Update(ctx, cm))
+	p, err = l.Current(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int32(5), p.MinReplicas)
+
+	cm.Data[ConfigKey] = "minReplicas: [oops\n"
+	require.NoError(t, c.Update(ctx, cm))
+	p, err = l.Current(ctx)
+	require.NoError(t, err, "an invalid update keeps the last valid policy")
+	require.Equal(t, int32(5), p.MinReplicas)
+
+	require.NoError(t, c.Delete(ctx, cm))
+	p, err = l.Current(ctx)
+	require.NoError(t, err)
+	require.Equal(t, Default(), p)
+
+	l.SetDefault(Policy{MinReplicas: 2})
+	p, err = l.Current(ctx)
+	require.NoError(t, err)
+	require.Equal(t, Policy{MinReplicas: 2}, p, "SetDefault replaces the fallback of a missing ConfigMap")
+
+	broken := &Loader{Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "p", Namespace: "default"},
+		Data:       map[string]string{ConfigKey: "bogus: true"},
+	}).Build(), Namespace: "default", Name: "p"}
+	_, err = broken.Current(ctx)
+	require.Error(t, err, "with no previous policy an invalid one is an error")
+}
+
+func TestReports(t *testing.T) {
+	reports := NewReports()
+	dep := compliantDeployment()
+	reports.Set(Evaluate(Default(), dep))
+	other := compliantDeployment()
+	other.Name = "api"
+	other.Spec.Template.Spec.Containers[0].LivenessProbe = nil
+	reports.Set(Evaluate(Default(), other))
+
+	list := reports.List()
+	require.Len(t, list, 2)
+	require.Equal(t, "api", list[0].Name)
+	require.Equal(t, Summary{Total: 2, Compliant: 1, Violations: map[string]int{CheckProbes: 1}}, Summarize(list))
+
+	reports.Delete(types.NamespacedName{Namespace: "default", Name: "api"})
+	require.Len(t, reports.List(), 1)
+}
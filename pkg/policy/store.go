@@ -0,0 +1,153 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Loader reads the policy from a ConfigMap through a (cached) client, re-parsing it
+// only when its resourceVersion changes. A missing ConfigMap means the fallback policy
+// (Default unless SetDefault was called); an invalid one keeps the last valid policy,
+// so a typo never silently disables auditing.
+type Loader struct {
+	Client    client.Reader
+	Namespace string
+	Name      string
+
+	mu              sync.Mutex
+	resourceVersion string
+	current         Policy
+	loaded          bool
+	fallback        *Policy
+}
+
+// SetDefault replaces the policy used while the ConfigMap is missing.
+func (l *Loader) SetDefault(p Policy) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.fallback = &p
+}
+
+func (l *Loader) defaultPolicy() Policy {
+	if l.fallback != nil {
+		return *l.fallback
+	}
+	return Default()
+}
+
+// Current returns the policy in effect.
+func (l *Loader) Current(ctx context.Context) (Policy, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.Name == "" {
+		return l.defaultPolicy(), nil
+	}
+	var cm corev1.ConfigMap
+	err := l.Client.Get(ctx, types.NamespacedName{Namespace: l.Namespace, Name: l.Name}, &cm)
+	switch {
+	case apierrors.IsNotFound(err):
+		if l.resourceVersion != "" || !l.loaded {
+			log.Info().Msgf("Policy ConfigMap %s/%s not found, using the default policy", l.Namespace, l.Name)
+		}
+		l.current, l.resourceVersion, l.loaded = l.defaultPolicy(), "", true
+		return l.current, nil
+	case err != nil:
+		return Policy{}, err
+	}
+	if l.loaded && cm.ResourceVersion == l.resourceVersion {
+		return l.current, nil
+	}
+	p, err := Parse([]byte(cm.Data[ConfigKey]))
+	if err != nil {
+		err = fmt.Errorf("invalid %s in ConfigMap %s/%s: %w", ConfigKey, l.Namespace, l.Name, err)
+		if l.loaded {
+			log.Error().Err(err).Msg("Keeping the previous deployment policy")
+			return l.current, nil
+		}
+		return Policy{}, err
+	}
+	log.Info().Msgf("Loaded deployment policy from ConfigMap %s/%s (resourceVersion %s)", l.Namespace, l.Name, cm.ResourceVersion)
+	l.current, l.resourceVersion, l.loaded = p, cm.ResourceVersion, true
+	return p, nil
+}
+
+// Reports keeps the latest Report of every audited Deployment and mirrors it to the
+// Prometheus gauges.
+type Reports struct {
+	mu      sync.RWMutex
+	reports map[types.NamespacedName]Report
+}
+
+// NewReports returns an empty report store.
+func NewReports() *Reports {
+	return &Reports{reports: map[types.NamespacedName]Report{}}
+}
+
+// Set records r, replacing the previous report of the same Deployment.
+func (s *Reports) Set(r Report) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := types.NamespacedName{Namespace: r.Namespace, Name: r.Name}
+	if old, ok := s.reports[key]; ok {
+		deleteGauges(old)
+	}
+	s.reports[key] = r
+	setGauges(r)
+}
+
+// Delete forgets the report of a deleted Deployment.
+func (s *Reports) Delete(key types.NamespacedName) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if old, ok := s.reports[key]; ok {
+		deleteGauges(old)
+		delete(s.reports, key)
+	}
+}
+
+// List returns every report, sorted by namespace and name.
+func (s *Reports) List() []Report {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]Report, 0, len(s.reports))
+	for _, r := range s.reports {
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Namespace != out[j].Namespace {
+			return out[i].Namespace < out[j].Namespace
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// Summary aggregates reports.
+type Summary struct {
+	Total     int `json:"total" example:"12"`
+	Compliant int `json:"compliant" example:"9"`
+	// Violations counts failing Deployments per check.
+	Violations map[string]int `json:"violations"`
+}
+
+// Summarize aggregates reports into a Summary.
+func Summarize(reports []Report) Summary {
+	s := Summary{Total: len(reports), Violations: map[string]int{}}
+	for _, r := range reports {
+		if r.Compliant {
+			s.Compliant++
+		}
+		for _, v := range r.Violations {
+			s.Violations[v]++
+		}
+	}
+	return s
+}
@@ -0,0 +1,201 @@
+// Package printers renders command output as a table, JSON, YAML, names, a JSONPath
+// expression or a Go template, selected with the -o flag.
+package printers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/spf13/pflag"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/yaml"
+)
+
+// Formats accepted by -o. JSONPath and GoTemplate are followed by =<expression>.
+const (
+	Table      = "table"
+	Wide       = "wide"
+	JSON       = "json"
+	YAML       = "yaml"
+	Name       = "name"
+	JSONPath   = "jsonpath"
+	GoTemplate = "go-template"
+)
+
+// Options is the output format chosen on the command line.
+type Options struct {
+	// Output is one of the formats, with "=<expression>" for jsonpath and go-template.
+	Output string
+	// NoHeaders omits the header row of table and wide output.
+	NoHeaders bool
+}
+
+// AddFlags registers -o/--output and --no-headers on fs.
+func AddFlags(fs *pflag.FlagSet, o *Options) {
+	fs.StringVarP(&o.Output, "output", "o", Table, "Output format: table, wide, json, yaml, name, jsonpath=<expr> or go-template=<template>")
+	fs.BoolVar(&o.NoHeaders, "no-headers", false, "Omit the header row of table and wide output")
+}
+
+// Column is a table column. Wide columns are only printed with -o wide.
+type Column struct {
+	Header string
+	Wide   bool
+}
+
+// Output is what a command prints.
+type Output struct {
+	// Object is encoded by json, yaml, jsonpath and go-template, as its JSON form.
+	Object any
+	// Columns and Rows are the table; every row has a cell for every column.
+	Columns []Column
+	Rows    [][]string
+	// Names are printed by -o name, e.g. "frontendpage/home".
+	Names []string
+}
+
+// Validate reports an unknown format or an invalid expression before any work is done.
+func (o Options) Validate() error {
+	format, expr, _ := strings.Cut(o.Output, "=")
+	switch format {
+	case "", Table, Wide, JSON, YAML, Name:
+		if expr != "" {
+			return fmt.Errorf("output format %q takes no expression", format)
+		}
+		return nil
+	case JSONPath:
+		_, err := parseJSONPath(expr)
+		return err
+	case GoTemplate:
+		_, err := parseTemplate(expr)
+		return err
+	}
+	return fmt.Errorf("unknown output format %q, want table, wide, json, yaml, name, jsonpath=<expr> or go-template=<template>", o.Output)
+}
+
+// IsTable reports whether the output is a table, so commands can tell the user about an
+// empty result instead of printing nothing.
+func (o Options) IsTable() bool {
+	return o.Output == "" || o.Output == Table || o.Output == Wide
+}
+
+// Print writes out in the chosen format.
+func (o Options) Print(w io.Writer, out Output) error {
+	format, expr, _ := strings.Cut(o.Output, "=")
+	switch format {
+	case "", Table, Wide:
+		return printTable(w, out, format == Wide, o.NoHeaders)
+	case Name:
+		for _, name := range out.Names {
+			if _, err := fmt.Fprintln(w, name); err != nil {
+				return err
+			}
+		}
+		return nil
+	case JSON:
+		data, err := json.MarshalIndent(out.Object, "", "    ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+	case YAML:
+		data, err := yaml.Marshal(out.Object)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	case JSONPath:
+		data, err := toJSONData(out.Object)
+		if err != nil {
+			return err
+		}
+		j, err := parseJSONPath(expr)
+		if err != nil {
+			return err
+		}
+		if err := j.Execute(w, data); err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w)
+		return err
+	case GoTemplate:
+		data, err := toJSONData(out.Object)
+		if err != nil {
+			return err
+		}
+		t, err := parseTemplate(expr)
+		if err != nil {
+			return err
+		}
+		return t.Execute(w, data)
+	}
+	return o.Validate()
+}
+
+func printTable(w io.Writer, out Output, wide, noHeaders bool) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	visible := func(cells []string) []string {
+		var shown []string
+		for i, c := range out.Columns {
+			if wide || !c.Wide {
+				shown = append(shown, cells[i])
+			}
+		}
+		return shown
+	}
+	if !noHeaders {
+		headers := make([]string, len(out.Columns))
+		for i, c := range out.Columns {
+			headers[i] = c.Header
+		}
+		fmt.Fprintln(tw, strings.Join(visible(headers), "\t"))
+	}
+	for _, row := range out.Rows {
+		fmt.Fprintln(tw, strings.Join(visible(row), "\t"))
+	}
+	return tw.Flush()
+}
+
+// toJSONData converts obj to the maps and slices its JSON encodes to, so expressions and
+// templates use the JSON field names, as kubectl does.
+func toJSONData(obj any) (any, error) {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var data any
+	err = json.Unmarshal(raw, &data)
+	return data, err
+}
+
+// parseJSONPath accepts kubectl's relaxed forms: .metadata.name and {.metadata.name}.
+func parseJSONPath(expr string) (*jsonpath.JSONPath, error) {
+	if expr == "" {
+		return nil, fmt.Errorf("jsonpath needs an expression, e.g. jsonpath={.items[*].metadata.name}")
+	}
+	expr = strings.Trim(expr, `'"`)
+	if !strings.Contains(expr, "{") {
+		expr = "{" + expr + "}"
+	}
+	j := jsonpath.New("output").AllowMissingKeys(true)
+	if err := j.Parse(expr); err != nil {
+		return nil, fmt.Errorf("invalid jsonpath %q: %w", expr, err)
+	}
+	return j, nil
+}
+
+func parseTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		return nil, fmt.Errorf("go-template needs a template, e.g. go-template={{.metadata.name}}")
+	}
+	t, err := template.New("output").Parse(strings.Trim(text, `'"`))
+	if err != nil {
+		return nil, fmt.Errorf("invalid go-template: %w", err)
+	}
+	return t, nil
+}
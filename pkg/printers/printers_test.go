@@ -0,0 +1,53 @@
+package printers
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type item struct {
+	Name     string `json:"name"`
+	Replicas int    `json:"replicas"`
+}
+
+func sample() Output {
+	return Output{
+		Object:  map[string]any{"items": []item{{"home", 2}, {"about", 1}}},
+		Columns: []Column{{Header: "NAME"}, {Header: "REPLICAS"}, {Header: "LABELS", Wide: true}},
+		Rows:    [][]string{{"home", "2", "team=web"}, {"about", "1", "<none>"}},
+		Names:   []string{"page/home", "page/about"},
+	}
+}
+
+func TestPrint(t *testing.T) {
+	for _, tc := range []struct {
+		opts Options
+		want string
+	}{
+		{Options{Output: Table}, "NAME    REPLICAS\nhome    2\nabout   1\n"},
+		{Options{Output: Table, NoHeaders: true}, "home    2\nabout   1\n"},
+		{Options{Output: Wide}, "NAME    REPLICAS   LABELS\nhome    2          team=web\nabout   1          <none>\n"},
+		{Options{Output: Name}, "page/home\npage/about\n"},
+		{Options{Output: "json"}, "{\n    \"items\": [\n        {\n            \"name\": \"home\",\n            \"replicas\": 2\n        },\n        {\n            \"name\": \"about\",\n            \"replicas\": 1\n        }\n    ]\n}\n"},
+		{Options{Output: "yaml"}, "items:\n- name: home\n  replicas: 2\n- name: about\n  replicas: 1\n"},
+		{Options{Output: "jsonpath={.items[*].name}"}, "home about\n"},
+		{Options{Output: "jsonpath=.items[0].replicas"}, "2\n"},
+		{Options{Output: `go-template={{range .items}}{{.name}}={{.replicas}};{{end}}`}, "home=2;about=1;"},
+	} {
+		require.NoError(t, tc.opts.Validate(), tc.opts.Output)
+		var buf bytes.Buffer
+		require.NoError(t, tc.opts.Print(&buf, sample()), tc.opts.Output)
+		require.Equal(t, tc.want, buf.String(), tc.opts.Output)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, output := range []string{"xml", "json=.x", "jsonpath=", "jsonpath={.items[", "go-template={{.name"} {
+		require.Error(t, Options{Output: output}.Validate(), output)
+	}
+	require.True(t, Options{}.IsTable())
+	require.True(t, Options{Output: Wide}.IsTable())
+	require.False(t, Options{Output: Name}.IsTable())
+}
@@ -0,0 +1,254 @@
+// Package render builds the objects the controller creates for FrontendPages and
+// FrontendPageBackups. The controller, the orphan scanner, kctl fp render and the render API
+// all use it, so a preview shows exactly what the controller applies.
+package render
+
+import (
+	"fmt"
+	"slices"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	frontendv1alpha2 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/frontendBackup"
+	frontendv1alpha1 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/v1alpha1"
+)
+
+const (
+	// LabelManagedBy and LabelFrontendPage mark the objects built for a FrontendPage, so
+	// they can be found (e.g. by the orphan scanner) even without an owner reference.
+	LabelManagedBy    = "app.kubernetes.io/managed-by"
+	LabelFrontendPage = "frontendpage.silhouetteua.io/page"
+	// ManagedByValue is the value of LabelManagedBy.
+	ManagedByValue = "kctl"
+)
+
+// Labels are the labels set on every object built for page.
+func Labels(page *frontendv1alpha1.FrontendPage) map[string]string {
+	return map[string]string{LabelManagedBy: ManagedByValue, LabelFrontendPage: page.Name}
+}
+
+// ConfigMap holds the page contents; the Deployment mounts it at /data.
+func ConfigMap(page *frontendv1alpha1.FrontendPage) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      page.Name,
+			Namespace: page.Namespace,
+			Labels:    Labels(page),
+		},
+		Data: map[string]string{
+			"contents": page.Spec.Contents,
+		},
+	}
+}
+
+// Deployment serves the page with its image and replicas. Its pods are selected by the
+// app=<page> label.
+func Deployment(page *frontendv1alpha1.FrontendPage) *appsv1.Deployment {
+	replicas := int32(page.Spec.Replicas)
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      page.Name,
+			Namespace: page.Namespace,
+			Labels:    Labels(page),
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": page.Name},
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"app": page.Name},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:  "frontend",
+						Image: page.Spec.Image,
+						VolumeMounts: []corev1.VolumeMount{{
+							Name:      "contents",
+							MountPath: "/data",
+						}},
+					}},
+					Volumes: []corev1.Volume{{
+						Name: "contents",
+						VolumeSource: corev1.VolumeSource{
+							ConfigMap: &corev1.ConfigMapVolumeSource{
+								LocalObjectReference: corev1.LocalObjectReference{
+									Name: page.Name,
+								},
+							},
+						},
+					}},
+				},
+			},
+		},
+	}
+}
+
+// BackupCronJobName is the name of the CronJob that backs up the FrontendPage named page.
+func BackupCronJobName(page string) string {
+	return fmt.Sprintf("backup-%s", page)
+}
+
+// CronJob copies the contents of page on the schedule of backup.
+func CronJob(backup *frontendv1alpha2.FrontendPageBackup, page *frontendv1alpha1.FrontendPage) *batchv1.CronJob {
+	jobName := BackupCronJobName(page.Name)
+
+	return &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName,
+			Namespace: backup.Namespace,
+			Labels:    Labels(page),
+		},
+		Spec: batchv1.CronJobSpec{
+			Schedule: backup.Spec.Schedule,
+			JobTemplate: batchv1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							RestartPolicy: corev1.RestartPolicyNever,
+							Containers: []corev1.Container{{
+								Name:    "backup",
+								Image:   "alpine",
+								Command: []string{"sh", "-c"},
+								Args: []string{
+									fmt.Sprintf(`echo "%s" > /backup/%s.txt`, page.Spec.Contents, page.Name),
+								},
+								VolumeMounts: []corev1.VolumeMount{{
+									Name:      "backup-vol",
+									MountPath: "/backup",
+								}},
+							}},
+							Volumes: []corev1.Volume{{
+								Name: "backup-vol",
+								VolumeSource: corev1.VolumeSource{
+									EmptyDir: &corev1.EmptyDirVolumeSource{},
+								},
+							}},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Objects returns everything the controller creates for page and its backups, in the form
+// it is sent to the API server: with apiVersion and kind set and owned by the page or the
+// backup. Every backup must reference page.
+func Objects(page *frontendv1alpha1.FrontendPage, backups []frontendv1alpha2.FrontendPageBackup) ([]client.Object, error) {
+	if errs := validation.IsDNS1123Subdomain(page.Name); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid frontendpage name %q: %v", page.Name, errs)
+	}
+	pageRef := metav1.NewControllerRef(page, frontendv1alpha1.SchemeGroupVersion.WithKind("FrontendPage"))
+
+	cm := ConfigMap(page)
+	cm.TypeMeta = metav1.TypeMeta{APIVersion: corev1.SchemeGroupVersion.String(), Kind: "ConfigMap"}
+	cm.OwnerReferences = []metav1.OwnerReference{*pageRef}
+	dep := Deployment(page)
+	dep.TypeMeta = metav1.TypeMeta{APIVersion: appsv1.SchemeGroupVersion.String(), Kind: "Deployment"}
+	dep.OwnerReferences = []metav1.OwnerReference{*pageRef}
+	objs := []client.Object{cm, dep}
+
+	for i := range backups {
+		backup := &backups[i]
+		if backup.Spec.FrontendPageRef != page.Name {
+			return nil, fmt.Errorf("frontendpagebackup %q references frontendpage %q, not %q", backup.Name, backup.Spec.FrontendPageRef, page.Name)
+		}
+		if backup.Namespace != page.Namespace {
+			return nil, fmt.Errorf("frontendpagebackup %q is not in namespace %q of its frontendpage", backup.Name, page.Namespace)
+		}
+		cron := CronJob(backup, page)
+		cron.TypeMeta = metav1.TypeMeta{APIVersion: batchv1.SchemeGroupVersion.String(), Kind: "CronJob"}
+		cron.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(backup, frontendv1alpha2.SchemeGroupVersion.WithKind("FrontendPageBackup"))}
+		objs = append(objs, cron)
+	}
+	return objs, nil
+}
+
+// Manifests is Objects as unstructured objects, without the empty status and null creation
+// timestamp the typed objects marshal, so they read like hand-written manifests.
+func Manifests(page *frontendv1alpha1.FrontendPage, backups []frontendv1alpha2.FrontendPageBackup) ([]*unstructured.Unstructured, error) {
+	objs, err := Objects(page, backups)
+	if err != nil {
+		return nil, err
+	}
+	manifests := make([]*unstructured.Unstructured, 0, len(objs))
+	for _, obj := range objs {
+		data, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return nil, err
+		}
+		u := &unstructured.Unstructured{Object: data}
+		unstructured.RemoveNestedField(u.Object, "metadata", "creationTimestamp")
+		for _, template := range [][]string{{"spec", "template"}, {"spec", "jobTemplate"}, {"spec", "jobTemplate", "spec", "template"}} {
+			removeEmptyMetadata(u.Object, template...)
+		}
+		unstructured.RemoveNestedField(u.Object, "status")
+		manifests = append(manifests, u)
+	}
+	return manifests, nil
+}
+
+// removeEmptyMetadata drops the null creationTimestamp of the object template at fields,
+// and the metadata itself when nothing else is left in it.
+func removeEmptyMetadata(obj map[string]any, fields ...string) {
+	metadata := append(slices.Clone(fields), "metadata")
+	unstructured.RemoveNestedField(obj, append(metadata, "creationTimestamp")...)
+	if m, found, _ := unstructured.NestedMap(obj, metadata...); found && len(m) == 0 {
+		unstructured.RemoveNestedField(obj, metadata...)
+	}
+}
+
+// Input is a FrontendPage with the FrontendPageBackups that reference it.
+type Input struct {
+	Page    *frontendv1alpha1.FrontendPage
+	Backups []frontendv1alpha2.FrontendPageBackup
+}
+
+// Inputs groups the FrontendPages and FrontendPageBackups among objs by page, in the order
+// the pages appear. Objects without a namespace are put in namespace. Any other kind, or a
+// backup of a page that is not among objs, is an error.
+func Inputs(objs []*unstructured.Unstructured, namespace string) ([]Input, error) {
+	var inputs []Input
+	var backups []frontendv1alpha2.FrontendPageBackup
+	for _, obj := range objs {
+		if obj.GetNamespace() == "" {
+			obj.SetNamespace(namespace)
+		}
+		gvk := obj.GroupVersionKind()
+		switch {
+		case gvk == frontendv1alpha1.SchemeGroupVersion.WithKind("FrontendPage"):
+			page := &frontendv1alpha1.FrontendPage{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, page); err != nil {
+				return nil, fmt.Errorf("frontendpage %q: %w", obj.GetName(), err)
+			}
+			inputs = append(inputs, Input{Page: page})
+		case gvk == frontendv1alpha2.SchemeGroupVersion.WithKind("FrontendPageBackup"):
+			var backup frontendv1alpha2.FrontendPageBackup
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &backup); err != nil {
+				return nil, fmt.Errorf("frontendpagebackup %q: %w", obj.GetName(), err)
+			}
+			backups = append(backups, backup)
+		default:
+			return nil, fmt.Errorf("cannot render %s %q: only FrontendPages and FrontendPageBackups are rendered", gvk.Kind, obj.GetName())
+		}
+	}
+	for _, backup := range backups {
+		i := slices.IndexFunc(inputs, func(in Input) bool {
+			return in.Page.Name == backup.Spec.FrontendPageRef && in.Page.Namespace == backup.Namespace
+		})
+		if i < 0 {
+			return nil, fmt.Errorf("frontendpagebackup %q references frontendpage %q, which is not given", backup.Name, backup.Spec.FrontendPageRef)
+		}
+		inputs[i].Backups = append(inputs[i].Backups, backup)
+	}
+	return inputs, nil
+}
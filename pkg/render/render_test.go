@@ -0,0 +1,99 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	frontendv1alpha2 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/frontendBackup"
+	frontendv1alpha1 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/v1alpha1"
+	"github.com/silhouetteUA/k8s-controller/pkg/manifest"
+)
+
+func TestManifests(t *testing.T) {
+	page := &frontendv1alpha1.FrontendPage{
+		ObjectMeta: metav1.ObjectMeta{Name: "home", Namespace: "web", UID: "page-uid"},
+		Spec:       frontendv1alpha1.FrontendPageSpec{Contents: "<h1>home</h1>", Image: "nginx:1.27", Replicas: 2},
+	}
+	backups := []frontendv1alpha2.FrontendPageBackup{{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "web", UID: "backup-uid"},
+		Spec:       frontendv1alpha2.FrontendPageBackupSpec{FrontendPageRef: "home", Schedule: "0 2 * * *"},
+	}}
+
+	objs, err := Manifests(page, backups)
+	require.NoError(t, err)
+	require.Len(t, objs, 3)
+	cm, dep, cron := objs[0], objs[1], objs[2]
+	require.Equal(t, []string{"v1/ConfigMap", "apps/v1/Deployment", "batch/v1/CronJob"},
+		[]string{cm.GetAPIVersion() + "/" + cm.GetKind(), dep.GetAPIVersion() + "/" + dep.GetKind(), cron.GetAPIVersion() + "/" + cron.GetKind()})
+	require.Equal(t, "backup-home", cron.GetName())
+	require.Equal(t, Labels(page), dep.GetLabels())
+	require.Equal(t, "page-uid", string(dep.GetOwnerReferences()[0].UID))
+	require.Equal(t, "FrontendPageBackup", cron.GetOwnerReferences()[0].Kind)
+
+	replicas, _, _ := unstructured.NestedFieldNoCopy(dep.Object, "spec", "replicas")
+	require.EqualValues(t, 2, replicas)
+	_, found, _ := unstructured.NestedFieldNoCopy(dep.Object, "status")
+	require.False(t, found)
+	_, found, _ = unstructured.NestedFieldNoCopy(dep.Object, "spec", "template", "metadata", "creationTimestamp")
+	require.False(t, found)
+	_, found, _ = unstructured.NestedFieldNoCopy(dep.Object, "spec", "template", "metadata", "labels")
+	require.True(t, found, "non-empty template metadata is kept")
+	_, found, _ = unstructured.NestedFieldNoCopy(cron.Object, "spec", "jobTemplate", "metadata")
+	require.False(t, found, "empty template metadata is dropped")
+
+	backups[0].Spec.FrontendPageRef = "about"
+	_, err = Manifests(page, backups)
+	require.ErrorContains(t, err, `references frontendpage "about"`)
+}
+
+func TestInputs(t *testing.T) {
+	objs, err := manifest.Decode(strings.NewReader(`
+apiVersion: frontendpage.silhouetteua.io/v1alpha1
+kind: FrontendPageBackup
+metadata:
+  name: nightly
+spec:
+  frontendPageRef: home
+  schedule: "0 2 * * *"
+---
+apiVersion: frontendpage.silhouetteua.io/v1alpha1
+kind: FrontendPage
+metadata:
+  name: about
+  namespace: other
+spec:
+  image: nginx:1.27
+  replicas: 1
+---
+apiVersion: frontendpage.silhouetteua.io/v1alpha1
+kind: FrontendPage
+metadata:
+  name: home
+spec:
+  image: nginx:1.27
+  replicas: 2
+`), "pages.yaml")
+	require.NoError(t, err)
+	inputs, err := Inputs(objs, "web")
+	require.NoError(t, err)
+	require.Len(t, inputs, 2)
+	require.Equal(t, "other", inputs[0].Page.Namespace)
+	require.Empty(t, inputs[0].Backups)
+	require.Equal(t, "web", inputs[1].Page.Namespace)
+	require.Equal(t, 2, inputs[1].Page.Spec.Replicas)
+	require.Len(t, inputs[1].Backups, 1)
+
+	_, err = Inputs(objs[:1], "web")
+	require.ErrorContains(t, err, "which is not given")
+
+	cm := &unstructured.Unstructured{}
+	cm.SetAPIVersion("v1")
+	cm.SetKind("ConfigMap")
+	cm.SetName("home")
+	_, err = Inputs([]*unstructured.Unstructured{cm}, "web")
+	require.ErrorContains(t, err, "only FrontendPages and FrontendPageBackups")
+}
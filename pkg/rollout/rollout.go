@@ -0,0 +1,80 @@
+package rollout
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	frontendv1alpha1 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/v1alpha1"
+)
+
+// Rollout phases reported by DeploymentStatus.
+const (
+	PhaseComplete    = "Complete"
+	PhaseProgressing = "Progressing"
+	PhaseFailed      = "Failed"
+)
+
+// timedOutReason is the Progressing condition reason set by the deployment controller
+// when progressDeadlineSeconds is exceeded.
+const timedOutReason = "ProgressDeadlineExceeded"
+
+// Status summarises where a Deployment rollout is, mirroring `kubectl rollout status`.
+type Status struct {
+	Phase   string `json:"phase"`
+	Message string `json:"message"`
+}
+
+// Done reports whether the rollout has finished, successfully or not.
+func (s Status) Done() bool {
+	return s.Phase != PhaseProgressing
+}
+
+// DeploymentStatus computes the rollout status of d.
+func DeploymentStatus(d *appsv1.Deployment) Status {
+	if d.Generation > d.Status.ObservedGeneration {
+		return Status{Phase: PhaseProgressing, Message: "Waiting for deployment spec update to be observed"}
+	}
+	for _, c := range d.Status.Conditions {
+		if c.Type == appsv1.DeploymentProgressing && c.Status == corev1.ConditionFalse && c.Reason == timedOutReason {
+			return Status{Phase: PhaseFailed, Message: fmt.Sprintf("deployment %q exceeded its progress deadline", d.Name)}
+		}
+	}
+	desired := int32(1)
+	if d.Spec.Replicas != nil {
+		desired = *d.Spec.Replicas
+	}
+	st := d.Status
+	switch {
+	case st.UpdatedReplicas < desired:
+		return Status{Phase: PhaseProgressing, Message: fmt.Sprintf("Waiting for rollout to finish: %d out of %d new replicas have been updated", st.UpdatedReplicas, desired)}
+	case st.Replicas > st.UpdatedReplicas:
+		return Status{Phase: PhaseProgressing, Message: fmt.Sprintf("Waiting for rollout to finish: %d old replicas are pending termination", st.Replicas-st.UpdatedReplicas)}
+	case st.AvailableReplicas < st.UpdatedReplicas:
+		return Status{Phase: PhaseProgressing, Message: fmt.Sprintf("Waiting for rollout to finish: %d of %d updated replicas are available", st.AvailableReplicas, st.UpdatedReplicas)}
+	}
+	return Status{Phase: PhaseComplete, Message: fmt.Sprintf("deployment %q successfully rolled out", d.Name)}
+}
+
+// FrontendPageStatus computes the rollout status of page from its Ready condition and its
+// Deployment dep, which is nil until the controller has created it. The page is complete
+// once the controller has observed its current spec and the Deployment has rolled it out.
+func FrontendPageStatus(page *frontendv1alpha1.FrontendPage, dep *appsv1.Deployment) Status {
+	if page.Status.ObservedGeneration < page.Generation {
+		return Status{Phase: PhaseProgressing, Message: "Waiting for frontendpage spec update to be observed by the controller"}
+	}
+	if dep == nil {
+		return Status{Phase: PhaseProgressing, Message: fmt.Sprintf("Waiting for deployment %q to be created", page.Name)}
+	}
+	if status := DeploymentStatus(dep); status.Phase != PhaseComplete {
+		return status
+	}
+	ready := meta.FindStatusCondition(page.Status.Conditions, frontendv1alpha1.ConditionReady)
+	if ready == nil || ready.Status != metav1.ConditionTrue || ready.ObservedGeneration < page.Generation {
+		return Status{Phase: PhaseProgressing, Message: fmt.Sprintf("Waiting for frontendpage %q to become Ready", page.Name)}
+	}
+	return Status{Phase: PhaseComplete, Message: fmt.Sprintf("frontendpage %q successfully rolled out", page.Name)}
+}
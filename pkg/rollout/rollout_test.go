@@ -0,0 +1,77 @@
+package rollout
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	frontendv1alpha1 "github.com/silhouetteUA/k8s-controller/pkg/api/frontend/v1alpha1"
+)
+
+func TestDeploymentStatus(t *testing.T) {
+	replicas := int32(3)
+	base := appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Generation: 2},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+	}
+
+	tests := map[string]struct {
+		status appsv1.DeploymentStatus
+		phase  string
+	}{
+		"spec not observed": {appsv1.DeploymentStatus{ObservedGeneration: 1}, PhaseProgressing},
+		"updating":          {appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 1}, PhaseProgressing},
+		"old pods pending":  {appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 4, UpdatedReplicas: 3}, PhaseProgressing},
+		"not available":     {appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 2}, PhaseProgressing},
+		"complete":          {appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 3}, PhaseComplete},
+		"deadline exceeded": {appsv1.DeploymentStatus{ObservedGeneration: 2, Conditions: []appsv1.DeploymentCondition{{
+			Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded",
+		}}}, PhaseFailed},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			d := base.DeepCopy()
+			d.Status = tc.status
+			status := DeploymentStatus(d)
+			require.Equal(t, tc.phase, status.Phase, status.Message)
+			require.Equal(t, tc.phase != PhaseProgressing, status.Done())
+		})
+	}
+}
+
+func TestFrontendPageStatus(t *testing.T) {
+	replicas := int32(1)
+	ready := func(status metav1.ConditionStatus, generation int64) []metav1.Condition {
+		return []metav1.Condition{{Type: frontendv1alpha1.ConditionReady, Status: status, ObservedGeneration: generation}}
+	}
+	rolledOut := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "home", Generation: 1},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1},
+	}
+	updating := rolledOut.DeepCopy()
+	updating.Status.AvailableReplicas = 0
+
+	tests := map[string]struct {
+		status frontendv1alpha1.FrontendPageStatus
+		dep    *appsv1.Deployment
+		phase  string
+	}{
+		"spec not observed":   {frontendv1alpha1.FrontendPageStatus{ObservedGeneration: 1, Conditions: ready(metav1.ConditionTrue, 1)}, rolledOut, PhaseProgressing},
+		"no deployment":       {frontendv1alpha1.FrontendPageStatus{ObservedGeneration: 2}, nil, PhaseProgressing},
+		"deployment updating": {frontendv1alpha1.FrontendPageStatus{ObservedGeneration: 2, Conditions: ready(metav1.ConditionFalse, 2)}, updating, PhaseProgressing},
+		"not ready yet":       {frontendv1alpha1.FrontendPageStatus{ObservedGeneration: 2, Conditions: ready(metav1.ConditionFalse, 2)}, rolledOut, PhaseProgressing},
+		"stale condition":     {frontendv1alpha1.FrontendPageStatus{ObservedGeneration: 2, Conditions: ready(metav1.ConditionTrue, 1)}, rolledOut, PhaseProgressing},
+		"complete":            {frontendv1alpha1.FrontendPageStatus{ObservedGeneration: 2, Conditions: ready(metav1.ConditionTrue, 2)}, rolledOut, PhaseComplete},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			page := &frontendv1alpha1.FrontendPage{ObjectMeta: metav1.ObjectMeta{Name: "home", Generation: 2}, Status: tc.status}
+			status := FrontendPageStatus(page, tc.dep)
+			require.Equal(t, tc.phase, status.Phase, status.Message)
+		})
+	}
+}
@@ -0,0 +1,165 @@
+// Package scope decides which namespaces kctl watches: a fixed list, the namespaces
+// matching a label selector, or the whole cluster.
+package scope
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// All is the --watch-namespaces value for every namespace in the cluster.
+const All = "*"
+
+// Scope is the set of watched namespaces. The zero value and nil both mean all namespaces.
+type Scope struct {
+	names    []string
+	selector labels.Selector
+	// namespaces caches the Namespaces matching selector once Start has run.
+	namespaces atomic.Pointer[corelisters.NamespaceLister]
+}
+
+// Parse reads a --watch-namespaces value: "*" for every namespace, a label selector such
+// as "team=web,env!=dev" for the namespaces whose labels match it, or a comma-separated
+// list such as "default,staging". A value is a selector when it contains one of = ! ( ),
+// which namespace names can't.
+func Parse(value string) (*Scope, error) {
+	value = strings.TrimSpace(value)
+	switch {
+	case value == "":
+		return nil, errors.New("no namespaces given, use * to watch all namespaces")
+	case value == All:
+		return &Scope{}, nil
+	case strings.ContainsAny(value, "=!()"):
+		selector, err := labels.Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid namespace selector %q: %w", value, err)
+		}
+		return &Scope{selector: selector}, nil
+	}
+	s := &Scope{}
+	seen := map[string]bool{}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid namespace %q: %s", name, strings.Join(errs, ", "))
+		}
+		seen[name] = true
+		s.names = append(s.names, name)
+	}
+	return s, nil
+}
+
+// ClusterWide reports whether objects must be watched in all namespaces: for * and for
+// selectors, whose matching namespaces change at runtime.
+func (s *Scope) ClusterWide() bool {
+	return s == nil || len(s.names) == 0
+}
+
+// Namespaces returns the listed namespaces, or nil when the scope is cluster-wide.
+func (s *Scope) Namespaces() []string {
+	if s == nil {
+		return nil
+	}
+	return s.names
+}
+
+// Selector returns the namespace label selector, or nil when the scope isn't one.
+func (s *Scope) Selector() labels.Selector {
+	if s == nil {
+		return nil
+	}
+	return s.selector
+}
+
+// Default is the namespace used when a request names none: the first listed one, or
+// "default" otherwise.
+func (s *Scope) Default() string {
+	if s != nil && len(s.names) > 0 {
+		return s.names[0]
+	}
+	return metav1.NamespaceDefault
+}
+
+// Contains reports whether namespace is watched. With a selector it is false until
+// Start has synced the matching namespaces.
+func (s *Scope) Contains(namespace string) bool {
+	switch {
+	case s == nil:
+		return true
+	case len(s.names) > 0:
+		for _, name := range s.names {
+			if name == namespace {
+				return true
+			}
+		}
+		return false
+	case s.selector != nil:
+		lister := s.namespaces.Load()
+		if lister == nil {
+			return false
+		}
+		_, err := (*lister).Get(namespace)
+		return err == nil
+	default:
+		return true
+	}
+}
+
+// Predicate filters controller events down to objects in watched namespaces.
+func (s *Scope) Predicate() predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(o client.Object) bool {
+		return s.Contains(o.GetNamespace())
+	})
+}
+
+// Start watches the Namespaces matching the selector so Contains follows label changes,
+// and waits for the first sync. It is a no-op for other scopes.
+func (s *Scope) Start(ctx context.Context, clientset kubernetes.Interface, resync time.Duration) error {
+	if s == nil || s.selector == nil {
+		return nil
+	}
+	factory := informers.NewSharedInformerFactoryWithOptions(clientset, resync,
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.LabelSelector = s.selector.String()
+		}))
+	namespaces := factory.Core().V1().Namespaces()
+	lister := namespaces.Lister()
+	informer := namespaces.Informer()
+	factory.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		return errors.New("namespace informer failed to sync")
+	}
+	s.namespaces.Store(&lister)
+	return nil
+}
+
+// String renders the scope the way Parse reads it.
+func (s *Scope) String() string {
+	switch {
+	case s == nil:
+		return All
+	case len(s.names) > 0:
+		return strings.Join(s.names, ",")
+	case s.selector != nil:
+		return s.selector.String()
+	default:
+		return All
+	}
+}
@@ -0,0 +1,71 @@
+package scope
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestParse(t *testing.T) {
+	all, err := Parse("*")
+	require.NoError(t, err)
+	require.True(t, all.ClusterWide())
+	require.Nil(t, all.Namespaces())
+	require.True(t, all.Contains("anything"))
+	require.Equal(t, "default", all.Default())
+	require.Equal(t, "*", all.String())
+
+	list, err := Parse(" staging, default ,staging")
+	require.NoError(t, err)
+	require.False(t, list.ClusterWide())
+	require.Equal(t, []string{"staging", "default"}, list.Namespaces())
+	require.Equal(t, "staging", list.Default())
+	require.True(t, list.Contains("default"))
+	require.False(t, list.Contains("kube-system"))
+	require.Equal(t, "staging,default", list.String())
+
+	selector, err := Parse("team=web,env!=dev")
+	require.NoError(t, err)
+	require.True(t, selector.ClusterWide())
+	require.NotNil(t, selector.Selector())
+	require.False(t, selector.Contains("default"), "selector scopes contain nothing before Start")
+
+	for _, bad := range []string{"", "Not_A_Namespace", "team in (web"} {
+		_, err := Parse(bad)
+		require.Error(t, err, bad)
+	}
+
+	var none *Scope
+	require.True(t, none.Contains("default"))
+	require.True(t, none.ClusterWide())
+}
+
+func TestSelectorFollowsNamespaceLabels(t *testing.T) {
+	web := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: map[string]string{"team": "web"}}}
+	clientset := fake.NewSimpleClientset(web, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "db"}})
+	s, err := Parse("team=web")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, s.Start(ctx, clientset, time.Minute))
+	require.True(t, s.Contains("web"))
+	require.False(t, s.Contains("db"))
+
+	pod := func(ns string) event.CreateEvent {
+		return event.CreateEvent{Object: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p", Namespace: ns}}}
+	}
+	require.True(t, s.Predicate().Create(pod("web")))
+	require.False(t, s.Predicate().Create(pod("db")))
+
+	db := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "db", Labels: map[string]string{"team": "web"}}}
+	_, err = clientset.CoreV1().Namespaces().Update(ctx, db, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return s.Contains("db") }, 5*time.Second, 20*time.Millisecond)
+}
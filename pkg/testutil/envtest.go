@@ -3,6 +3,8 @@ package testutil
 import (
 	"context"
 	"fmt"
+	"path/filepath"
+	goruntime "runtime"
 	"testing"
 	"time"
 
@@ -37,7 +39,7 @@ func StartTestManager(t *testing.T) (mgr manager.Manager, k8sClient client.Clien
 	defer cancel()
 
 	env := &envtest.Environment{
-		CRDDirectoryPaths:        []string{"../../config/crd/"},
+		CRDDirectoryPaths:        []string{crdDir()},
 		ErrorIfCRDPathMissing:    true,
 		AttachControlPlaneOutput: false,
 	}
@@ -100,7 +102,7 @@ func SetupEnv(t *testing.T) (*envtest.Environment, *kubernetes.Clientset, func()
 
 	env := &envtest.Environment{
 		CRDDirectoryPaths: []string{
-			"../../config/crd/",
+			crdDir(),
 		},
 		ErrorIfCRDPathMissing:    true,
 		AttachControlPlaneOutput: false,
@@ -155,3 +157,9 @@ func SetupEnv(t *testing.T) (*envtest.Environment, *kubernetes.Clientset, func()
 }
 
 func int32Ptr(i int32) *int32 { return &i }
+
+// crdDir returns config/crd relative to this file, so packages at any depth can use envtest.
+func crdDir() string {
+	_, file, _, _ := goruntime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "config", "crd")
+}